@@ -0,0 +1,218 @@
+package audit
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// genericLinkText is link text that says nothing about where a link goes
+var genericLinkText = map[string]bool{
+	"click here": true,
+	"here":       true,
+	"click":      true,
+	"link":       true,
+	"more":       true,
+	"read more":  true,
+	"learn more": true,
+	"this":       true,
+	"this page":  true,
+}
+
+// unlabelledInputTypes are inputs that either aren't shown or label
+// themselves with their value
+var unlabelledInputTypes = map[string]bool{
+	"hidden": true,
+	"submit": true,
+	"reset":  true,
+	"button": true,
+	"image":  true,
+}
+
+// AccessibilityRules creates the static accessibility checks every audit
+// runs
+func AccessibilityRules() []Rule {
+	return []Rule{
+		GenerateRule(ACCESSIBILITY, "img-name-missing", WARNING, checkImageNames),
+		GenerateRule(ACCESSIBILITY, "input-label-missing", ERROR, checkInputLabels),
+		GenerateRule(ACCESSIBILITY, "link-text-empty", ERROR, checkLinkTextEmpty),
+		GenerateRule(ACCESSIBILITY, "link-text-generic", WARNING, checkLinkTextGeneric),
+		GenerateRule(ACCESSIBILITY, "heading-level-skipped", WARNING, checkHeadingLevels),
+		GenerateRule(ACCESSIBILITY, "id-duplicate", ERROR, checkDuplicateIDs),
+		GenerateRule(ACCESSIBILITY, "iframe-title-missing", WARNING, checkIframeTitles),
+	}
+}
+
+// accessibleName approximates the name assistive technology announces for
+// an element from its text, ARIA attributes and image alternatives
+func accessibleName(s *goquery.Selection) string {
+	for _, attr := range []string{"aria-label", "aria-labelledby", "title"} {
+		if value, _ := s.Attr(attr); strings.TrimSpace(value) != "" {
+			return strings.TrimSpace(value)
+		}
+	}
+
+	name := strings.TrimSpace(s.Text())
+	if name != "" {
+		return name
+	}
+
+	s.Find("img[alt]").EachWithBreak(func(i int, img *goquery.Selection) bool {
+		alt, _ := img.Attr("alt")
+		name = strings.TrimSpace(alt)
+		return name == ""
+	})
+
+	return name
+}
+
+func checkImageNames(page *Page) []string {
+	var messages []string
+
+	page.Doc.Find("img:not([alt])").Each(func(i int, s *goquery.Selection) {
+		// Images hidden from assistive technology or marked as decorative
+		// don't need a name
+		hidden, _ := s.Attr("aria-hidden")
+		role, _ := s.Attr("role")
+		if hidden == "true" || role == "presentation" || role == "none" || accessibleName(s) != "" {
+			return
+		}
+
+		src, _ := s.Attr("src")
+		messages = append(messages, fmt.Sprintf("Image %s has no text alternative", src))
+	})
+
+	return messages
+}
+
+func checkInputLabels(page *Page) []string {
+	var messages []string
+
+	labelled := make(map[string]bool)
+	page.Doc.Find("label[for]").Each(func(i int, s *goquery.Selection) {
+		id, _ := s.Attr("for")
+		labelled[id] = true
+	})
+
+	page.Doc.Find("input, select, textarea").Each(func(i int, s *goquery.Selection) {
+		inputType, _ := s.Attr("type")
+		if unlabelledInputTypes[strings.ToLower(inputType)] {
+			return
+		}
+
+		if id, ok := s.Attr("id"); ok && labelled[id] {
+			return
+		}
+
+		for _, attr := range []string{"aria-label", "aria-labelledby", "title"} {
+			if value, _ := s.Attr(attr); strings.TrimSpace(value) != "" {
+				return
+			}
+		}
+
+		if s.Closest("label").Length() > 0 {
+			return
+		}
+
+		messages = append(messages, fmt.Sprintf("Form field %s has no label", describe(s)))
+	})
+
+	return messages
+}
+
+func checkLinkTextEmpty(page *Page) []string {
+	var messages []string
+
+	page.Doc.Find("a[href]").Each(func(i int, s *goquery.Selection) {
+		if accessibleName(s) == "" {
+			href, _ := s.Attr("href")
+			messages = append(messages, fmt.Sprintf("Link to %s has no text", href))
+		}
+	})
+
+	return messages
+}
+
+func checkLinkTextGeneric(page *Page) []string {
+	var messages []string
+
+	page.Doc.Find("a[href]").Each(func(i int, s *goquery.Selection) {
+		text := strings.Join(strings.Fields(strings.ToLower(s.Text())), " ")
+		text = strings.TrimRight(text, ".!:")
+
+		if _, ok := s.Attr("aria-label"); !ok && genericLinkText[text] {
+			href, _ := s.Attr("href")
+			messages = append(messages, fmt.Sprintf("Link to %s only says %q", href, strings.TrimSpace(s.Text())))
+		}
+	})
+
+	return messages
+}
+
+func checkHeadingLevels(page *Page) []string {
+	var messages []string
+	previous := 0
+
+	page.Doc.Find("h1, h2, h3, h4, h5, h6").Each(func(i int, s *goquery.Selection) {
+		level := int(goquery.NodeName(s)[1] - '0')
+
+		if previous > 0 && level > previous+1 {
+			messages = append(messages, fmt.Sprintf("Heading %q skips from <h%d> to <h%d>", strings.TrimSpace(s.Text()), previous, level))
+		}
+		previous = level
+	})
+
+	return messages
+}
+
+func checkDuplicateIDs(page *Page) []string {
+	var messages []string
+	counts := make(map[string]int)
+	var order []string
+
+	page.Doc.Find("[id]").Each(func(i int, s *goquery.Selection) {
+		id, _ := s.Attr("id")
+		if id == "" {
+			return
+		}
+
+		if counts[id] == 0 {
+			order = append(order, id)
+		}
+		counts[id]++
+	})
+
+	for _, id := range order {
+		if counts[id] > 1 {
+			messages = append(messages, fmt.Sprintf("ID %q is used by %d elements", id, counts[id]))
+		}
+	}
+
+	return messages
+}
+
+func checkIframeTitles(page *Page) []string {
+	var messages []string
+
+	page.Doc.Find("iframe").Each(func(i int, s *goquery.Selection) {
+		title, _ := s.Attr("title")
+		if strings.TrimSpace(title) == "" {
+			src, _ := s.Attr("src")
+			messages = append(messages, fmt.Sprintf("Frame %s has no title", src))
+		}
+	})
+
+	return messages
+}
+
+// describe identifies a form field in messages by its name or id
+func describe(s *goquery.Selection) string {
+	for _, attr := range []string{"name", "id"} {
+		if value, _ := s.Attr(attr); value != "" {
+			return fmt.Sprintf("<%s %s=%q>", goquery.NodeName(s), attr, value)
+		}
+	}
+
+	return fmt.Sprintf("<%s>", goquery.NodeName(s))
+}
@@ -0,0 +1,72 @@
+package audit_test
+
+import (
+	. "github.com/kevinoconnor7/digitalocean-crawler/audit"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Accessibility", func() {
+	rules := func(html string) []string {
+		return firing(AccessibilityRules(), "http://foo.faketld/", html)
+	}
+
+	It("Should pass an accessible page", func() {
+		Expect(rules(`
+<h1>Title</h1>
+<h2>Section</h2>
+<img src="/a.png" alt="A chart">
+<form>
+	<label for="email">Email</label><input id="email" type="email">
+	<label>Name <input name="name"></label>
+	<input type="search" aria-label="Search">
+	<input type="hidden" name="token">
+	<button type="submit">Send</button>
+</form>
+<a href="/pricing">Pricing plans</a>
+<a href="/home"><img src="/logo.png" alt="Home"></a>
+<iframe src="/video" title="Product demo"></iframe>
+`)).To(BeEmpty())
+	})
+
+	It("Should flag images without a text alternative", func() {
+		Expect(rules(`<img src="/a.png"><img src="/b.png" alt="">`)).To(Equal([]string{"img-name-missing"}))
+	})
+
+	It("Should not flag named, hidden or decorative images", func() {
+		Expect(rules(`<img src="/a.png" aria-label="A chart"><img src="/b.png" aria-hidden="true"><img src="/c.png" role="presentation">`)).To(BeEmpty())
+	})
+
+	It("Should flag form fields without labels", func() {
+		Expect(rules(`<input name="q"><select id="size"></select><textarea></textarea>`)).To(Equal([]string{
+			"input-label-missing", "input-label-missing", "input-label-missing",
+		}))
+	})
+
+	It("Should flag links without text", func() {
+		Expect(rules(`<a href="/"><img src="/logo.png" alt=""></a>`)).To(Equal([]string{"link-text-empty"}))
+	})
+
+	It("Should flag generic link text", func() {
+		Expect(rules(`<a href="/a">Click here</a><a href="/b">Read more...</a><a href="/c" aria-label="Read more about pricing">More</a>`)).To(Equal([]string{
+			"link-text-generic", "link-text-generic",
+		}))
+	})
+
+	It("Should flag skipped heading levels", func() {
+		findings := GenerateEngine(AccessibilityRules()...).Check(page("http://foo.faketld/", `<h1>A</h1><h3>B</h3><h2>C</h2><h4>D</h4>`))
+
+		Expect(len(findings)).To(Equal(2))
+		Expect(findings[0].Message).To(Equal(`Heading "B" skips from <h1> to <h3>`))
+		Expect(findings[0].Category).To(Equal(ACCESSIBILITY))
+	})
+
+	It("Should flag duplicate ids", func() {
+		Expect(rules(`<div id="a"></div><div id="a"></div><div id="b"></div>`)).To(Equal([]string{"id-duplicate"}))
+	})
+
+	It("Should flag iframes without titles", func() {
+		Expect(rules(`<iframe src="/video"></iframe>`)).To(Equal([]string{"iframe-title-missing"}))
+	})
+})
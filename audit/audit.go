@@ -0,0 +1,293 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"sync"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Severity defines an enum for how serious a Finding is
+type Severity int
+
+const (
+	INFO Severity = 1 + iota
+	WARNING
+	ERROR
+)
+
+var severityNames = map[Severity]string{
+	INFO:    "info",
+	WARNING: "warning",
+	ERROR:   "error",
+}
+
+func (s Severity) String() string {
+	return severityNames[s]
+}
+
+// MarshalJSON encodes the Severity by name
+func (s Severity) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.String())
+}
+
+// UnmarshalJSON decodes a Severity from its name
+func (s *Severity) UnmarshalJSON(data []byte) error {
+	var name string
+
+	err := json.Unmarshal(data, &name)
+	if err != nil {
+		return err
+	}
+
+	severity, err := ParseSeverity(name)
+	if err != nil {
+		return err
+	}
+
+	*s = severity
+
+	return nil
+}
+
+// ParseSeverity looks up a Severity by name
+func ParseSeverity(name string) (Severity, error) {
+	for severity, severityName := range severityNames {
+		if severityName == name {
+			return severity, nil
+		}
+	}
+
+	return 0, fmt.Errorf("Unknown severity %q", name)
+}
+
+// Category groups rules by the kind of problem they look for
+type Category string
+
+const (
+	SEO           Category = "seo"
+	ACCESSIBILITY Category = "accessibility"
+)
+
+// Finding is a problem a Rule found on a page
+type Finding struct {
+	Rule     string   `json:"rule"`
+	Category Category `json:"category"`
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+}
+
+// Page is a fetched HTML page for rules to check
+type Page struct {
+	URL  *url.URL
+	Doc  *goquery.Document
+	Size int
+}
+
+// Rule checks a single page at a time
+type Rule interface {
+	Name() string
+	Check(page *Page) []Finding
+}
+
+// SiteRule is a Rule that can only judge pages once it has seen all of
+// them, such as finding duplicate titles
+type SiteRule interface {
+	Rule
+
+	// Finish returns the findings for every page checked, keyed by URL
+	Finish() map[string][]Finding
+}
+
+// ValueRule is a SiteRule judging every page by a single value, so pages
+// that aren't checked again, such as those unchanged since an earlier
+// crawl, can still be judged by the value they had
+type ValueRule interface {
+	SiteRule
+
+	// Value returns what the rule judges the page by
+	Value(page *Page) string
+
+	// Add judges the page at url by a value found earlier
+	Add(url string, value string)
+}
+
+// simpleRule is a Rule reporting each message from check at one severity
+type simpleRule struct {
+	category Category
+	name     string
+	severity Severity
+	check    func(page *Page) []string
+}
+
+// GenerateRule is a factory for a Rule reporting every message returned by
+// check as a Finding with the given category and severity
+func GenerateRule(category Category, name string, severity Severity, check func(page *Page) []string) Rule {
+	return &simpleRule{
+		category: category,
+		name:     name,
+		severity: severity,
+		check:    check,
+	}
+}
+
+func (r *simpleRule) Name() string {
+	return r.name
+}
+
+func (r *simpleRule) Check(page *Page) []Finding {
+	var findings []Finding
+
+	for _, message := range r.check(page) {
+		findings = append(findings, Finding{
+			Rule:     r.name,
+			Category: r.category,
+			Severity: r.severity,
+			Message:  message,
+		})
+	}
+
+	return findings
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   []func() Rule
+)
+
+// Register adds a custom rule to every crawl that audits pages
+// A new Rule is created per crawl, so SiteRules may safely keep state
+func Register(factory func() Rule) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	registry = append(registry, factory)
+}
+
+// Rules creates the built-in SEO and accessibility rules followed by every
+// registered rule
+func Rules() []Rule {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	rules := append(BuiltinRules(), AccessibilityRules()...)
+	for _, factory := range registry {
+		rules = append(rules, factory())
+	}
+
+	return rules
+}
+
+// Engine runs a set of rules over the pages of a single crawl
+type Engine struct {
+	mu    sync.Mutex
+	rules []Rule
+}
+
+// GenerateEngine is a factory for an Engine running rules
+func GenerateEngine(rules ...Rule) *Engine {
+	return &Engine{rules: rules}
+}
+
+// Check runs every rule over the page
+func (e *Engine) Check(page *Page) []Finding {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var findings []Finding
+	for _, rule := range e.rules {
+		findings = append(findings, rule.Check(page)...)
+	}
+
+	return findings
+}
+
+// Values returns what every ValueRule judges the page by, keyed by rule
+// name, leaving out empty values
+func (e *Engine) Values(page *Page) map[string]string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	values := make(map[string]string)
+
+	for _, rule := range e.rules {
+		if valueRule, ok := rule.(ValueRule); ok {
+			if value := valueRule.Value(page); value != "" {
+				values[rule.Name()] = value
+			}
+		}
+	}
+
+	return values
+}
+
+// Reuse judges a page that isn't checked again by the values its
+// ValueRules found earlier
+func (e *Engine) Reuse(url string, values map[string]string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, rule := range e.rules {
+		valueRule, ok := rule.(ValueRule)
+		if !ok {
+			continue
+		}
+
+		if value, ok := values[rule.Name()]; ok && value != "" {
+			valueRule.Add(url, value)
+		}
+	}
+}
+
+// PageFindings drops the findings of SiteRules, which depend on other pages,
+// keeping those of rules that judge each page on its own
+func (e *Engine) PageFindings(findings []Finding) []Finding {
+	site := make(map[string]bool)
+	for _, rule := range e.rules {
+		if _, ok := rule.(SiteRule); ok {
+			site[rule.Name()] = true
+		}
+	}
+
+	var kept []Finding
+	for _, finding := range findings {
+		if !site[finding.Rule] {
+			kept = append(kept, finding)
+		}
+	}
+
+	return kept
+}
+
+// Finish collects the findings of every SiteRule, keyed by URL
+func (e *Engine) Finish() map[string][]Finding {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	findings := make(map[string][]Finding)
+	for _, rule := range e.rules {
+		site, ok := rule.(SiteRule)
+		if !ok {
+			continue
+		}
+
+		for url, pageFindings := range site.Finish() {
+			findings[url] = append(findings[url], pageFindings...)
+		}
+	}
+
+	return findings
+}
+
+// Sort orders findings from most to least severe, then by rule
+func Sort(findings []Finding) {
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].Severity != findings[j].Severity {
+			return findings[i].Severity > findings[j].Severity
+		}
+		return findings[i].Rule < findings[j].Rule
+	})
+}
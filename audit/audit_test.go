@@ -0,0 +1,130 @@
+package audit_test
+
+import (
+	"bytes"
+	"encoding/json"
+	u "net/url"
+
+	"github.com/PuerkitoBio/goquery"
+	. "github.com/kevinoconnor7/digitalocean-crawler/audit"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// page parses html as if it had been fetched from url
+func page(url string, html string) *Page {
+	parsed, _ := u.Parse(url)
+	doc, _ := goquery.NewDocumentFromReader(bytes.NewBufferString(html))
+
+	return &Page{URL: parsed, Doc: doc, Size: len(html)}
+}
+
+var _ = Describe("Audit", func() {
+	Describe("Severity", func() {
+		It("Should encode by name", func() {
+			out, _ := json.Marshal(WARNING)
+			Expect(string(out)).To(Equal(`"warning"`))
+
+			var s Severity
+			Expect(json.Unmarshal(out, &s)).To(Succeed())
+			Expect(s).To(Equal(WARNING))
+		})
+
+		It("Should reject unknown names", func() {
+			_, err := ParseSeverity("fatal")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("GenerateRule", func() {
+		It("Should report each message as a finding", func() {
+			rule := GenerateRule(SEO, "custom", INFO, func(p *Page) []string {
+				return []string{"one", "two"}
+			})
+
+			Expect(rule.Check(page("http://foo.faketld/", ""))).To(Equal([]Finding{
+				{Rule: "custom", Category: SEO, Severity: INFO, Message: "one"},
+				{Rule: "custom", Category: SEO, Severity: INFO, Message: "two"},
+			}))
+		})
+	})
+
+	Describe("Engine", func() {
+		It("Should run every rule", func() {
+			e := GenerateEngine(
+				GenerateRule(SEO, "a", INFO, func(p *Page) []string { return []string{"a"} }),
+				GenerateRule(SEO, "b", ERROR, func(p *Page) []string { return []string{"b"} }),
+			)
+
+			Expect(len(e.Check(page("http://foo.faketld/", "")))).To(Equal(2))
+		})
+
+		It("Should collect site rule findings on finish", func() {
+			e := GenerateEngine(GenerateDuplicateRule(SEO, "dup", WARNING, "Title", Title))
+			e.Check(page("http://foo.faketld/a", "<title>Same</title>"))
+			e.Check(page("http://foo.faketld/b", "<title>Same</title>"))
+			e.Check(page("http://foo.faketld/c", "<title>Other</title>"))
+
+			findings := e.Finish()
+			Expect(findings).To(HaveLen(2))
+			Expect(findings["http://foo.faketld/a"]).To(Equal([]Finding{
+				{Rule: "dup", Category: SEO, Severity: WARNING, Message: `Title "Same" is shared with 1 other page(s)`},
+			}))
+			Expect(findings).To(HaveKey("http://foo.faketld/b"))
+		})
+
+		It("Should judge reused pages by their earlier values", func() {
+			e := GenerateEngine(
+				GenerateRule(SEO, "a", INFO, func(p *Page) []string { return nil }),
+				GenerateDuplicateRule(SEO, "dup", WARNING, "Title", Title),
+			)
+			values := e.Values(page("http://foo.faketld/a", "<title>Same</title>"))
+			Expect(values).To(Equal(map[string]string{"dup": "Same"}))
+
+			e.Reuse("http://foo.faketld/a", values)
+			e.Check(page("http://foo.faketld/b", "<title>Same</title>"))
+
+			findings := e.Finish()
+			Expect(findings).To(HaveKey("http://foo.faketld/a"))
+			Expect(findings).To(HaveKey("http://foo.faketld/b"))
+		})
+
+		It("Should keep only the findings of per-page rules", func() {
+			e := GenerateEngine(
+				GenerateRule(SEO, "a", INFO, func(p *Page) []string { return nil }),
+				GenerateDuplicateRule(SEO, "dup", WARNING, "Title", Title),
+			)
+
+			Expect(e.PageFindings([]Finding{{Rule: "a"}, {Rule: "dup"}})).To(Equal([]Finding{{Rule: "a"}}))
+		})
+	})
+
+	Describe("Register", func() {
+		It("Should add custom rules after the built-in ones", func() {
+			before := len(Rules())
+			Register(func() Rule {
+				return GenerateRule(SEO, "registered", INFO, func(p *Page) []string { return nil })
+			})
+
+			rules := Rules()
+			Expect(len(rules)).To(Equal(before + 1))
+			Expect(rules[len(rules)-1].Name()).To(Equal("registered"))
+		})
+	})
+
+	Describe("Sort", func() {
+		It("Should order by severity then rule", func() {
+			findings := []Finding{
+				{Rule: "b", Severity: WARNING},
+				{Rule: "c", Severity: ERROR},
+				{Rule: "a", Severity: WARNING},
+			}
+			Sort(findings)
+
+			Expect(findings[0].Rule).To(Equal("c"))
+			Expect(findings[1].Rule).To(Equal("a"))
+			Expect(findings[2].Rule).To(Equal("b"))
+		})
+	})
+})
@@ -0,0 +1,234 @@
+package audit
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+const (
+	// MinTitleLength and MaxTitleLength bound the characters in a title
+	// that search engines will display in full
+	MinTitleLength = 10
+	MaxTitleLength = 60
+
+	// MaxPageSize is the most bytes of HTML a page should need
+	MaxPageSize = 1 << 20
+)
+
+// subresources lists elements that load other URLs into a page, along
+// with the attribute holding the URL
+var subresources = []struct {
+	selector string
+	attr     string
+}{
+	{"img[src]", "src"},
+	{"script[src]", "src"},
+	{"link[rel~=stylesheet][href]", "href"},
+	{"iframe[src]", "src"},
+	{"video[src]", "src"},
+	{"audio[src]", "src"},
+	{"source[src]", "src"},
+	{"embed[src]", "src"},
+	{"object[data]", "data"},
+}
+
+// BuiltinRules creates the SEO rules every audit runs
+func BuiltinRules() []Rule {
+	return []Rule{
+		GenerateRule(SEO, "title-missing", ERROR, checkTitleMissing),
+		GenerateRule(SEO, "title-length", WARNING, checkTitleLength),
+		GenerateDuplicateRule(SEO, "title-duplicate", WARNING, "Title", Title),
+		GenerateRule(SEO, "description-missing", WARNING, checkDescriptionMissing),
+		GenerateDuplicateRule(SEO, "description-duplicate", WARNING, "Meta description", Description),
+		GenerateRule(SEO, "h1-missing", WARNING, checkH1Missing),
+		GenerateRule(SEO, "h1-multiple", WARNING, checkH1Multiple),
+		GenerateRule(SEO, "img-alt-missing", WARNING, checkImageAlt),
+		GenerateRule(SEO, "lang-missing", WARNING, checkLang),
+		GenerateRule(SEO, "mixed-content", ERROR, checkMixedContent),
+		GenerateRule(SEO, "page-size", WARNING, checkPageSize),
+	}
+}
+
+// Title returns the trimmed text of the page's title
+func Title(page *Page) string {
+	return strings.TrimSpace(page.Doc.Find("head title").First().Text())
+}
+
+// Description returns the trimmed content of the page's meta description
+func Description(page *Page) string {
+	description := ""
+
+	page.Doc.Find("meta[name]").EachWithBreak(func(i int, s *goquery.Selection) bool {
+		name, _ := s.Attr("name")
+		if !strings.EqualFold(name, "description") {
+			return true
+		}
+
+		description, _ = s.Attr("content")
+		description = strings.TrimSpace(description)
+		return false
+	})
+
+	return description
+}
+
+func checkTitleMissing(page *Page) []string {
+	if Title(page) == "" {
+		return []string{"Page has no title"}
+	}
+
+	return nil
+}
+
+func checkTitleLength(page *Page) []string {
+	title := Title(page)
+	length := utf8.RuneCountInString(title)
+
+	if title == "" || (length >= MinTitleLength && length <= MaxTitleLength) {
+		return nil
+	}
+
+	return []string{fmt.Sprintf("Title is %d characters, keep it between %d and %d", length, MinTitleLength, MaxTitleLength)}
+}
+
+func checkDescriptionMissing(page *Page) []string {
+	if Description(page) == "" {
+		return []string{"Page has no meta description"}
+	}
+
+	return nil
+}
+
+func checkH1Missing(page *Page) []string {
+	if page.Doc.Find("h1").Length() == 0 {
+		return []string{"Page has no <h1>"}
+	}
+
+	return nil
+}
+
+func checkH1Multiple(page *Page) []string {
+	if count := page.Doc.Find("h1").Length(); count > 1 {
+		return []string{fmt.Sprintf("Page has %d <h1> elements", count)}
+	}
+
+	return nil
+}
+
+func checkImageAlt(page *Page) []string {
+	var messages []string
+
+	page.Doc.Find("img:not([alt])").Each(func(i int, s *goquery.Selection) {
+		src, _ := s.Attr("src")
+		messages = append(messages, fmt.Sprintf("Image %s has no alt attribute", src))
+	})
+
+	return messages
+}
+
+func checkLang(page *Page) []string {
+	lang, _ := page.Doc.Find("html").Attr("lang")
+
+	if strings.TrimSpace(lang) == "" {
+		return []string{"Page doesn't declare its language with <html lang>"}
+	}
+
+	return nil
+}
+
+func checkMixedContent(page *Page) []string {
+	if page.URL == nil || page.URL.Scheme != "https" {
+		return nil
+	}
+
+	var messages []string
+	seen := make(map[string]bool)
+
+	for _, subresource := range subresources {
+		page.Doc.Find(subresource.selector).Each(func(i int, s *goquery.Selection) {
+			value, _ := s.Attr(subresource.attr)
+			value = strings.TrimSpace(value)
+
+			if strings.HasPrefix(strings.ToLower(value), "http://") && !seen[value] {
+				seen[value] = true
+				messages = append(messages, fmt.Sprintf("Insecure subresource %s", value))
+			}
+		})
+	}
+
+	return messages
+}
+
+func checkPageSize(page *Page) []string {
+	if page.Size > MaxPageSize {
+		return []string{fmt.Sprintf("Page is %d KB, larger than %d KB", page.Size/1024, MaxPageSize/1024)}
+	}
+
+	return nil
+}
+
+// duplicateRule reports pages sharing the same non-empty value
+type duplicateRule struct {
+	category Category
+	name     string
+	severity Severity
+	label    string
+	value    func(page *Page) string
+	pages    map[string][]string
+}
+
+// GenerateDuplicateRule is a factory for a ValueRule reporting every page
+// whose value is shared with another page
+func GenerateDuplicateRule(category Category, name string, severity Severity, label string, value func(page *Page) string) ValueRule {
+	return &duplicateRule{
+		category: category,
+		name:     name,
+		severity: severity,
+		label:    label,
+		value:    value,
+		pages:    make(map[string][]string),
+	}
+}
+
+func (r *duplicateRule) Name() string {
+	return r.name
+}
+
+func (r *duplicateRule) Check(page *Page) []Finding {
+	r.Add(page.URL.String(), r.Value(page))
+	return nil
+}
+
+func (r *duplicateRule) Value(page *Page) string {
+	return r.value(page)
+}
+
+func (r *duplicateRule) Add(url string, value string) {
+	if value != "" {
+		r.pages[value] = append(r.pages[value], url)
+	}
+}
+
+func (r *duplicateRule) Finish() map[string][]Finding {
+	findings := make(map[string][]Finding)
+
+	for value, urls := range r.pages {
+		if len(urls) < 2 {
+			continue
+		}
+
+		for _, url := range urls {
+			findings[url] = append(findings[url], Finding{
+				Rule:     r.name,
+				Category: r.category,
+				Severity: r.severity,
+				Message:  fmt.Sprintf("%s %q is shared with %d other page(s)", r.label, value, len(urls)-1),
+			})
+		}
+	}
+
+	return findings
+}
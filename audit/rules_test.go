@@ -0,0 +1,77 @@
+package audit_test
+
+import (
+	"strings"
+
+	. "github.com/kevinoconnor7/digitalocean-crawler/audit"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// firing returns the names of the rules that fire for html
+func firing(rules []Rule, url string, html string) []string {
+	var names []string
+	for _, finding := range GenerateEngine(rules...).Check(page(url, html)) {
+		names = append(names, finding.Rule)
+	}
+	return names
+}
+
+var _ = Describe("Rules", func() {
+	rules := func(url string, html string) []string {
+		return firing(BuiltinRules(), url, html)
+	}
+
+	good := `<html lang="en"><head>
+<title>A perfectly reasonable title</title>
+<meta name="Description" content="What the page is about">
+</head><body><h1>Heading</h1><img src="/a.png" alt=""></body></html>`
+
+	It("Should pass a well formed page", func() {
+		Expect(rules("https://foo.faketld/", good)).To(BeEmpty())
+	})
+
+	It("Should flag a missing title and description", func() {
+		names := rules("http://foo.faketld/", `<html lang="en"><body><h1>Heading</h1></body></html>`)
+		Expect(names).To(ConsistOf("title-missing", "description-missing"))
+	})
+
+	It("Should flag titles of the wrong length", func() {
+		html := strings.Replace(good, "A perfectly reasonable title", "Short", 1)
+		Expect(rules("http://foo.faketld/", html)).To(Equal([]string{"title-length"}))
+
+		html = strings.Replace(good, "A perfectly reasonable title", strings.Repeat("Long ", 20), 1)
+		Expect(rules("http://foo.faketld/", html)).To(Equal([]string{"title-length"}))
+	})
+
+	It("Should flag missing and multiple h1s", func() {
+		html := strings.Replace(good, "<h1>Heading</h1>", "", 1)
+		Expect(rules("http://foo.faketld/", html)).To(Equal([]string{"h1-missing"}))
+
+		html = strings.Replace(good, "<h1>Heading</h1>", "<h1>One</h1><h1>Two</h1>", 1)
+		Expect(rules("http://foo.faketld/", html)).To(Equal([]string{"h1-multiple"}))
+	})
+
+	It("Should flag each image without alt", func() {
+		html := strings.Replace(good, `alt=""`, "", 1)
+		html = strings.Replace(html, "</body>", `<img src="/b.png" aria-label="B"></body>`, 1)
+		Expect(rules("http://foo.faketld/", html)).To(Equal([]string{"img-alt-missing", "img-alt-missing"}))
+	})
+
+	It("Should flag a missing lang", func() {
+		html := strings.Replace(good, ` lang="en"`, "", 1)
+		Expect(rules("http://foo.faketld/", html)).To(Equal([]string{"lang-missing"}))
+	})
+
+	It("Should flag http subresources on https pages", func() {
+		html := strings.Replace(good, "</body>", `<script src="http://cdn.faketld/a.js"></script><a href="http://other.faketld/">Link</a></body>`, 1)
+		Expect(rules("https://foo.faketld/", html)).To(Equal([]string{"mixed-content"}))
+		Expect(rules("http://foo.faketld/", html)).To(BeEmpty())
+	})
+
+	It("Should flag oversized pages", func() {
+		html := strings.Replace(good, "</body>", "<!--"+strings.Repeat("x", MaxPageSize)+"--></body>", 1)
+		Expect(rules("http://foo.faketld/", html)).To(Equal([]string{"page-size"}))
+	})
+})
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/kevinoconnor7/digitalocean-crawler/report"
+)
+
+// compression lists uncompressed text assets and the heaviest assets of a
+// saved crawl
+func compression(args []string) error {
+	flags := flag.NewFlagSet("compression", flag.ExitOnError)
+	asJSON := flags.Bool("json", false, "Print the report as JSON")
+	largest := flags.Int("largest", report.DefaultLargest, "Number of the heaviest assets to list")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: compression [flags] crawl.json")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return fmt.Errorf("Expected a crawl file")
+	}
+
+	if *largest < 0 {
+		return fmt.Errorf("largest can't be negative")
+	}
+
+	s, err := loadSnapshot(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	c := report.GenerateCompression(s, *largest)
+
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(c)
+	}
+
+	return c.WriteText(os.Stdout)
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+
+	"github.com/kevinoconnor7/digitalocean-crawler/crawler"
+)
+
+// crawl runs a single crawl to completion and saves its results
+func crawl(args []string) error {
+	flags := flag.NewFlagSet("crawl", flag.ExitOnError)
+	options := crawlFlags(flags)
+	chrome := renderFlags(flags)
+	out := flags.String("out", "", "File to write the crawl to, defaults to stdout")
+	warcDir := flags.String("warc", "", "Directory to archive every fetched response to as WARC files")
+	warcSize := flags.Int64("warcSize", 1024, "Size in MB to start a new WARC file at")
+	previous := flags.String("previous", "", "Earlier crawl of the site to only download changed pages since")
+	fixtures := flags.String("fixtures", "", "Directory to serve responses from instead of the network, laid out as by mirror")
+	flags.Parse(args)
+
+	seed, err := url.Parse(options.Seed)
+
+	if err != nil {
+		return err
+	}
+
+	stop, err := startRenderer(options, chrome)
+	if err != nil {
+		return err
+	}
+	defer stop()
+
+	config := options.Config()
+	config.WARCDir = *warcDir
+	config.WARCMaxSize = *warcSize << 20
+
+	if *previous != "" {
+		config.Previous, err = loadSnapshot(*previous)
+		if err != nil {
+			return err
+		}
+	}
+
+	if *fixtures != "" {
+		config.Fetcher = crawler.GenerateFixtureFetcher(*fixtures)
+	}
+
+	c := crawler.GenerateCrawlerWithConfig(seed, config)
+
+	err = c.Run(options.MaxPages)
+	if err != nil {
+		return err
+	}
+
+	return writeResults(c, *out)
+}
+
+// writeResults saves a finished crawl to path, or stdout if it's empty
+func writeResults(c *crawler.Crawler, path string) error {
+	results, err := c.OutputResults()
+	if err != nil {
+		return err
+	}
+
+	if path == "" {
+		fmt.Println(results)
+		return nil
+	}
+
+	return os.WriteFile(path, []byte(results), 0644)
+}
+
+// loadSnapshot reads a crawl saved by the crawl command
+func loadSnapshot(path string) (*crawler.Snapshot, error) {
+	file, err := os.Open(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+
+	s, err := crawler.LoadSnapshot(file)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to load crawl %s: %v", path, err)
+	}
+
+	return s, nil
+}
@@ -0,0 +1,59 @@
+package crawler
+
+import "github.com/kevinoconnor7/digitalocean-crawler/graph"
+
+// Analyze computes link graph metrics over the internal pages of the
+// snapshot and attaches them to those nodes, then clusters duplicate pages
+// The first node is taken to be the seed of the crawl
+func (s *Snapshot) Analyze() {
+	pages := make(map[int]int)
+	var indices []int
+
+	for i := range s.Nodes {
+		if s.Nodes[i].Type == PAGE && !s.Nodes[i].External {
+			pages[i] = len(indices)
+			indices = append(indices, i)
+		}
+	}
+
+	g := graph.GenerateGraph(len(indices))
+	for _, link := range s.Links {
+		source, ok := pages[link.Source]
+		if !ok {
+			continue
+		}
+
+		target, ok := pages[link.Target]
+		if !ok || source == target {
+			continue
+		}
+
+		g.AddEdge(source, target)
+	}
+
+	var known []int
+	for node, i := range indices {
+		if s.Nodes[i].InSitemap {
+			known = append(known, node)
+		}
+	}
+
+	root := -1
+	if seed, ok := pages[0]; ok {
+		root = seed
+	}
+
+	metrics := g.Analyze(root, known)
+	for node, i := range indices {
+		m := metrics[node]
+
+		// Pages that weren't fetched successfully have no outbound links
+		// to speak of, so don't count them as dead ends
+		status := s.Nodes[i].StatusCode
+		m.DeadEnd = m.DeadEnd && status >= 200 && status < 300
+
+		s.Nodes[i].Metrics = &m
+	}
+
+	s.Duplicates = s.FindDuplicates(DefaultSimHashDistance)
+}
@@ -1,5 +1,14 @@
 package crawler
 
+import (
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/kevinoconnor7/digitalocean-crawler/audit"
+	"github.com/kevinoconnor7/digitalocean-crawler/extract"
+	"github.com/kevinoconnor7/digitalocean-crawler/graph"
+)
+
 // ContentType Defines an enum for different asset content types
 type ContentType int
 
@@ -12,9 +21,25 @@ const (
 
 // Link defines directed link between to indicies that define an asset position
 type Link struct {
-	Source int `json:"source"`
-	Target int `json:"target"`
-	Value  int `json:"value"`
+	Source      int `json:"source"`
+	Target      int `json:"target"`
+	Value       int `json:"value"`
+	Nofollow    int `json:"nofollow,omitempty"`
+	Boilerplate int `json:"boilerplate,omitempty"`
+	Inferred    int `json:"inferred,omitempty"`
+}
+
+// LinkAttributes describes a single occurrence of a link on a page
+type LinkAttributes struct {
+	// Nofollow is set for anchors with rel="nofollow"
+	Nofollow bool
+
+	// Boilerplate is set for anchors inside site navigation or footers
+	Boilerplate bool
+
+	// Inferred is set for URLs guessed from scripts and attributes rather
+	// than found in links
+	Inferred bool
 }
 
 // GenerateLink acts as a factory for Link
@@ -28,11 +53,38 @@ func GenerateLink(source int, target int) *Link {
 
 // Asset models a crawled URL
 type Asset struct {
-	URL       string           `json:"url"`
-	Type      ContentType      `json:"type"`
-	Links     map[string]*Link `json:"-"`
-	processed bool
-	index     int
+	URL          string            `json:"url"`
+	Type         ContentType       `json:"type"`
+	StatusCode   int               `json:"statusCode,omitempty"`
+	Depth        int               `json:"depth"`
+	External     bool              `json:"external,omitempty"`
+	RedirectTo   string            `json:"redirectTo,omitempty"`
+	ContentHash  string            `json:"contentHash,omitempty"`
+	TextHash     string            `json:"textHash,omitempty"`
+	SimHash      SimHash           `json:"simHash,omitempty"`
+	Findings     []audit.Finding   `json:"findings,omitempty"`
+	AuditValues  map[string]string `json:"auditValues,omitempty"`
+	Metadata     *extract.Metadata `json:"metadata,omitempty"`
+	Fields       map[string]string `json:"fields,omitempty"`
+	WARCRecordID string            `json:"warcRecordId,omitempty"`
+	ETag         string            `json:"etag,omitempty"`
+	LastModified string            `json:"lastModified,omitempty"`
+	NotModified  bool              `json:"notModified,omitempty"`
+	Truncated    bool              `json:"truncated,omitempty"`
+	Charset      string            `json:"charset,omitempty"`
+	Rendered     bool              `json:"rendered,omitempty"`
+	RenderError  string            `json:"renderError,omitempty"`
+	MirrorError  string            `json:"mirrorError,omitempty"`
+	ContentType  string            `json:"contentType,omitempty"`
+	Encoding     string            `json:"encoding,omitempty"`
+	DecodeError  string            `json:"decodeError,omitempty"`
+	Size         int64             `json:"size,omitempty"`
+	TransferSize int64             `json:"transferSize,omitempty"`
+	InSitemap    bool              `json:"inSitemap,omitempty"`
+	Metrics      *graph.Metrics    `json:"metrics,omitempty"`
+	Links        map[string]*Link  `json:"-"`
+	processed    bool
+	index        int
 }
 
 // GenerateAsset is a factory for Asset
@@ -44,6 +96,12 @@ func GenerateAsset(url string, contentType ContentType) *Asset {
 	}
 }
 
+// HashContent returns the hex encoded SHA-256 of a response body
+func HashContent(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
+
 // GetIndex is an accessor for Asset.link
 func (a *Asset) GetIndex() int {
 	return a.index
@@ -52,15 +110,54 @@ func (a *Asset) GetIndex() int {
 // AddLink links the Asset as the parent to the given Asset
 // Returns the Link if created, else returns nil
 func (a *Asset) AddLink(newAsset *Asset) *Link {
+	return a.AddLinkWithAttributes(newAsset, LinkAttributes{})
+}
+
+// AddLinkWithAttributes links the Asset as the parent to the given Asset,
+// counting how many occurrences of the link have each attribute
+// Returns the Link if created, else returns nil
+func (a *Asset) AddLinkWithAttributes(newAsset *Asset, attrs LinkAttributes) *Link {
 	link, ok := a.Links[newAsset.URL]
 
 	if ok {
 		link.Value++
+		link.record(attrs)
 		return nil
 	}
 
 	link = GenerateLink(a.index, newAsset.index)
+	link.record(attrs)
 	a.Links[newAsset.URL] = link
 
 	return link
 }
+
+func (l *Link) record(attrs LinkAttributes) {
+	if attrs.Nofollow {
+		l.Nofollow++
+	}
+
+	if attrs.Boilerplate {
+		l.Boilerplate++
+	}
+
+	if attrs.Inferred {
+		l.Inferred++
+	}
+}
+
+// OnlyNofollow reports whether every occurrence of the link is nofollow
+func (l *Link) OnlyNofollow() bool {
+	return l.Nofollow >= l.Value
+}
+
+// OnlyBoilerplate reports whether every occurrence of the link is in
+// navigation or footers
+func (l *Link) OnlyBoilerplate() bool {
+	return l.Boilerplate >= l.Value
+}
+
+// OnlyInferred reports whether every occurrence of the link was inferred
+func (l *Link) OnlyInferred() bool {
+	return l.Inferred >= l.Value
+}
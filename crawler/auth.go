@@ -0,0 +1,240 @@
+package crawler
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Credentials authenticate every request to one host
+type Credentials struct {
+	// Username and Password are sent using basic auth
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+
+	// Token is sent as a bearer token
+	Token string `json:"token,omitempty"`
+
+	// Cookie is sent as is, e.g. "session=abc; theme=dark"
+	Cookie string `json:"cookie,omitempty"`
+}
+
+// Apply adds the credentials to a request
+func (cr Credentials) Apply(req *http.Request) {
+	if cr.Username != "" || cr.Password != "" {
+		req.SetBasicAuth(cr.Username, cr.Password)
+	}
+
+	if cr.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+cr.Token)
+	}
+
+	if cr.Cookie != "" {
+		req.Header.Add("Cookie", cr.Cookie)
+	}
+}
+
+// FormLogin logs in by submitting a form before the crawl starts, and
+// again whenever the session is found to have expired
+type FormLogin struct {
+	// URL is the page with the login form, or the endpoint to POST to if
+	// it doesn't have one
+	URL string `json:"url"`
+
+	// Form selects the login form on the page, defaulting to the first
+	Form string `json:"form,omitempty"`
+
+	// Fields are submitted with the form, overriding its own values
+	Fields map[string]string `json:"fields"`
+}
+
+// AuthConfig authenticates crawls of sites behind a login
+type AuthConfig struct {
+	// Hosts maps host names, with a port if not the default, to their
+	// credentials
+	Hosts map[string]Credentials `json:"hosts,omitempty"`
+
+	// Login is submitted before crawling, keeping the session in the
+	// client's cookie jar
+	Login *FormLogin `json:"login,omitempty"`
+}
+
+// Apply adds the credentials for the request's host, if any
+func (a AuthConfig) Apply(req *http.Request) {
+	credentials, ok := a.Hosts[strings.ToLower(req.URL.Host)]
+	if !ok {
+		credentials, ok = a.Hosts[strings.ToLower(req.URL.Hostname())]
+	}
+
+	if ok {
+		credentials.Apply(req)
+	}
+}
+
+// newRequest generates a request carrying the configured headers and
+// credentials
+// Headers often hold tokens, so like credentials they're only sent to the
+// host being crawled
+func (c *Crawler) newRequest(method string, urlStr string, body io.Reader) (*http.Request, error) {
+	req, err := GenerateRequest(method, urlStr, body)
+
+	if err != nil {
+		return nil, err
+	}
+
+	config := c.config.HTTP
+	if normalizeHost(req.URL.Host) != normalizeHost(c.baseURL.Host) {
+		config.Headers = nil
+	}
+
+	config.Apply(req)
+	c.config.Auth.Apply(req)
+
+	return req.WithContext(c.ctx), nil
+}
+
+// login submits the configured login form with the crawler's client so
+// that the session cookie is kept in its jar
+func (c *Crawler) login() error {
+	login := c.config.Auth.Login
+
+	page, err := url.Parse(login.URL)
+	if err != nil {
+		return fmt.Errorf("Invalid login URL: %v", err)
+	}
+
+	action, fields, err := c.loginForm(page)
+	if err != nil {
+		return err
+	}
+
+	for name, value := range login.Fields {
+		fields.Set(name, value)
+	}
+
+	req, err := c.newRequest("POST", action.String(), strings.NewReader(fields.Encode()))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := c.GetClient().Do(req)
+	if err != nil {
+		return fmt.Errorf("Unable to log in: %v", err)
+	}
+
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	if resp.StatusCode >= 400 || c.redirectsToLogin(action, resp.StatusCode, resp.Header) {
+		return fmt.Errorf("Login at %s failed with status %d", action, resp.StatusCode)
+	}
+
+	return nil
+}
+
+// loginForm fetches the login page and returns where its form submits to
+// along with the values already in it, such as CSRF tokens
+// Pages without a form are posted to directly
+func (c *Crawler) loginForm(page *url.URL) (*url.URL, url.Values, error) {
+	fields := make(url.Values)
+
+	req, err := c.newRequest("GET", page.String(), nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	resp, err := c.GetClient().Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Unable to fetch login page: %v", err)
+	}
+
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		return page, fields, nil
+	}
+
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(body))
+	if err != nil {
+		return page, fields, nil
+	}
+
+	selector := c.config.Auth.Login.Form
+	if selector == "" {
+		selector = "form"
+	}
+
+	form := doc.Find(selector).First()
+	if form.Length() == 0 {
+		return page, fields, nil
+	}
+
+	action := page
+	if value, ok := form.Attr("action"); ok && strings.TrimSpace(value) != "" {
+		resolved, err := page.Parse(strings.TrimSpace(value))
+		if err != nil {
+			return nil, nil, fmt.Errorf("Invalid login form action: %v", err)
+		}
+		action = resolved
+	}
+
+	form.Find("input[name], select[name], textarea[name]").Each(func(i int, s *goquery.Selection) {
+		name, _ := s.Attr("name")
+
+		switch strings.ToLower(s.AttrOr("type", "")) {
+		case "submit", "button", "image", "reset", "file":
+			return
+		case "checkbox", "radio":
+			if _, checked := s.Attr("checked"); !checked {
+				return
+			}
+		}
+
+		value, ok := s.Attr("value")
+		if !ok && !s.Is("input") {
+			value = s.Text()
+		}
+
+		fields.Add(name, value)
+	})
+
+	return action, fields, nil
+}
+
+// loggedOut reports whether a response shows the session has expired,
+// either by refusing the request or redirecting to the login page
+func (c *Crawler) loggedOut(requestURL *url.URL, resp *Response) bool {
+	if c.config.Auth.Login == nil {
+		return false
+	}
+
+	return resp.StatusCode == http.StatusUnauthorized || c.redirectsToLogin(requestURL, resp.StatusCode, resp.Header)
+}
+
+// redirectsToLogin reports whether a response redirects to the login page
+func (c *Crawler) redirectsToLogin(requestURL *url.URL, statusCode int, header http.Header) bool {
+	location := header.Get("Location")
+	if !isRedirect(statusCode) || location == "" {
+		return false
+	}
+
+	target, err := requestURL.Parse(location)
+	if err != nil {
+		return false
+	}
+
+	login, err := url.Parse(c.config.Auth.Login.URL)
+	if err != nil {
+		return false
+	}
+
+	return strings.EqualFold(target.Host, login.Host) && strings.TrimSuffix(target.Path, "/") == strings.TrimSuffix(login.Path, "/")
+}
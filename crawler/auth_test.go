@@ -0,0 +1,171 @@
+package crawler_test
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	u "net/url"
+	"os"
+
+	. "github.com/kevinoconnor7/digitalocean-crawler/crawler"
+	"github.com/kevinoconnor7/digitalocean-crawler/warc"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Auth", func() {
+	Describe("Credentials", func() {
+		request := func(url string, auth AuthConfig) *http.Request {
+			req, _ := GenerateRequest("GET", url, nil)
+			auth.Apply(req)
+			return req
+		}
+
+		It("Should apply credentials for the request's host", func() {
+			auth := AuthConfig{Hosts: map[string]Credentials{
+				"basic.faketld":      {Username: "user", Password: "secret"},
+				"token.faketld:8080": {Token: "abc"},
+				"cookie.faketld":     {Cookie: "session=1"},
+			}}
+
+			username, password, ok := request("http://basic.faketld/", auth).BasicAuth()
+			Expect(ok).To(BeTrue())
+			Expect(username).To(Equal("user"))
+			Expect(password).To(Equal("secret"))
+
+			Expect(request("http://token.faketld:8080/", auth).Header.Get("Authorization")).To(Equal("Bearer abc"))
+			Expect(request("http://token.faketld/", auth).Header.Get("Authorization")).To(BeEmpty())
+			Expect(request("http://cookie.faketld:8080/", auth).Header.Get("Cookie")).To(Equal("session=1"))
+		})
+
+		It("Should not send credentials to other hosts", func() {
+			auth := AuthConfig{Hosts: map[string]Credentials{"basic.faketld": {Username: "user"}}}
+
+			_, _, ok := request("http://elsewhere.faketld/", auth).BasicAuth()
+			Expect(ok).To(BeFalse())
+		})
+
+		It("Should not archive credentials", func() {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, `<a href="/about">About</a>`)
+			}))
+			defer ts.Close()
+
+			dir, err := os.MkdirTemp("", "warc")
+			Expect(err).ToNot(HaveOccurred())
+			defer os.RemoveAll(dir)
+
+			url, _ := u.Parse(ts.URL + "/")
+			config := DefaultConfig()
+			config.WARCDir = dir
+			config.Auth.Hosts = map[string]Credentials{url.Host: {Username: "user", Password: "secret"}}
+			config.HTTP.Headers = http.Header{"Cookie": {"session=secret"}}
+
+			c := GenerateCrawlerWithConfig(url, config)
+			Expect(c.Run(10)).To(Succeed())
+			Expect(c.Assets[ts.URL+"/about"].StatusCode).To(Equal(http.StatusOK))
+
+			files, err := os.ReadDir(dir)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(files).To(HaveLen(1))
+
+			file, err := os.Open(dir + "/" + files[0].Name())
+			Expect(err).ToNot(HaveOccurred())
+			defer file.Close()
+
+			archive, err := gzip.NewReader(file)
+			Expect(err).ToNot(HaveOccurred())
+
+			contents, err := io.ReadAll(archive)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(contents)).To(ContainSubstring("Authorization: " + warc.Redacted))
+			Expect(string(contents)).ToNot(ContainSubstring("secret"))
+			Expect(string(contents)).ToNot(ContainSubstring("dXNlcjpzZWNyZXQ="))
+		})
+	})
+
+	Describe("FormLogin", func() {
+		var (
+			ts     *httptest.Server
+			logins int
+		)
+
+		BeforeEach(func() {
+			logins = 0
+
+			mux := http.NewServeMux()
+			mux.HandleFunc("GET /login", func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, `<form action="/session" method="post">
+	<input type="hidden" name="csrf" value="token">
+	<input name="username">
+	<input type="password" name="password">
+	<input type="checkbox" name="remember">
+	<input type="submit" name="go" value="Log in">
+</form>`)
+			})
+			mux.HandleFunc("POST /session", func(w http.ResponseWriter, r *http.Request) {
+				r.ParseForm()
+				if r.Form.Get("csrf") != "token" || r.Form.Get("password") != "secret" || r.Form.Has("remember") || r.Form.Has("go") {
+					http.Redirect(w, r, "/login", http.StatusFound)
+					return
+				}
+
+				logins++
+				http.SetCookie(w, &http.Cookie{Name: "session", Value: "valid", Path: "/"})
+				http.Redirect(w, r, "/", http.StatusFound)
+			})
+			mux.HandleFunc("/logout", func(w http.ResponseWriter, r *http.Request) {
+				http.SetCookie(w, &http.Cookie{Name: "session", Value: "", Path: "/", MaxAge: -1})
+				fmt.Fprint(w, `<a href="/private">Private</a>`)
+			})
+			mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+				if cookie, err := r.Cookie("session"); err != nil || cookie.Value != "valid" {
+					http.Redirect(w, r, "/login", http.StatusFound)
+					return
+				}
+
+				fmt.Fprint(w, `<a href="/logout">Log out</a>`)
+			})
+			ts = httptest.NewServer(mux)
+		})
+
+		AfterEach(func() {
+			ts.Close()
+		})
+
+		crawler := func(password string) *Crawler {
+			url, _ := u.Parse(ts.URL + "/")
+			config := DefaultConfig()
+			config.Auth.Login = &FormLogin{
+				URL:    ts.URL + "/login",
+				Fields: map[string]string{"username": "user", "password": password},
+			}
+
+			return GenerateCrawlerWithConfig(url, config)
+		}
+
+		It("Should log in before crawling", func() {
+			c := crawler("secret")
+			Expect(c.Run(10)).To(Succeed())
+			Expect(c.Assets[ts.URL+"/"].StatusCode).To(Equal(http.StatusOK))
+			Expect(c.Assets[ts.URL+"/login"]).To(BeNil())
+		})
+
+		It("Should log in again when logged out", func() {
+			c := crawler("secret")
+			Expect(c.Run(10)).To(Succeed())
+			Expect(c.Assets[ts.URL+"/logout"].StatusCode).To(Equal(http.StatusOK))
+			Expect(c.Assets[ts.URL+"/private"].StatusCode).To(Equal(http.StatusOK))
+			Expect(logins).To(Equal(2))
+		})
+
+		It("Should fail the crawl if the login is rejected", func() {
+			c := crawler("wrong")
+			Expect(c.Run(10)).ToNot(Succeed())
+			Expect(c.State()).To(Equal(FAILED))
+		})
+	})
+})
@@ -0,0 +1,152 @@
+package crawler
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/charset"
+	"golang.org/x/net/idna"
+)
+
+// utf8BOM starts some UTF-8 documents, and isn't part of their content
+var utf8BOM = []byte("\xef\xbb\xbf")
+
+// DecodeHTML transcodes an HTML body to UTF-8, returning it along with the
+// name of the encoding it was in
+// As in browsers, a byte order mark wins over the Content-Type header,
+// which wins over a <meta> charset near the start of the document
+func DecodeHTML(body []byte, contentType string) ([]byte, string) {
+	encoding, name, certain := charset.DetermineEncoding(body, contentType)
+
+	// Without any declaration the detector assumes windows-1252, but
+	// undeclared pages that are valid UTF-8 almost always are UTF-8
+	if !certain && name == "windows-1252" && !metaCharset(body) && utf8.Valid(trimPartialRune(body)) {
+		name = "utf-8"
+	}
+
+	if name == "utf-8" {
+		return bytes.TrimPrefix(body, utf8BOM), name
+	}
+
+	decoded, err := encoding.NewDecoder().Bytes(body)
+	if err != nil {
+		return body, name
+	}
+
+	return decoded, name
+}
+
+// metaCharset reports whether a <meta> tag near the start of a document
+// declares a known charset, as the detector looks for one
+func metaCharset(body []byte) bool {
+	if len(body) > 1024 {
+		body = body[:1024]
+	}
+
+	z := html.NewTokenizer(bytes.NewReader(body))
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			return false
+		case html.StartTagToken, html.SelfClosingTagToken:
+			tag, hasAttr := z.TagName()
+			if string(tag) != "meta" {
+				continue
+			}
+
+			var httpEquiv, content, declared string
+			for hasAttr {
+				var key, value []byte
+				key, value, hasAttr = z.TagAttr()
+
+				switch string(key) {
+				case "http-equiv":
+					httpEquiv = strings.ToLower(string(value))
+				case "content":
+					content = string(value)
+				case "charset":
+					declared = string(value)
+				}
+			}
+
+			if declared == "" && httpEquiv == "content-type" {
+				_, params, err := mime.ParseMediaType(content)
+				if err == nil {
+					declared = params["charset"]
+				}
+			}
+
+			if encoding, _ := charset.Lookup(declared); encoding != nil {
+				return true
+			}
+		}
+	}
+}
+
+// trimPartialRune drops a UTF-8 sequence cut off at the end of a body, as
+// bodies truncated at the maximum size may end in the middle of one
+func trimPartialRune(body []byte) []byte {
+	for i := len(body) - 1; i >= 0 && i >= len(body)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(body[i]) {
+			if !utf8.FullRune(body[i:]) {
+				return body[:i]
+			}
+			break
+		}
+	}
+
+	return body
+}
+
+// normalizeURL puts the parts of a URL browsers treat as equivalent into
+// one canonical form, so that each page is only crawled once
+func normalizeURL(u *url.URL) {
+	u.Host = normalizeHost(u.Host)
+
+	// Percent-encoding is case insensitive, so a raw path is only kept if
+	// it means something different from the decoded one, like an escaped /
+	if u.RawPath != "" && strings.EqualFold(u.RawPath, (&url.URL{Path: u.Path}).EscapedPath()) {
+		u.RawPath = ""
+	}
+
+	u.RawQuery = escapeNonASCII(u.RawQuery)
+}
+
+// normalizeHost lowercases a host and converts internationalized domain
+// names to their ASCII form, keeping any port
+func normalizeHost(host string) string {
+	name, port := host, ""
+	if i := strings.LastIndex(host, ":"); i >= 0 && !strings.HasSuffix(host, "]") {
+		name, port = host[:i], host[i:]
+	}
+
+	ascii, err := idna.Lookup.ToASCII(name)
+	if err != nil {
+		// Not a valid IDN, such as names with underscores
+		return strings.ToLower(host)
+	}
+
+	return ascii + port
+}
+
+// escapeNonASCII percent-encodes the non-ASCII bytes and spaces of a raw
+// query, which browsers send encoded
+func escapeNonASCII(raw string) string {
+	var b strings.Builder
+
+	for i := 0; i < len(raw); i++ {
+		if raw[i] >= utf8.RuneSelf || raw[i] == ' ' {
+			fmt.Fprintf(&b, "%%%02X", raw[i])
+			continue
+		}
+
+		b.WriteByte(raw[i])
+	}
+
+	return b.String()
+}
@@ -0,0 +1,133 @@
+package crawler_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	u "net/url"
+
+	. "github.com/kevinoconnor7/digitalocean-crawler/crawler"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func encode(text string, encoder interface{ String(string) (string, error) }) []byte {
+	encoded, err := encoder.String(text)
+	Expect(err).ToNot(HaveOccurred())
+	return []byte(encoded)
+}
+
+var _ = Describe("Charsets", func() {
+	Describe("DecodeHTML", func() {
+		It("Should use the charset from the header", func() {
+			body := encode("<p>日本語</p>", japanese.ShiftJIS.NewEncoder())
+
+			decoded, name := DecodeHTML(body, "text/html; charset=Shift_JIS")
+			Expect(name).To(Equal("shift_jis"))
+			Expect(string(decoded)).To(Equal("<p>日本語</p>"))
+		})
+
+		It("Should fall back to a meta charset", func() {
+			body := encode(`<meta charset="iso-8859-1"><p>café</p>`, charmap.ISO8859_1.NewEncoder())
+
+			decoded, name := DecodeHTML(body, "text/html")
+			Expect(name).To(Equal("windows-1252"))
+			Expect(string(decoded)).To(ContainSubstring("café"))
+		})
+
+		It("Should prefer the header to a meta charset", func() {
+			body := encode(`<meta charset="iso-8859-1"><p>日本語</p>`, japanese.ShiftJIS.NewEncoder())
+
+			decoded, name := DecodeHTML(body, "text/html; charset=shift_jis")
+			Expect(name).To(Equal("shift_jis"))
+			Expect(string(decoded)).To(ContainSubstring("日本語"))
+		})
+
+		It("Should prefer a byte order mark to the header", func() {
+			decoded, name := DecodeHTML([]byte("\xef\xbb\xbf<p>café</p>"), "text/html; charset=iso-8859-1")
+			Expect(name).To(Equal("utf-8"))
+			Expect(string(decoded)).To(Equal("<p>café</p>"))
+		})
+
+		It("Should treat undeclared valid UTF-8 as UTF-8", func() {
+			decoded, name := DecodeHTML([]byte("<p>café</p>"), "")
+			Expect(name).To(Equal("utf-8"))
+			Expect(string(decoded)).To(Equal("<p>café</p>"))
+		})
+
+		It("Should treat undeclared UTF-8 cut off mid-character as UTF-8", func() {
+			body := []byte("<p>café 日本語</p>")
+			decoded, name := DecodeHTML(body[:len(body)-len("語</p>")-1], "")
+			Expect(name).To(Equal("utf-8"))
+			Expect(string(decoded)).To(HavePrefix("<p>café 日"))
+		})
+
+		It("Should report a meta charset even when the page is plain ASCII", func() {
+			_, name := DecodeHTML([]byte(`<meta charset="iso-8859-1"><p>cafe</p>`), "text/html")
+			Expect(name).To(Equal("windows-1252"))
+
+			_, name = DecodeHTML([]byte(`<meta http-equiv="Content-Type" content="text/html; charset=iso-8859-1"><p>cafe</p>`), "")
+			Expect(name).To(Equal("windows-1252"))
+		})
+
+		It("Should treat undeclared invalid UTF-8 as windows-1252", func() {
+			decoded, name := DecodeHTML([]byte("<p>caf\xe9</p>"), "")
+			Expect(name).To(Equal("windows-1252"))
+			Expect(string(decoded)).To(Equal("<p>café</p>"))
+		})
+	})
+
+	Describe("ResolveURL", func() {
+		base, _ := u.Parse("http://bücher.example/")
+
+		It("Should convert internationalized hosts to ASCII", func() {
+			url, external, err := ResolveURL("http://BÜCHER.example/", base)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(url).To(Equal("http://xn--bcher-kva.example/"))
+			Expect(external).To(BeFalse())
+
+			url, external, _ = ResolveURL("http://xn--bcher-kva.example:8080/", base)
+			Expect(url).To(Equal("http://xn--bcher-kva.example:8080/"))
+			Expect(external).To(BeTrue())
+		})
+
+		It("Should percent-encode non-ASCII paths consistently", func() {
+			raw, _, _ := ResolveURL("/straße?q=süß&x=a b", base)
+			upper, _, _ := ResolveURL("/stra%C3%9Fe?q=s%C3%BC%C3%9F&x=a%20b", base)
+			lower, _, _ := ResolveURL("/stra%c3%9fe?q=s%C3%BC%C3%9F&x=a%20b", base)
+
+			Expect(raw).To(Equal("http://xn--bcher-kva.example/stra%C3%9Fe?q=s%C3%BC%C3%9F&x=a%20b"))
+			Expect(upper).To(Equal(raw))
+			Expect(lower).To(Equal(raw))
+		})
+
+		It("Should keep escaped slashes", func() {
+			url, _, _ := ResolveURL("/a%2Fb", base)
+			Expect(url).To(Equal("http://xn--bcher-kva.example/a%2Fb"))
+		})
+	})
+
+	It("Should decode pages before parsing them", func() {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/" {
+				return
+			}
+
+			w.Header().Set("Content-Type", "text/html; charset=Shift_JIS")
+			w.Write(encode(`<html><head><title>日本語のページ</title></head><body><a href="/日本">Link</a></body></html>`, japanese.ShiftJIS.NewEncoder()))
+		}))
+		defer ts.Close()
+
+		url, _ := u.Parse(ts.URL + "/")
+		c := GenerateCrawler(url)
+		Expect(c.Run(10)).To(Succeed())
+
+		home := c.Assets[ts.URL+"/"]
+		Expect(home.Charset).To(Equal("shift_jis"))
+		Expect(home.Metadata.Title).To(Equal("日本語のページ"))
+		Expect(c.Assets[ts.URL+"/%E6%97%A5%E6%9C%AC"]).ToNot(BeNil())
+		Expect(c.Assets[ts.URL+"/%E6%97%A5%E6%9C%AC"].StatusCode).To(Equal(http.StatusOK))
+	})
+})
@@ -0,0 +1,166 @@
+package crawler
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"time"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// DefaultUserAgent identifies the crawler unless another User-Agent is set
+const DefaultUserAgent = "Crawler - kevin@kevo.io"
+
+// HTTPConfig controls how the crawler's HTTP client connects and what it
+// sends with every request
+// Zero durations mean no timeout
+type HTTPConfig struct {
+	// ConnectTimeout limits establishing a connection, including TLS
+	ConnectTimeout time.Duration
+
+	// ReadTimeout limits waiting for response headers once a request is sent
+	ReadTimeout time.Duration
+
+	// Timeout limits the whole request, including reading the body
+	Timeout time.Duration
+
+	// Proxy is an http, https or socks5 proxy URL, defaulting to the
+	// HTTP_PROXY and HTTPS_PROXY environment variables
+	Proxy string
+
+	// CABundle is a PEM file of certificates to trust as well as the
+	// system's own
+	CABundle string
+
+	// InsecureSkipVerify accepts any TLS certificate, for staging sites
+	InsecureSkipVerify bool
+
+	// UserAgent defaults to DefaultUserAgent
+	UserAgent string
+
+	// Headers are added to every request the crawler sends to the host
+	// it's crawling
+	Headers http.Header
+
+	// Cookies keeps cookies set by the site between requests
+	Cookies bool
+
+	// DisableHTTP2 forces HTTP/1.1 even for servers supporting HTTP/2
+	DisableHTTP2 bool
+}
+
+// DefaultHTTPConfig returns the HTTPConfig used by DefaultConfig
+func DefaultHTTPConfig() HTTPConfig {
+	return HTTPConfig{
+		ConnectTimeout: 10 * time.Second,
+		ReadTimeout:    30 * time.Second,
+		Timeout:        time.Minute,
+	}
+}
+
+// Client builds an http.Client following the config
+// Redirects are never followed, as the crawler records them as links
+func (h HTTPConfig) Client() (*http.Client, error) {
+	dialer := &net.Dialer{
+		Timeout:   h.ConnectTimeout,
+		KeepAlive: 30 * time.Second,
+	}
+
+	transport := &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           dialer.DialContext,
+		TLSHandshakeTimeout:   h.ConnectTimeout,
+		ResponseHeaderTimeout: h.ReadTimeout,
+		ForceAttemptHTTP2:     !h.DisableHTTP2,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		TLSClientConfig:       &tls.Config{InsecureSkipVerify: h.InsecureSkipVerify},
+	}
+
+	if h.DisableHTTP2 {
+		// A non-nil empty map stops the transport from upgrading to HTTP/2
+		transport.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	}
+
+	if h.Proxy != "" {
+		proxy, err := url.Parse(h.Proxy)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid proxy: %v", err)
+		}
+
+		switch proxy.Scheme {
+		case "http", "https", "socks5", "socks5h":
+		default:
+			return nil, fmt.Errorf("Unsupported proxy scheme %q", proxy.Scheme)
+		}
+
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+
+	if h.CABundle != "" {
+		pool, err := loadCABundle(h.CABundle)
+		if err != nil {
+			return nil, err
+		}
+
+		transport.TLSClientConfig.RootCAs = pool
+	}
+
+	client := &http.Client{
+		Transport: transport,
+		Timeout:   h.Timeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	if h.Cookies {
+		jar, err := cookiejar.New(&cookiejar.Options{PublicSuffixList: publicsuffix.List})
+		if err != nil {
+			return nil, err
+		}
+
+		client.Jar = jar
+	}
+
+	return client, nil
+}
+
+// Apply sets the configured User-Agent and headers on a request
+func (h HTTPConfig) Apply(req *http.Request) {
+	if h.UserAgent != "" {
+		req.Header.Set("User-Agent", h.UserAgent)
+	}
+
+	for name, values := range h.Headers {
+		req.Header.Del(name)
+		for _, value := range values {
+			req.Header.Add(name, value)
+		}
+	}
+}
+
+// loadCABundle adds the certificates in a PEM file to the system's pool
+func loadCABundle(path string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to read CA bundle: %v", err)
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("No certificates found in CA bundle %s", path)
+	}
+
+	return pool, nil
+}
@@ -0,0 +1,200 @@
+package crawler_test
+
+import (
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	u "net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/kevinoconnor7/digitalocean-crawler/crawler"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("HTTPConfig", func() {
+	get := func(config HTTPConfig, url string) (*http.Response, error) {
+		client, err := config.Client()
+		Expect(err).ToNot(HaveOccurred())
+
+		req, err := GenerateRequest("GET", url, nil)
+		Expect(err).ToNot(HaveOccurred())
+		config.Apply(req)
+
+		return client.Do(req)
+	}
+
+	It("Should send the configured User-Agent and headers", func() {
+		var received http.Header
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			received = r.Header
+		}))
+		defer ts.Close()
+
+		_, err := get(HTTPConfig{}, ts.URL)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(received.Get("User-Agent")).To(Equal(DefaultUserAgent))
+
+		config := HTTPConfig{
+			UserAgent: "Test Agent",
+			Headers:   http.Header{"Authorization": {"Bearer token"}},
+		}
+		_, err = get(config, ts.URL)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(received.Get("User-Agent")).To(Equal("Test Agent"))
+		Expect(received.Get("Authorization")).To(Equal("Bearer token"))
+	})
+
+	It("Should only send headers to the crawled host", func() {
+		var seed, other http.Header
+		otherServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			other = r.Header
+			fmt.Fprint(w, `<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9"></urlset>`)
+		}))
+		defer otherServer.Close()
+
+		// A sitemap index can send the crawler to any host
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			seed = r.Header
+			fmt.Fprintf(w, `<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+	<sitemap><loc>%s/sitemap.xml</loc></sitemap>
+</sitemapindex>`, otherServer.URL)
+		}))
+		defer ts.Close()
+
+		url, _ := u.Parse(ts.URL + "/")
+		config := DefaultConfig()
+		config.SitemapURL = ts.URL + "/sitemap.xml"
+		config.HTTP.Headers = http.Header{"Authorization": {"Bearer token"}}
+
+		c := GenerateCrawlerWithConfig(url, config)
+		Expect(c.Run(10)).To(Succeed())
+		Expect(seed.Get("Authorization")).To(Equal("Bearer token"))
+		Expect(other).ToNot(BeNil())
+		Expect(other.Get("Authorization")).To(BeEmpty())
+		Expect(other.Get("User-Agent")).To(Equal(DefaultUserAgent))
+	})
+
+	It("Should not follow redirects", func() {
+		ts := httptest.NewServer(http.RedirectHandler("/elsewhere", http.StatusFound))
+		defer ts.Close()
+
+		resp, err := get(HTTPConfig{}, ts.URL)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resp.StatusCode).To(Equal(http.StatusFound))
+	})
+
+	It("Should keep cookies when enabled", func() {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if _, err := r.Cookie("session"); err != nil {
+				http.SetCookie(w, &http.Cookie{Name: "session", Value: "1"})
+				return
+			}
+			fmt.Fprint(w, "known")
+		}))
+		defer ts.Close()
+
+		client, err := HTTPConfig{Cookies: true}.Client()
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = client.Get(ts.URL)
+		Expect(err).ToNot(HaveOccurred())
+
+		resp, err := client.Get(ts.URL)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resp.Cookies()).To(BeEmpty())
+	})
+
+	It("Should time out slow responses", func() {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(200 * time.Millisecond)
+		}))
+		defer ts.Close()
+
+		_, err := get(HTTPConfig{ReadTimeout: 20 * time.Millisecond}, ts.URL)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("Should send requests through a proxy", func() {
+		var proxied string
+		proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			proxied = r.URL.String()
+		}))
+		defer proxy.Close()
+
+		_, err := get(HTTPConfig{Proxy: proxy.URL}, "http://example.faketld/page")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(proxied).To(Equal("http://example.faketld/page"))
+	})
+
+	It("Should reject unsupported proxies", func() {
+		_, err := HTTPConfig{Proxy: "ftp://proxy"}.Client()
+		Expect(err).To(HaveOccurred())
+
+		_, err = HTTPConfig{Proxy: "socks5://127.0.0.1:1080"}.Client()
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	Describe("TLS", func() {
+		var ts *httptest.Server
+
+		BeforeEach(func() {
+			ts = httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, r.Proto)
+			}))
+			ts.EnableHTTP2 = true
+			ts.StartTLS()
+		})
+
+		AfterEach(func() {
+			ts.Close()
+		})
+
+		It("Should reject unknown certificates", func() {
+			_, err := get(HTTPConfig{}, ts.URL)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("Should trust certificates from a CA bundle", func() {
+			dir, err := os.MkdirTemp("", "ca")
+			Expect(err).ToNot(HaveOccurred())
+			defer os.RemoveAll(dir)
+
+			bundle := filepath.Join(dir, "ca.pem")
+			certificate := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ts.Certificate().Raw})
+			Expect(os.WriteFile(bundle, certificate, 0644)).To(Succeed())
+
+			_, err = get(HTTPConfig{CABundle: bundle}, ts.URL)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(os.WriteFile(bundle, []byte("nothing"), 0644)).To(Succeed())
+			_, err = HTTPConfig{CABundle: bundle}.Client()
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("Should toggle HTTP/2", func() {
+			resp, err := get(HTTPConfig{InsecureSkipVerify: true}, ts.URL)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.ProtoMajor).To(Equal(2))
+
+			resp, err = get(HTTPConfig{InsecureSkipVerify: true, DisableHTTP2: true}, ts.URL)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.ProtoMajor).To(Equal(1))
+		})
+	})
+
+	It("Should fail crawls with an invalid configuration", func() {
+		url, _ := u.Parse("http://example.faketld/")
+		config := DefaultConfig()
+		config.HTTP.Proxy = "ftp://proxy"
+
+		c := GenerateCrawlerWithConfig(url, config)
+		Expect(c.Err()).To(HaveOccurred())
+		Expect(c.Run(1)).ToNot(Succeed())
+		Expect(c.State()).To(Equal(FAILED))
+	})
+})
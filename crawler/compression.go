@@ -0,0 +1,132 @@
+package crawler
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"mime"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+)
+
+// AcceptEncoding lists the content codings the HTTPFetcher can decode
+const AcceptEncoding = "gzip, br, zstd, deflate"
+
+// decoders wrap a compressed body in a reader of its decoded content
+var decoders = map[string]func(r io.Reader) (io.ReadCloser, error){
+	"gzip": func(r io.Reader) (io.ReadCloser, error) {
+		return gzip.NewReader(r)
+	},
+	"x-gzip": func(r io.Reader) (io.ReadCloser, error) {
+		return gzip.NewReader(r)
+	},
+	"br": func(r io.Reader) (io.ReadCloser, error) {
+		return io.NopCloser(brotli.NewReader(r)), nil
+	},
+	"zstd": func(r io.Reader) (io.ReadCloser, error) {
+		decoder, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return decoder.IOReadCloser(), nil
+	},
+	"deflate": func(r io.Reader) (io.ReadCloser, error) {
+		// Many servers send raw deflate data rather than the zlib wrapped
+		// data the coding calls for
+		buffered := bufio.NewReader(r)
+		header, err := buffered.Peek(2)
+		if err == nil && header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+			return zlib.NewReader(buffered)
+		}
+
+		return flate.NewReader(buffered), nil
+	},
+}
+
+// canDecode reports whether every coding in a Content-Encoding header is
+// supported
+func canDecode(contentEncoding string) bool {
+	for _, coding := range strings.Split(contentEncoding, ",") {
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		if _, ok := decoders[coding]; !ok && coding != "" && coding != "identity" {
+			return false
+		}
+	}
+
+	return true
+}
+
+// decodeContent wraps body in readers undoing each content coding listed
+// in a Content-Encoding header, which are applied in the order listed
+func decodeContent(body io.Reader, contentEncoding string) (io.Reader, []io.Closer, error) {
+	var closers []io.Closer
+
+	codings := strings.Split(contentEncoding, ",")
+	for i := len(codings) - 1; i >= 0; i-- {
+		coding := strings.ToLower(strings.TrimSpace(codings[i]))
+		if coding == "" || coding == "identity" {
+			continue
+		}
+
+		decoder, ok := decoders[coding]
+		if !ok {
+			return nil, closers, fmt.Errorf("Unsupported content encoding %q", coding)
+		}
+
+		decoded, err := decoder(body)
+		if err != nil {
+			return nil, closers, fmt.Errorf("Unable to decode %s content: %v", coding, err)
+		}
+
+		closers = append(closers, decoded)
+		body = decoded
+	}
+
+	return body, closers, nil
+}
+
+// decodeBody undoes the content codings of a raw body, reading up to
+// maxBodySize decoded bytes if it's positive, and reports whether the
+// decoded body was cut off
+// A raw body that was itself cut off decodes to as much as it holds
+func decodeBody(raw []byte, contentEncoding string, maxBodySize int64, rawTruncated bool) ([]byte, bool, error) {
+	reader, closers, err := decodeContent(bytes.NewReader(raw), contentEncoding)
+	for _, closer := range closers {
+		defer closer.Close()
+	}
+
+	if err != nil {
+		return nil, false, err
+	}
+
+	if maxBodySize > 0 {
+		reader = io.LimitReader(reader, maxBodySize+1)
+	}
+
+	body, err := io.ReadAll(reader)
+	if err != nil && !rawTruncated {
+		return nil, false, fmt.Errorf("Unable to decode %s content: %v", contentEncoding, err)
+	}
+
+	if maxBodySize > 0 && int64(len(body)) > maxBodySize {
+		return body[:maxBodySize], true, nil
+	}
+
+	return body, rawTruncated, nil
+}
+
+// mediaType strips any parameters from a Content-Type
+func mediaType(contentType string) string {
+	parsed, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return ""
+	}
+
+	return parsed
+}
@@ -0,0 +1,167 @@
+package crawler_test
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"net/http"
+	"net/http/httptest"
+	u "net/url"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+
+	. "github.com/kevinoconnor7/digitalocean-crawler/crawler"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Compression", func() {
+	var (
+		ts   *httptest.Server
+		page = `<html><body><a href="/main.css">` + strings.Repeat("lorem ipsum ", 200) + `</a></body></html>`
+	)
+
+	compress := func(encoding string) []byte {
+		var buf bytes.Buffer
+
+		switch encoding {
+		case "gzip":
+			w := gzip.NewWriter(&buf)
+			w.Write([]byte(page))
+			w.Close()
+		case "br":
+			w := brotli.NewWriter(&buf)
+			w.Write([]byte(page))
+			w.Close()
+		case "zstd":
+			w, _ := zstd.NewWriter(&buf)
+			w.Write([]byte(page))
+			w.Close()
+		case "deflate":
+			w := zlib.NewWriter(&buf)
+			w.Write([]byte(page))
+			w.Close()
+		default:
+			buf.WriteString(page)
+		}
+
+		return buf.Bytes()
+	}
+
+	BeforeEach(func() {
+		ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			encoding := strings.TrimPrefix(r.URL.Path, "/")
+			if r.URL.Path == "/main.css" {
+				w.Header().Set("Content-Type", "text/css; charset=utf-8")
+				w.Write([]byte("body {}"))
+				return
+			}
+
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			if encoding != "" {
+				w.Header().Set("Content-Encoding", encoding)
+			}
+			w.Write(compress(encoding))
+		}))
+	})
+
+	AfterEach(func() {
+		ts.Close()
+	})
+
+	crawl := func(path string) *Asset {
+		url, _ := u.Parse(ts.URL + path)
+		c := GenerateCrawler(url)
+		Expect(c.Run(1)).To(Succeed())
+		return c.Assets[ts.URL+path]
+	}
+
+	for _, encoding := range []string{"gzip", "br", "zstd", "deflate"} {
+		encoding := encoding
+
+		It("Should decode "+encoding+" responses", func() {
+			asset := crawl("/" + encoding)
+			Expect(asset.Encoding).To(Equal(encoding))
+			Expect(asset.Size).To(Equal(int64(len(page))))
+			Expect(asset.TransferSize).To(Equal(int64(len(compress(encoding)))))
+			Expect(asset.TransferSize).To(BeNumerically("<", asset.Size))
+			Expect(asset.ContentType).To(Equal("text/html"))
+			Expect(asset.ContentHash).To(Equal(HashContent([]byte(page))))
+		})
+	}
+
+	It("Should decode raw deflate responses", func() {
+		var buf bytes.Buffer
+		w, _ := flate.NewWriter(&buf, flate.DefaultCompression)
+		w.Write([]byte(page))
+		w.Close()
+
+		ts.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Encoding", "deflate")
+			w.Write(buf.Bytes())
+		})
+
+		asset := crawl("/")
+		Expect(asset.DecodeError).To(BeEmpty())
+		Expect(asset.ContentHash).To(Equal(HashContent([]byte(page))))
+	})
+
+	It("Should record bodies that fail to decode without failing the crawl", func() {
+		ts.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/" {
+				w.Header().Set("Content-Type", "text/html")
+				w.Write([]byte(`<a href="/broken">Broken</a><a href="/fine">Fine</a>`))
+				return
+			}
+
+			if r.URL.Path == "/broken" {
+				w.Header().Set("Content-Encoding", "gzip")
+			}
+			w.Write([]byte("not compressed"))
+		})
+
+		url, _ := u.Parse(ts.URL + "/")
+		c := GenerateCrawler(url)
+		Expect(c.Run(10)).To(Succeed())
+
+		broken := c.Assets[ts.URL+"/broken"]
+		Expect(broken.StatusCode).To(Equal(http.StatusOK))
+		Expect(broken.DecodeError).To(ContainSubstring("Unable to decode gzip content"))
+		Expect(broken.Size).To(Equal(int64(len("not compressed"))))
+		Expect(c.Assets[ts.URL+"/fine"].StatusCode).To(Equal(http.StatusOK))
+	})
+
+	It("Should ask for compressed responses", func() {
+		var accept string
+		ts.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			accept = r.Header.Get("Accept-Encoding")
+		})
+
+		crawl("/")
+		Expect(accept).To(Equal(AcceptEncoding))
+	})
+
+	It("Should measure uncompressed responses", func() {
+		asset := crawl("/")
+		Expect(asset.Encoding).To(BeEmpty())
+		Expect(asset.Size).To(Equal(int64(len(page))))
+		Expect(asset.TransferSize).To(Equal(asset.Size))
+	})
+
+	It("Should keep bodies with unsupported encodings raw", func() {
+		ts.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Encoding", "compress")
+			w.Write([]byte("raw"))
+		})
+
+		asset := crawl("/")
+		Expect(asset.StatusCode).To(Equal(http.StatusOK))
+		Expect(asset.Encoding).To(Equal("compress"))
+		Expect(asset.Size).To(Equal(int64(3)))
+		Expect(asset.TransferSize).To(Equal(int64(3)))
+	})
+})
@@ -0,0 +1,92 @@
+package crawler
+
+import (
+	"github.com/kevinoconnor7/digitalocean-crawler/extract"
+	"github.com/kevinoconnor7/digitalocean-crawler/render"
+)
+
+// Config controls optional crawler behaviour
+type Config struct {
+	// TrackExternal records links to other hosts as external assets
+	// without crawling them
+	TrackExternal bool
+
+	// SitemapURL is loaded before crawling so that pages listed in it are
+	// crawled and can be reported as orphans
+	SitemapURL string
+
+	// Audit checks every fetched page against the registered audit rules
+	Audit bool
+
+	// ExtractMetadata stores the title, headings, social tags and
+	// structured data of every page, which large crawls may not need
+	ExtractMetadata bool
+
+	// Fields are user defined values extracted from every matching page
+	Fields *extract.Fields
+
+	// MaxBodySize caps how many bytes of each response the default Fetcher
+	// reads, so huge or endless responses can't exhaust memory
+	// Sitemaps may be read up to MaxSitemapSize. Zero means no limit
+	MaxBodySize int64
+
+	// StreamLinks finds the links on pages with a tokenizer instead of
+	// building a DOM, skipping audits, metadata, fields and fingerprints
+	StreamLinks bool
+
+	// InferLinks also links pages to same-host URLs found in inline
+	// scripts, JSON-LD, data-* attributes and event handlers, marking them
+	// as inferred
+	InferLinks bool
+
+	// HeadAssets requests images, scripts and stylesheets with HEAD,
+	// sizing them from their Content-Length instead of downloading them
+	HeadAssets bool
+
+	// Renderer loads pages in a browser so that links added by scripts
+	// are found, if set
+	// Only pages with URLs matching RenderPatterns are rendered, or every
+	// page when there are none
+	Renderer       render.Renderer
+	RenderPatterns render.Patterns
+
+	// HTTP configures the client used by the default Fetcher and the
+	// headers sent with every request
+	HTTP HTTPConfig
+
+	// Auth authenticates requests to sites behind a login
+	Auth AuthConfig
+
+	// Previous is an earlier crawl of the same site, making this crawl
+	// incremental: assets are requested conditionally and the stored
+	// links of those that haven't changed are followed instead
+	Previous *Snapshot
+
+	// Fetcher retrieves every response, defaulting to fetching over HTTP
+	Fetcher Fetcher
+
+	// WARCDir is the directory every fetched response is archived to as
+	// WARC files, if set
+	WARCDir string
+
+	// WARCMaxSize is the size in bytes archives are rotated at, defaulting
+	// to warc.DefaultMaxSize
+	WARCMaxSize int64
+
+	// MirrorDir is the directory a browsable offline copy of every fetched
+	// asset is saved to, if set
+	MirrorDir string
+}
+
+// DefaultMaxBodySize is the MaxBodySize of DefaultConfig
+const DefaultMaxBodySize = 10 << 20
+
+// DefaultConfig returns the Config used by GenerateCrawler
+func DefaultConfig() Config {
+	return Config{
+		Audit:           true,
+		ExtractMetadata: true,
+		MaxBodySize:     DefaultMaxBodySize,
+		HTTP:            DefaultHTTPConfig(),
+	}
+}
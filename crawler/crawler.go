@@ -1,25 +1,51 @@
 package crawler
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"regexp"
+	"strconv"
+	"sync"
+	"time"
 
 	"github.com/PuerkitoBio/goquery"
+	"github.com/kevinoconnor7/digitalocean-crawler/audit"
+	"github.com/kevinoconnor7/digitalocean-crawler/extract"
+	"github.com/kevinoconnor7/digitalocean-crawler/mirror"
 	"github.com/kevinoconnor7/digitalocean-crawler/queue"
+	"github.com/kevinoconnor7/digitalocean-crawler/render"
+	"github.com/kevinoconnor7/digitalocean-crawler/warc"
 )
 
+// boilerplateSelector matches the site-wide regions links are repeated in
+const boilerplateSelector = "nav, footer, [role=navigation], [role=contentinfo]"
+
 type Crawler struct {
+	mu          sync.RWMutex
 	httpClient  *http.Client
 	Assets      map[string]*Asset `json:"-"`
 	AssetsArray []*Asset          `json:"nodes"`
 	Links       []*Link           `json:"links"`
 	toProcess   queue.Queue
 	baseURL     *url.URL
+	config      Config
 	matchRE     *regexp.Regexp
+	subscribers []chan Event
+	state       State
+	err         error
+	resumed     *sync.Cond
+	ctx         context.Context
+	cancel      context.CancelFunc
+	auditor     *audit.Engine
+	archive     *warc.Writer
+	mirror      *mirror.Mirror
+	fetcher     Fetcher
+	previous    map[string]*previousAsset
 }
 
 func Crawl(urlStr string, maxResults int) (*Crawler, error) {
@@ -30,12 +56,75 @@ func Crawl(urlStr string, maxResults int) (*Crawler, error) {
 	}
 
 	c := GenerateCrawler(url)
-	c.toProcess.Push(c.baseURL.String())
+	err = c.Run(maxResults)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// Run crawls outward from the base URL until the queue is empty,
+// maxResults has been exceeded or the crawl is cancelled
+func (c *Crawler) Run(maxResults int) error {
+	err := c.start()
+	if err != nil {
+		return err
+	}
+
+	if c.config.Auth.Login != nil {
+		err = c.login()
+	}
+
+	if err == nil && c.config.SitemapURL != "" {
+		err = c.LoadSitemap(c.config.SitemapURL)
+	}
+
+	if err == nil {
+		err = c.crawl(maxResults)
+	}
+	c.finishAudit()
+
+	if c.archive != nil {
+		if closeErr := c.archive.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	c.finish(err)
+
+	return err
+}
+
+// start moves a pending crawl to running and queues the base URL
+func (c *Crawler) start() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.state != PENDING {
+		return fmt.Errorf("Crawl is %s and can't be started", c.state)
+	}
+
+	if c.err != nil {
+		c.state = FAILED
+		return c.err
+	}
+
+	c.state = RUNNING
+	c.storeAsset(GenerateAsset(c.baseURL.String(), PAGE))
+
+	return nil
+}
+
+func (c *Crawler) crawl(maxResults int) error {
 	i := 0
-	for c.toProcess.Length > 0 {
-		err = c.ProcessQueue()
+	for c.proceed() && c.queueLength() > 0 {
+		err := c.ProcessQueue()
 		if err != nil {
-			return nil, err
+			if c.ctx.Err() != nil {
+				return nil
+			}
+			return err
 		}
 		i++
 		if i > maxResults {
@@ -43,24 +132,83 @@ func Crawl(urlStr string, maxResults int) (*Crawler, error) {
 		}
 	}
 
-	return c, nil
+	return nil
 }
 
 func GenerateCrawler(url *url.URL) *Crawler {
-	return &Crawler{
-		baseURL: url,
+	return GenerateCrawlerWithConfig(url, DefaultConfig())
+}
+
+// GenerateCrawlerWithConfig is a factory for a Crawler with non-default behaviour
+func GenerateCrawlerWithConfig(url *url.URL, config Config) *Crawler {
+	// The seed is stored in the same form as the links that lead back to it
+	base := *url
+	normalizeURL(&base)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &Crawler{
+		baseURL: &base,
+		config:  config,
 		Assets:  make(map[string]*Asset),
+		state:   PENDING,
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+	c.resumed = sync.NewCond(&c.mu)
+
+	if config.Audit {
+		c.auditor = audit.GenerateEngine(audit.Rules()...)
+	}
+
+	httpConfig := config.HTTP
+	if config.Auth.Login != nil {
+		// The session is kept in the cookie jar
+		httpConfig.Cookies = true
+	}
+
+	client, err := httpConfig.Client()
+	if err != nil {
+		// Reported when the crawl is started
+		c.err = fmt.Errorf("Invalid HTTP configuration: %v", err)
+		client = http.DefaultClient
+	}
+	c.httpClient = client
+
+	c.fetcher = config.Fetcher
+	if c.fetcher == nil {
+		fetcher := GenerateHTTPFetcher(c.GetClient())
+		fetcher.MaxBodySize = config.MaxBodySize
+		c.fetcher = fetcher
+	}
+
+	if config.WARCDir != "" {
+		prefix := "crawl-" + time.Now().UTC().Format("20060102150405")
+		c.archive = warc.GenerateWriter(config.WARCDir, prefix, config.WARCMaxSize)
+		c.fetcher = &RecordingFetcher{Fetcher: c.fetcher, Writer: c.archive}
 	}
+
+	if config.Previous != nil {
+		c.previous = indexPrevious(config.Previous)
+	}
+
+	if config.MirrorDir != "" {
+		c.mirror = mirror.GenerateMirror(config.MirrorDir, url, ResolveURL)
+	}
+
+	return c
 }
 
 func (c *Crawler) ProcessQueue() error {
+	c.mu.Lock()
 	if c.toProcess.Length <= 0 {
+		c.mu.Unlock()
 		return fmt.Errorf("Nothing left in queue to process")
 	}
 
 	url, ok := c.toProcess.Pop().(string)
 
 	if !ok {
+		c.mu.Unlock()
 		return fmt.Errorf("Non-string type in queue")
 	}
 
@@ -68,39 +216,190 @@ func (c *Crawler) ProcessQueue() error {
 
 	if !ok {
 		asset = GenerateAsset(url, PAGE)
-		c.StoreAsset(asset)
+		c.storeAsset(asset)
 	}
 
 	if asset.processed == true {
+		c.mu.Unlock()
 		return nil
 	}
 
 	asset.processed = true
+	c.mu.Unlock()
 
-	req, err := GenerateRequest("GET", url, nil)
-
+	resp, err := c.fetchAsset(asset)
 	if err != nil {
 		return err
 	}
 
-	resp, err := c.GetClient().Do(req)
+	return c.processResponse(asset, resp)
+}
+
+// processResponse records a fetched or replayed response against its
+// asset and follows the links in it
+func (c *Crawler) processResponse(asset *Asset, resp *Response) error {
+	body := resp.Body
+
+	c.mu.Lock()
+	if resp.RecordID != "" {
+		asset.WARCRecordID = resp.RecordID
+	}
+	c.mu.Unlock()
+
+	if previous, ok := c.previous[asset.URL]; ok && resp.StatusCode == http.StatusNotModified {
+		c.reusePrevious(asset, previous, resp)
+		return nil
+	}
+
+	if resp.Head {
+		c.processHead(asset, resp)
+		return nil
+	}
+
+	c.mu.Lock()
+	asset.StatusCode = resp.StatusCode
+	asset.ContentHash = HashContent(body)
+	asset.Truncated = resp.Truncated
+	asset.Size = int64(len(body))
+	asset.TransferSize = resp.TransferSize
+	asset.Encoding = resp.Encoding
+	asset.DecodeError = resp.DecodeError
+	asset.ContentType = mediaType(resp.Header.Get("Content-Type"))
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		asset.ETag = resp.Header.Get("ETag")
+		asset.LastModified = resp.Header.Get("Last-Modified")
+	}
+	c.mu.Unlock()
+
+	// An asset that can't be mirrored is still crawled
+	if c.mirror != nil && !asset.External && resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		_, err := c.mirror.Save(asset.URL, resp.Header.Get("Content-Type"), body)
+		if err != nil {
+			c.mu.Lock()
+			asset.MirrorError = err.Error()
+			c.mu.Unlock()
+		}
+	}
+
+	if isRedirect(resp.StatusCode) && resp.Header.Get("Location") != "" {
+		c.HandleRedirect(resp.Header.Get("Location"), asset)
+	}
+
+	// Pages are parsed as UTF-8, whatever they were served as
+	if asset.Type == PAGE {
+		decoded, name := DecodeHTML(body, resp.Header.Get("Content-Type"))
+
+		c.mu.Lock()
+		asset.Charset = name
+		c.mu.Unlock()
+
+		body = decoded
+	}
+
+	var requests []render.Request
+	if c.renders(asset, resp) {
+		body, requests = c.render(asset, body)
+	}
+
+	// Only pages need a DOM, for audits and extraction
+	if asset.Type != PAGE || c.config.StreamLinks {
+		err := ScanLinks(bytes.NewReader(body), func(url string, contentType ContentType, attrs LinkAttributes) {
+			c.HandleLink(url, asset, contentType, attrs)
+		})
+		c.linkRequests(asset, requests)
+
+		if err == nil {
+			err = c.inferLinks(asset, body)
+		}
+
+		c.mu.Lock()
+		c.emitAsset(ASSET_FETCHED, asset)
+		c.mu.Unlock()
 
-	if err != nil {
 		return err
 	}
 
-	doc, err := goquery.NewDocumentFromResponse(resp)
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
 
 	err = c.ProcessDoc(doc, asset)
+	c.linkRequests(asset, requests)
+
+	if err == nil {
+		err = c.inferLinks(asset, body)
+	}
+
+	// Only fingerprint and audit pages with content worth checking
+	if asset.Type == PAGE && resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		text := MainText(doc)
+		findings, values := c.auditPage(asset, doc, len(resp.Body))
+
+		c.mu.Lock()
+		asset.TextHash = HashText(text)
+		asset.SimHash = ComputeSimHash(text)
+		asset.Findings = findings
+		asset.AuditValues = values
+		c.mu.Unlock()
+	}
+
+	c.mu.Lock()
+	c.emitAsset(ASSET_FETCHED, asset)
+	c.mu.Unlock()
 
 	return err
 }
 
+// processHead records the response to a HEAD request for a subresource,
+// which is sized from its Content-Length
+func (c *Crawler) processHead(asset *Asset, resp *Response) {
+	length, _ := strconv.ParseInt(resp.Header.Get("Content-Length"), 10, 64)
+
+	c.mu.Lock()
+	asset.StatusCode = resp.StatusCode
+	asset.ContentType = mediaType(resp.Header.Get("Content-Type"))
+	asset.Encoding = resp.Header.Get("Content-Encoding")
+	asset.TransferSize = length
+	if asset.Encoding == "" {
+		asset.Size = length
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		asset.ETag = resp.Header.Get("ETag")
+		asset.LastModified = resp.Header.Get("Last-Modified")
+	}
+	c.mu.Unlock()
+
+	if isRedirect(resp.StatusCode) && resp.Header.Get("Location") != "" {
+		c.HandleRedirect(resp.Header.Get("Location"), asset)
+	}
+
+	c.mu.Lock()
+	c.emitAsset(ASSET_FETCHED, asset)
+	c.mu.Unlock()
+}
+
 func (c *Crawler) GetQueue() *queue.Queue {
 	return &c.toProcess
 }
 
 func (c *Crawler) ProcessDoc(doc *goquery.Document, asset *Asset) error {
+	if c.config.ExtractMetadata && asset.Type == PAGE {
+		metadata := extract.Extract(doc)
+
+		c.mu.Lock()
+		asset.Metadata = metadata
+		c.mu.Unlock()
+	}
+
+	if c.config.Fields != nil && asset.Type == PAGE {
+		fields := c.config.Fields.Extract(asset.URL, doc)
+
+		c.mu.Lock()
+		asset.Fields = fields
+		c.mu.Unlock()
+	}
+
 	doc.Find("a").Each(func(i int, s *goquery.Selection) {
 		url, ok := s.Attr("href")
 
@@ -108,7 +407,7 @@ func (c *Crawler) ProcessDoc(doc *goquery.Document, asset *Asset) error {
 			return
 		}
 
-		c.HandleURL(url, asset, PAGE)
+		c.HandleLink(url, asset, PAGE, GetLinkAttributes(s))
 	})
 
 	doc.Find("img").Each(func(i int, s *goquery.Selection) {
@@ -143,13 +442,92 @@ func (c *Crawler) ProcessDoc(doc *goquery.Document, asset *Asset) error {
 	return nil
 }
 
+// auditPage runs the audit rules over a fetched page, if auditing is
+// enabled, returning its findings and the values site rules judge it by
+func (c *Crawler) auditPage(asset *Asset, doc *goquery.Document, size int) ([]audit.Finding, map[string]string) {
+	if c.auditor == nil {
+		return nil, nil
+	}
+
+	pageURL, err := url.Parse(asset.URL)
+	if err != nil {
+		return nil, nil
+	}
+
+	page := &audit.Page{URL: pageURL, Doc: doc, Size: size}
+	findings := c.auditor.Check(page)
+
+	values := c.auditor.Values(page)
+	if len(values) == 0 {
+		values = nil
+	}
+
+	return findings, values
+}
+
+// reuseAudit judges a page unchanged since the previous crawl by what it
+// was judged by then, returning the findings that still stand
+// Findings of site rules are dropped, as they depend on other pages, and
+// found again when the audit finishes
+func (c *Crawler) reuseAudit(asset *Asset, previous *Asset) []audit.Finding {
+	if c.auditor == nil {
+		return previous.Findings
+	}
+
+	c.auditor.Reuse(asset.URL, previous.AuditValues)
+
+	return c.auditor.PageFindings(previous.Findings)
+}
+
+// finishAudit adds the findings that needed every page to have been seen
+func (c *Crawler) finishAudit() {
+	if c.auditor == nil {
+		return
+	}
+
+	findings := c.auditor.Finish()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for pageURL, pageFindings := range findings {
+		asset, ok := c.Assets[pageURL]
+		if !ok {
+			continue
+		}
+
+		merged := append(append([]audit.Finding{}, asset.Findings...), pageFindings...)
+		audit.Sort(merged)
+		asset.Findings = merged
+	}
+}
+
+// GetLinkAttributes inspects an anchor for nofollow and boilerplate placement
+func GetLinkAttributes(s *goquery.Selection) LinkAttributes {
+	attrs := LinkAttributes{
+		Boilerplate: s.Closest(boilerplateSelector).Length() > 0,
+	}
+
+	rel, _ := s.Attr("rel")
+	attrs.Nofollow = isNofollow(rel)
+
+	return attrs
+}
+
+// StoreAsset records the asset and queues it for processing
+// Returns false if an asset with the same URL was already stored
 func (c *Crawler) StoreAsset(asset *Asset) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.storeAsset(asset)
+}
+
+func (c *Crawler) storeAsset(asset *Asset) bool {
 	_, ok := c.Assets[asset.URL]
 
 	if !ok {
-		asset.index = len(c.AssetsArray)
-		c.AssetsArray = append(c.AssetsArray, asset)
-		c.Assets[asset.URL] = asset
+		c.addAsset(asset)
 		c.toProcess.Push(asset.URL)
 		return true
 	}
@@ -157,39 +535,117 @@ func (c *Crawler) StoreAsset(asset *Asset) bool {
 	return false
 }
 
+// addAsset indexes the asset into the graph without queueing it
+func (c *Crawler) addAsset(asset *Asset) {
+	asset.index = len(c.AssetsArray)
+	c.AssetsArray = append(c.AssetsArray, asset)
+	c.Assets[asset.URL] = asset
+	c.emitAsset(ASSET_DISCOVERED, asset)
+}
+
+// GetClient returns the HTTP client built from the crawler's HTTPConfig
 func (c *Crawler) GetClient() *http.Client {
-	if c.httpClient == nil {
-		c.httpClient = &http.Client{}
+	return c.httpClient
+}
+
+// HandleRedirect records where asset redirects to and links it to the target
+func (c *Crawler) HandleRedirect(location string, asset *Asset) {
+	target, _, err := ResolveURL(location, c.baseURL)
+	if err != nil {
+		return
 	}
 
-	return c.httpClient
+	c.mu.Lock()
+	asset.RedirectTo = target
+	c.mu.Unlock()
+
+	c.HandleURL(location, asset, asset.Type)
 }
 
+func isRedirect(statusCode int) bool {
+	return statusCode >= 300 && statusCode < 400
+}
+
+// HandleURL records a link from asset to the resolved url, storing the
+// target as a new asset if it hasn't been seen before
 func (c *Crawler) HandleURL(url string, asset *Asset, contentType ContentType) {
-	url = GetContextedURL(url, c.baseURL)
-	if url == "" {
+	c.HandleLink(url, asset, contentType, LinkAttributes{})
+}
+
+// HandleLink is HandleURL for links whose attributes are known
+func (c *Crawler) HandleLink(url string, asset *Asset, contentType ContentType, attrs LinkAttributes) {
+	url, external, err := ResolveURL(url, c.baseURL)
+	if err != nil || (external && !c.config.TrackExternal) {
 		return
 	}
 
-	newAsset := GenerateAsset(url, contentType)
-	c.StoreAsset(newAsset)
-	newLink := asset.AddLink(newAsset)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	newAsset, ok := c.Assets[url]
+
+	if !ok {
+		newAsset = GenerateAsset(url, contentType)
+		newAsset.Depth = asset.Depth + 1
+		newAsset.External = external
+
+		if external {
+			c.addAsset(newAsset)
+		} else {
+			c.storeAsset(newAsset)
+		}
+	}
+
+	newLink := asset.AddLinkWithAttributes(newAsset, attrs)
 
 	if newLink != nil {
 		c.Links = append(c.Links, newLink)
+		c.emitLink(newLink)
+	}
+}
+
+// OutputResults serializes a snapshot of the crawl graph to JSON
+func (c *Crawler) OutputResults() (string, error) {
+	snapshot := c.Snapshot()
+	snapshot.Analyze()
+
+	resp, err := json.Marshal(snapshot)
+
+	if err != nil {
+		return "", err
 	}
+
+	return string(resp), nil
 }
 
-func (c *Crawler) OutputResults() string {
-	resp, _ := json.Marshal(c)
-	return string(resp)
+func (c *Crawler) queueLength() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.toProcess.Length
 }
 
+// GetContextedURL resolves urlStr against baseUrl
+// Returns empty if the URL is invalid or points at a different host
 func GetContextedURL(urlStr string, baseUrl *url.URL) string {
+	resolved, external, err := ResolveURL(urlStr, baseUrl)
+
+	if err != nil || external {
+		return ""
+	}
+
+	return resolved
+}
+
+// ResolveURL resolves urlStr against baseUrl
+// Returns the absolute URL, with internationalized hosts in their ASCII
+// form and non-ASCII paths and queries percent-encoded, and whether it
+// points at a different host
+func ResolveURL(urlStr string, baseUrl *url.URL) (string, bool, error) {
 	url, err := url.Parse(urlStr)
 
 	if err != nil {
-		return ""
+		return "", false, err
 	}
 
 	if url.Host == "" {
@@ -200,11 +656,9 @@ func GetContextedURL(urlStr string, baseUrl *url.URL) string {
 		url.Scheme = baseUrl.Scheme
 	}
 
-	if url.Host != baseUrl.Host {
-		return ""
-	}
+	normalizeURL(url)
 
-	return url.String()
+	return url.String(), url.Host != normalizeHost(baseUrl.Host), nil
 }
 
 func GenerateRequest(method string, urlStr string, body io.Reader) (*http.Request, error) {
@@ -214,7 +668,7 @@ func GenerateRequest(method string, urlStr string, body io.Reader) (*http.Reques
 		return nil, err
 	}
 
-	req.Header.Add("User-Agent", "Crawler - kevin@kevo.io")
+	req.Header.Add("User-Agent", DefaultUserAgent)
 
 	return req, nil
 }
@@ -3,9 +3,11 @@ package crawler_test
 import (
 	"bytes"
 	"fmt"
+	"strings"
 
 	"github.com/PuerkitoBio/goquery"
 	. "github.com/kevinoconnor7/digitalocean-crawler/crawler"
+	"github.com/kevinoconnor7/digitalocean-crawler/extract"
 
 	"net/http"
 	"net/http/httptest"
@@ -108,6 +110,33 @@ var _ = Describe("Crawler", func() {
 		})
 	})
 
+	Describe("ResolveURL", func() {
+		var BaseURL *u.URL
+
+		BeforeEach(func() {
+			BaseURL, _ = u.Parse("https://foo.com")
+		})
+
+		It("Should resolve relative paths", func() {
+			url, external, err := ResolveURL("/home", BaseURL)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(url).To(Equal("https://foo.com/home"))
+			Expect(external).To(BeFalse())
+		})
+
+		It("Should flag other hosts as external", func() {
+			url, external, err := ResolveURL("//bar.com/test", BaseURL)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(url).To(Equal("https://bar.com/test"))
+			Expect(external).To(BeTrue())
+		})
+
+		It("Should error for invalid URLs", func() {
+			_, _, err := ResolveURL("http://[::1", BaseURL)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
 	Describe("Crawler", func() {
 
 		var (
@@ -156,6 +185,25 @@ var _ = Describe("Crawler", func() {
 					Expect(c.GetQueue().Length).To(Equal(0))
 				})
 
+				It("Should record the status code", func() {
+					c.StoreAsset(GenerateAsset(ts.URL, PAGE))
+					c.ProcessQueue()
+					Expect(c.Assets[ts.URL].StatusCode).To(Equal(http.StatusOK))
+				})
+
+				It("Should hash the response body", func() {
+					c.StoreAsset(GenerateAsset(ts.URL, PAGE))
+					c.ProcessQueue()
+					Expect(c.Assets[ts.URL].ContentHash).To(Equal(HashContent([]byte("test"))))
+				})
+
+				It("Should fingerprint the text of pages", func() {
+					c.StoreAsset(GenerateAsset(ts.URL, PAGE))
+					c.ProcessQueue()
+					Expect(c.Assets[ts.URL].TextHash).To(Equal(HashText("test")))
+					Expect(c.Assets[ts.URL].SimHash).To(Equal(ComputeSimHash("test")))
+				})
+
 				It("Should store an asset not in the map", func() {
 					c.GetQueue().Push(ts.URL)
 					err := c.ProcessQueue()
@@ -164,6 +212,36 @@ var _ = Describe("Crawler", func() {
 				})
 			})
 
+			Context("With a redirect", func() {
+				BeforeEach(func() {
+					ts.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+						if r.URL.Path == "/old" {
+							http.Redirect(w, r, "/new", http.StatusMovedPermanently)
+							return
+						}
+						fmt.Fprint(w, "test")
+					})
+				})
+
+				It("Should record the redirect instead of following it", func() {
+					c.StoreAsset(GenerateAsset(ts.URL+"/old", PAGE))
+					c.ProcessQueue()
+
+					old := c.Assets[ts.URL+"/old"]
+					Expect(old.StatusCode).To(Equal(http.StatusMovedPermanently))
+					Expect(old.RedirectTo).To(Equal(ts.URL + "/new"))
+				})
+
+				It("Should queue the redirect target", func() {
+					c.StoreAsset(GenerateAsset(ts.URL+"/old", PAGE))
+					c.ProcessQueue()
+
+					Expect(c.Assets).To(HaveKey(ts.URL + "/new"))
+					Expect(len(c.Links)).To(Equal(1))
+					Expect(c.GetQueue().Length).To(Equal(1))
+				})
+			})
+
 			AfterEach(func() {
 				ts.Close()
 			})
@@ -179,6 +257,38 @@ var _ = Describe("Crawler", func() {
 				c.StoreAsset(asset)
 			})
 
+			It("Should extract page metadata", func() {
+				doc, _ := goquery.NewDocumentFromReader(bytes.NewBufferString(`<title>Home</title><h1>Welcome</h1>`))
+				c.ProcessDoc(doc, asset)
+
+				Expect(asset.Metadata.Title).To(Equal("Home"))
+				Expect(asset.Metadata.Headings).To(HaveLen(1))
+			})
+
+			It("Should extract custom fields", func() {
+				url, _ := u.Parse("http://foo.faketld")
+				config := DefaultConfig()
+				config.Fields, _ = extract.LoadFields(strings.NewReader(`{"fields": {"price": ".price"}}`))
+				c = GenerateCrawlerWithConfig(url, config)
+
+				doc, _ := goquery.NewDocumentFromReader(bytes.NewBufferString(`<span class="price">$4</span>`))
+				c.ProcessDoc(doc, asset)
+
+				Expect(asset.Fields).To(Equal(map[string]string{"price": "$4"}))
+			})
+
+			It("Should not extract metadata when disabled", func() {
+				url, _ := u.Parse("http://foo.faketld")
+				config := DefaultConfig()
+				config.ExtractMetadata = false
+				c = GenerateCrawlerWithConfig(url, config)
+
+				doc, _ := goquery.NewDocumentFromReader(bytes.NewBufferString(`<title>Home</title>`))
+				c.ProcessDoc(doc, asset)
+
+				Expect(asset.Metadata).To(BeNil())
+			})
+
 			It("Should match images", func() {
 				buf := bytes.NewBuffer(nil)
 				buf.WriteString(`
@@ -284,6 +394,39 @@ var _ = Describe("Crawler", func() {
 					c.HandleURL("http://foo.faketld/subpage", asset, PAGE)
 					Expect(c.GetQueue().Length).To(Equal(2))
 				})
+
+				It("Should link to the stored asset", func() {
+					c.HandleURL("http://foo.faketld/subpage", asset, PAGE)
+					c.HandleURL("http://foo.faketld/subpage", c.Assets["http://foo.faketld/subpage"], PAGE)
+					c.HandleURL("http://foo.faketld/other", c.Assets["http://foo.faketld/subpage"], PAGE)
+					c.HandleURL("http://foo.faketld/subpage", c.Assets["http://foo.faketld/other"], PAGE)
+
+					Expect(c.Links[len(c.Links)-1].Target).To(Equal(1))
+				})
+
+				It("Should record the depth from the seed", func() {
+					c.HandleURL("http://foo.faketld/subpage", asset, PAGE)
+					c.HandleURL("http://foo.faketld/deeper", c.Assets["http://foo.faketld/subpage"], PAGE)
+
+					Expect(c.Assets["http://foo.faketld/subpage"].Depth).To(Equal(1))
+					Expect(c.Assets["http://foo.faketld/deeper"].Depth).To(Equal(2))
+				})
+			})
+
+			Context("When tracking external links", func() {
+				BeforeEach(func() {
+					url, _ := u.Parse("http://foo.faketld")
+					c = GenerateCrawlerWithConfig(url, Config{TrackExternal: true})
+					c.StoreAsset(asset)
+				})
+
+				It("Should store the asset without queueing it", func() {
+					c.HandleURL("http://bar.faketld/", asset, PAGE)
+					Expect(c.GetQueue().Length).To(Equal(1))
+					Expect(len(c.Assets)).To(Equal(2))
+					Expect(c.Assets["http://bar.faketld/"].External).To(BeTrue())
+					Expect(len(c.Links)).To(Equal(1))
+				})
 			})
 		})
 
@@ -369,6 +512,36 @@ var _ = Describe("Crawler", func() {
 			Expect(c.GetQueue().Length).To(Equal(0))
 		})
 
+		Context("With pages to audit", func() {
+			BeforeEach(func() {
+				ts.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					fmt.Fprint(w, `<title>Same title on every page</title><a href="/other">Other</a>`)
+				})
+			})
+
+			It("Should store findings on each page", func() {
+				c, err := Crawl(ts.URL, 5)
+				Expect(err).ToNot(HaveOccurred())
+
+				rules := make(map[string]bool)
+				for _, finding := range c.Assets[ts.URL+"/other"].Findings {
+					rules[finding.Rule] = true
+				}
+				Expect(rules).To(HaveKey("h1-missing"))
+				Expect(rules).To(HaveKey("title-duplicate"))
+			})
+
+			It("Should not audit when disabled", func() {
+				url, _ := u.Parse(ts.URL)
+				config := DefaultConfig()
+				config.Audit = false
+				c := GenerateCrawlerWithConfig(url, config)
+
+				Expect(c.Run(5)).To(Succeed())
+				Expect(c.Assets[ts.URL+"/other"].Findings).To(BeEmpty())
+			})
+		})
+
 		AfterEach(func() {
 			ts.Close()
 		})
@@ -0,0 +1,119 @@
+package crawler
+
+import "sort"
+
+const (
+	// DefaultSimHashDistance is the most bits two fingerprints may differ by
+	// for their pages to be considered near duplicates
+	DefaultSimHashDistance = 3
+
+	// simHashBands splits fingerprints into this many bands for candidate
+	// lookup, pages within the distance are guaranteed to share a band as
+	// long as it is smaller than the number of bands
+	simHashBands = DefaultSimHashDistance + 1
+	bandBits     = 64 / simHashBands
+)
+
+// DuplicateCluster is a group of pages with the same or nearly the same text
+type DuplicateCluster struct {
+	Exact   bool     `json:"exact"`
+	Indices []int    `json:"indices"`
+	URLs    []string `json:"urls"`
+}
+
+// FindDuplicates clusters the fetched pages of the snapshot whose text is
+// identical or whose SimHash differs by at most distance bits
+// Clusters are ordered by their first page
+func (s *Snapshot) FindDuplicates(distance int) []DuplicateCluster {
+	var pages []int
+	for i := range s.Nodes {
+		if s.Nodes[i].TextHash != "" {
+			pages = append(pages, i)
+		}
+	}
+
+	parent := make(map[int]int, len(pages))
+	for _, i := range pages {
+		parent[i] = i
+	}
+
+	var find func(i int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	union := func(a int, b int) {
+		a, b = find(a), find(b)
+		if a < b {
+			parent[b] = a
+		} else if b < a {
+			parent[a] = b
+		}
+	}
+
+	// Identical text is always a duplicate
+	byText := make(map[string]int)
+	for _, i := range pages {
+		if first, ok := byText[s.Nodes[i].TextHash]; ok {
+			union(first, i)
+		} else {
+			byText[s.Nodes[i].TextHash] = i
+		}
+	}
+
+	// Only compare pages sharing at least one band of their fingerprint,
+	// rather than every pair
+	for band := 0; band < simHashBands; band++ {
+		shift := uint(band * bandBits)
+		buckets := make(map[uint64][]int)
+
+		for _, i := range pages {
+			key := uint64(s.Nodes[i].SimHash>>shift) & (1<<bandBits - 1)
+			buckets[key] = append(buckets[key], i)
+		}
+
+		for _, bucket := range buckets {
+			for x := 0; x < len(bucket); x++ {
+				for y := x + 1; y < len(bucket); y++ {
+					a, b := bucket[x], bucket[y]
+					if s.Nodes[a].SimHash.Distance(s.Nodes[b].SimHash) <= distance {
+						union(a, b)
+					}
+				}
+			}
+		}
+	}
+
+	members := make(map[int][]int)
+	for _, i := range pages {
+		root := find(i)
+		members[root] = append(members[root], i)
+	}
+
+	clusters := []DuplicateCluster{}
+	for _, indices := range members {
+		if len(indices) < 2 {
+			continue
+		}
+
+		sort.Ints(indices)
+		cluster := DuplicateCluster{Exact: true, Indices: indices}
+		for _, i := range indices {
+			cluster.URLs = append(cluster.URLs, s.Nodes[i].URL)
+			if s.Nodes[i].TextHash != s.Nodes[indices[0]].TextHash {
+				cluster.Exact = false
+			}
+		}
+
+		clusters = append(clusters, cluster)
+	}
+
+	sort.Slice(clusters, func(i, j int) bool {
+		return clusters[i].Indices[0] < clusters[j].Indices[0]
+	})
+
+	return clusters
+}
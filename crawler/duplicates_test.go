@@ -0,0 +1,64 @@
+package crawler_test
+
+import (
+	. "github.com/kevinoconnor7/digitalocean-crawler/crawler"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Duplicates", func() {
+	page := func(url string, text string) Asset {
+		return Asset{
+			URL:      url,
+			Type:     PAGE,
+			TextHash: HashText(text),
+			SimHash:  ComputeSimHash(text),
+		}
+	}
+
+	var s *Snapshot
+
+	BeforeEach(func() {
+		s = &Snapshot{
+			Nodes: []Asset{
+				page("http://foo.faketld/", "Welcome to the home page of the site"),
+				page("http://foo.faketld/a", article),
+				page("http://foo.faketld/b", "Lorem ipsum dolor sit amet consectetur adipiscing elit"),
+				page("http://foo.faketld/a?page=2", article+" page two"),
+				page("http://foo.faketld/c", "Lorem ipsum dolor sit amet consectetur adipiscing elit"),
+				{URL: "http://foo.faketld/unfetched", Type: PAGE},
+				{URL: "http://foo.faketld/unfetched2", Type: PAGE},
+			},
+		}
+	})
+
+	It("Should cluster identical text as exact duplicates", func() {
+		clusters := s.FindDuplicates(DefaultSimHashDistance)
+
+		Expect(clusters).To(ContainElement(DuplicateCluster{
+			Exact:   true,
+			Indices: []int{2, 4},
+			URLs:    []string{"http://foo.faketld/b", "http://foo.faketld/c"},
+		}))
+	})
+
+	It("Should cluster near duplicates", func() {
+		clusters := s.FindDuplicates(DefaultSimHashDistance)
+
+		Expect(clusters[0]).To(Equal(DuplicateCluster{
+			Exact:   false,
+			Indices: []int{1, 3},
+			URLs:    []string{"http://foo.faketld/a", "http://foo.faketld/a?page=2"},
+		}))
+	})
+
+	It("Should ignore unique and unfetched pages", func() {
+		Expect(len(s.FindDuplicates(DefaultSimHashDistance))).To(Equal(2))
+	})
+
+	It("Should be attached by Analyze", func() {
+		s.Analyze()
+		Expect(len(s.Duplicates)).To(Equal(2))
+	})
+})
@@ -0,0 +1,112 @@
+package crawler
+
+import "encoding/json"
+
+// EventType defines an enum for the kinds of crawl events
+type EventType int
+
+const (
+	ASSET_DISCOVERED EventType = 1 + iota
+	ASSET_FETCHED
+	LINK_ADDED
+)
+
+// eventBuffer is how many events a subscriber may fall behind before it's
+// unsubscribed
+const eventBuffer = 256
+
+var eventTypeNames = map[EventType]string{
+	ASSET_DISCOVERED: "asset-discovered",
+	ASSET_FETCHED:    "asset-fetched",
+	LINK_ADDED:       "link-added",
+}
+
+func (t EventType) String() string {
+	return eventTypeNames[t]
+}
+
+// MarshalJSON encodes the EventType by name
+func (t EventType) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.String())
+}
+
+// Event describes a change to the crawl graph
+// Asset and Link are copies and are safe to read after the event is sent
+type Event struct {
+	Type  EventType `json:"type"`
+	Index int       `json:"index"`
+	Asset *Asset    `json:"asset,omitempty"`
+	Link  *Link     `json:"link,omitempty"`
+}
+
+// Subscribe returns a channel receiving every subsequent crawl event
+// Subscribers that fall too far behind are unsubscribed, closing the
+// channel, rather than silently missing events, and must take a new
+// Snapshot to catch up
+func (c *Crawler) Subscribe() chan Event {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ch := make(chan Event, eventBuffer)
+	c.subscribers = append(c.subscribers, ch)
+
+	return ch
+}
+
+// Unsubscribe stops delivery of events to the channel and closes it
+func (c *Crawler) Unsubscribe(ch chan Event) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i, sub := range c.subscribers {
+		if sub == ch {
+			c.subscribers = append(c.subscribers[:i], c.subscribers[i+1:]...)
+			close(ch)
+			return
+		}
+	}
+}
+
+// emitAsset must be called with c.mu held
+func (c *Crawler) emitAsset(eventType EventType, asset *Asset) {
+	copied := *asset
+	copied.Links = nil
+
+	c.emit(Event{
+		Type:  eventType,
+		Index: asset.index,
+		Asset: &copied,
+	})
+}
+
+// emitLink must be called with c.mu held
+func (c *Crawler) emitLink(link *Link) {
+	copied := *link
+
+	c.emit(Event{
+		Type:  LINK_ADDED,
+		Index: len(c.Links) - 1,
+		Link:  &copied,
+	})
+}
+
+// emit must be called with c.mu held
+func (c *Crawler) emit(event Event) {
+	subscribers := c.subscribers[:0]
+
+	for _, ch := range c.subscribers {
+		select {
+		case ch <- event:
+			subscribers = append(subscribers, ch)
+		default:
+			close(ch)
+		}
+	}
+
+	// Clear the slots of dropped subscribers left past the end
+	for i := len(subscribers); i < len(c.subscribers); i++ {
+		c.subscribers[i] = nil
+	}
+
+	c.subscribers = subscribers
+}
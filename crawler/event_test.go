@@ -0,0 +1,77 @@
+package crawler_test
+
+import (
+	"fmt"
+
+	. "github.com/kevinoconnor7/digitalocean-crawler/crawler"
+
+	u "net/url"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Event", func() {
+	var (
+		c      *Crawler
+		asset  *Asset
+		events chan Event
+	)
+
+	BeforeEach(func() {
+		url, _ := u.Parse("http://foo.faketld")
+		c = GenerateCrawler(url)
+		events = c.Subscribe()
+		asset = GenerateAsset("http://foo.faketld", PAGE)
+		c.StoreAsset(asset)
+	})
+
+	It("Should emit when an asset is discovered", func() {
+		event := <-events
+		Expect(event.Type).To(Equal(ASSET_DISCOVERED))
+		Expect(event.Index).To(Equal(0))
+		Expect(event.Asset.URL).To(Equal("http://foo.faketld"))
+	})
+
+	It("Should emit when a link is added", func() {
+		<-events
+		c.HandleURL("/subpage", asset, PAGE)
+
+		Expect((<-events).Type).To(Equal(ASSET_DISCOVERED))
+
+		event := <-events
+		Expect(event.Type).To(Equal(LINK_ADDED))
+		Expect(event.Link.Source).To(Equal(0))
+		Expect(event.Link.Target).To(Equal(1))
+	})
+
+	It("Should name event types", func() {
+		Expect(ASSET_DISCOVERED.String()).To(Equal("asset-discovered"))
+		Expect(ASSET_FETCHED.String()).To(Equal("asset-fetched"))
+		Expect(LINK_ADDED.String()).To(Equal("link-added"))
+	})
+
+	It("Should unsubscribe subscribers that fall behind", func() {
+		for i := 0; i < 300; i++ {
+			c.HandleURL(fmt.Sprintf("/page-%d", i), asset, PAGE)
+		}
+
+		received := 0
+		for range events {
+			received++
+		}
+		Expect(received).To(BeNumerically("<", 600))
+
+		// Unsubscribing again is harmless
+		c.Unsubscribe(events)
+	})
+
+	Describe("Unsubscribe", func() {
+		It("Should close the channel", func() {
+			c.Unsubscribe(events)
+			<-events
+			_, ok := <-events
+			Expect(ok).To(BeFalse())
+		})
+	})
+})
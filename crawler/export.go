@@ -0,0 +1,25 @@
+package crawler
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// LoadSnapshot reads a crawl previously written by OutputResults
+func LoadSnapshot(r io.Reader) (*Snapshot, error) {
+	var s Snapshot
+
+	err := json.NewDecoder(r).Decode(&s)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, link := range s.Links {
+		if link.Source < 0 || link.Source >= len(s.Nodes) || link.Target < 0 || link.Target >= len(s.Nodes) {
+			return nil, fmt.Errorf("Link %d -> %d refers to a missing asset", link.Source, link.Target)
+		}
+	}
+
+	return &s, nil
+}
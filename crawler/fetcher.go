@@ -0,0 +1,425 @@
+package crawler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/kevinoconnor7/digitalocean-crawler/mirror"
+	"github.com/kevinoconnor7/digitalocean-crawler/warc"
+)
+
+// Response is a fetched response with its body already read
+type Response struct {
+	Proto      string
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+
+	// RecordID is the WARC record the response is archived in, if any
+	RecordID string
+
+	// Truncated is set if the body was cut off at the maximum size
+	Truncated bool
+
+	// Encoding is the Content-Encoding the body was decoded from, if any
+	Encoding string
+
+	// DecodeError is set if the body couldn't be decoded from Encoding, in
+	// which case it's kept as received
+	DecodeError string
+
+	// TransferSize is the number of bytes received for the body, before
+	// decoding, or zero if it wasn't transferred
+	TransferSize int64
+
+	// Head is set for responses to HEAD requests, which have no body
+	Head bool
+
+	// rawHeader, rawBody and rawTruncated describe the response as it was
+	// received, before its body was decoded, for archiving
+	rawHeader    http.Header
+	rawBody      []byte
+	rawTruncated bool
+}
+
+// Fetcher retrieves the response to a request
+type Fetcher interface {
+	Fetch(req *http.Request) (*Response, error)
+}
+
+// HTTPFetcher fetches responses over the network
+type HTTPFetcher struct {
+	Client *http.Client
+
+	// MaxBodySize is the number of bytes of each body read, if positive
+	// The rest is discarded and the response marked as truncated
+	MaxBodySize int64
+}
+
+// maxBodySizeKey is the context key of a request's own MaxBodySize
+type maxBodySizeKey struct{}
+
+// WithMaxBodySize returns a copy of req whose body the HTTPFetcher reads up
+// to size bytes, if that's more than its MaxBodySize
+func WithMaxBodySize(req *http.Request, size int64) *http.Request {
+	return req.WithContext(context.WithValue(req.Context(), maxBodySizeKey{}, size))
+}
+
+// GenerateHTTPFetcher is a factory for an HTTPFetcher using client
+func GenerateHTTPFetcher(client *http.Client) *HTTPFetcher {
+	return &HTTPFetcher{Client: client}
+}
+
+// Fetch sends the request and reads the whole response, decoding any
+// compressed content itself so that the transferred size is known
+func (f *HTTPFetcher) Fetch(req *http.Request) (*Response, error) {
+	if req.Header.Get("Accept-Encoding") == "" {
+		// Setting this also stops the transport decoding gzip by itself
+		req.Header.Set("Accept-Encoding", AcceptEncoding)
+	}
+
+	resp, err := f.Client.Do(req)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	maxBodySize := requestMaxBodySize(req, f.MaxBodySize)
+
+	var reader io.Reader = resp.Body
+	if maxBodySize > 0 {
+		// Reading a byte past the limit tells whether there was more
+		reader = io.LimitReader(reader, maxBodySize+1)
+	}
+
+	raw, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	truncated := maxBodySize > 0 && int64(len(raw)) > maxBodySize
+	if truncated {
+		raw = raw[:maxBodySize]
+	}
+
+	response := &Response{
+		Proto:      resp.Proto,
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		Body:       raw,
+		Truncated:  truncated,
+	}
+	response.decode(req.Method, maxBodySize)
+
+	return response, nil
+}
+
+// requestMaxBodySize returns the MaxBodySize a request was given, if it's
+// more than maxBodySize
+func requestMaxBodySize(req *http.Request, maxBodySize int64) int64 {
+	if size, ok := req.Context().Value(maxBodySizeKey{}).(int64); ok && maxBodySize > 0 && size > maxBodySize {
+		return size
+	}
+
+	return maxBodySize
+}
+
+// decode undoes the content codings of a body as it was received, reading
+// up to maxBodySize decoded bytes if it's positive, and keeps the response
+// as it was received for archiving
+// Bodies in codings that can't be decoded, or that fail to decode, are kept
+// as they are
+func (r *Response) decode(method string, maxBodySize int64) {
+	r.rawHeader, r.rawBody, r.rawTruncated = r.Header, r.Body, r.Truncated
+	r.Encoding = r.Header.Get("Content-Encoding")
+	r.TransferSize = int64(len(r.Body))
+
+	decoded := false
+	if r.Encoding != "" && canDecode(r.Encoding) && method != http.MethodHead {
+		body, cut, err := decodeBody(r.Body, r.Encoding, maxBodySize, r.Truncated)
+		if err != nil {
+			r.DecodeError = err.Error()
+		} else {
+			r.Body, decoded = body, true
+			r.Truncated = r.Truncated || cut
+		}
+	}
+
+	// As with the transport's own decoding, the headers describe the body
+	// as it's returned
+	r.Header = r.Header.Clone()
+	if decoded {
+		r.Header.Del("Content-Encoding")
+	}
+	if decoded || r.Truncated {
+		r.Header.Del("Content-Length")
+	}
+}
+
+// FixtureFetcher serves responses from files laid out as by mirror.LocalPath,
+// so a saved mirror can be crawled again without the network
+// URLs without a file are not found
+type FixtureFetcher struct {
+	Dir string
+}
+
+// GenerateFixtureFetcher is a factory for a FixtureFetcher serving dir
+func GenerateFixtureFetcher(dir string) *FixtureFetcher {
+	return &FixtureFetcher{Dir: dir}
+}
+
+// Fetch reads the file the request's URL maps to
+func (f *FixtureFetcher) Fetch(req *http.Request) (*Response, error) {
+	local, err := mirror.LocalPath(req.URL.String())
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := os.ReadFile(filepath.Join(f.Dir, filepath.FromSlash(local)))
+	if os.IsNotExist(err) {
+		return &Response{
+			StatusCode: http.StatusNotFound,
+			Header:     http.Header{"Content-Type": {"text/plain; charset=utf-8"}},
+			Body:       []byte(http.StatusText(http.StatusNotFound)),
+		}, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	contentType := mime.TypeByExtension(path.Ext(local))
+	if contentType == "" {
+		contentType = http.DetectContentType(body)
+	}
+
+	return &Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": {contentType}},
+		Body:       body,
+	}, nil
+}
+
+// RecordingFetcher archives every response fetched by another Fetcher
+// Responses to HEAD requests have no body to replay, so aren't archived
+type RecordingFetcher struct {
+	Fetcher Fetcher
+	Writer  *warc.Writer
+}
+
+// Fetch fetches and archives the response, recording its WARC record ID
+func (f *RecordingFetcher) Fetch(req *http.Request) (*Response, error) {
+	resp, err := f.Fetcher.Fetch(req)
+	if err != nil || req.Method == http.MethodHead {
+		return resp, err
+	}
+
+	// Responses are archived as they were received where the fetcher kept
+	// them, so encoded bodies stay encoded
+	archived := &http.Response{Proto: resp.Proto, StatusCode: resp.StatusCode, Header: resp.Header}
+	body, cut := resp.Body, resp.Truncated
+	if resp.rawHeader != nil {
+		archived.Header, body, cut = resp.rawHeader, resp.rawBody, resp.rawTruncated
+	}
+
+	truncated := ""
+	if cut {
+		truncated = warc.TruncatedLength
+	}
+
+	resp.RecordID, err = f.Writer.WriteTruncatedExchange(req, archived, body, truncated)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// ReplayFetcher serves the responses archived in WARC files, reading each
+// from its archive when it's fetched
+// Only the first capture of each URL is served
+type ReplayFetcher struct {
+	// MaxBodySize is the number of decoded bytes of each body read, if
+	// positive, as with the HTTPFetcher
+	MaxBodySize int64
+
+	archives []io.ReaderAt
+	records  map[string]archivedRecord
+	order    []string
+}
+
+// archivedRecord locates a response record in one of the archives
+type archivedRecord struct {
+	archive int
+	offset  warc.Offset
+}
+
+// LoadReplayFetcher indexes every response in the archives into a
+// ReplayFetcher, which reads from them until it's no longer used
+func LoadReplayFetcher(archives ...io.ReaderAt) (*ReplayFetcher, error) {
+	f := &ReplayFetcher{archives: archives, records: make(map[string]archivedRecord)}
+
+	for i, archive := range archives {
+		reader, err := warc.GenerateReader(io.NewSectionReader(archive, 0, math.MaxInt64))
+		if err != nil {
+			return nil, err
+		}
+
+		for {
+			record, err := reader.Next()
+			if err == io.EOF {
+				break
+			}
+
+			if err != nil {
+				return nil, err
+			}
+
+			url := record.TargetURI()
+			if _, ok := f.records[url]; record.Type() != warc.RESPONSE || ok {
+				continue
+			}
+
+			f.records[url] = archivedRecord{archive: i, offset: reader.Offset()}
+			f.order = append(f.order, url)
+		}
+	}
+
+	return f, nil
+}
+
+// URLs lists every archived URL in the order it was captured
+func (f *ReplayFetcher) URLs() []string {
+	return f.order
+}
+
+// Fetch returns the archived response for the request's URL, decoding its
+// body as the HTTPFetcher would have
+func (f *ReplayFetcher) Fetch(req *http.Request) (*Response, error) {
+	archived, ok := f.records[req.URL.String()]
+	if !ok {
+		return nil, fmt.Errorf("No archived response for %s", req.URL)
+	}
+
+	record, err := warc.ReadRecordAt(f.archives[archived.archive], archived.offset)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := record.Response()
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	// Bodies archived shorter than their Content-Length were cut off
+	body, err := io.ReadAll(resp.Body)
+	truncated := record.Truncated() != ""
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		truncated, err = true, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	response := &Response{
+		Proto:      resp.Proto,
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		Body:       body,
+		RecordID:   record.ID(),
+		Truncated:  truncated,
+	}
+	response.decode(req.Method, requestMaxBodySize(req, f.MaxBodySize))
+
+	return response, nil
+}
+
+// fetch GETs urlStr with the crawler's fetcher, logging in again and
+// retrying once if the session has expired
+func (c *Crawler) fetch(urlStr string) (*Response, error) {
+	return c.fetchMethod(http.MethodGet, urlStr, 0)
+}
+
+// fetchMethod is fetch for requests with any method, raising the fetcher's
+// MaxBodySize to maxBodySize if it's positive
+func (c *Crawler) fetchMethod(method string, urlStr string, maxBodySize int64) (*Response, error) {
+	req, err := c.newFetchRequest(method, urlStr, maxBodySize)
+
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.fetcher.Fetch(req)
+	if err != nil || !c.loggedOut(req.URL, resp) {
+		return resp, err
+	}
+
+	err = c.login()
+	if err != nil {
+		return nil, err
+	}
+
+	req, err = c.newFetchRequest(method, urlStr, maxBodySize)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.fetcher.Fetch(req)
+}
+
+// newFetchRequest generates a conditional request for urlStr
+func (c *Crawler) newFetchRequest(method string, urlStr string, maxBodySize int64) (*http.Request, error) {
+	req, err := c.newRequest(method, urlStr, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	c.conditional(req, urlStr)
+
+	if maxBodySize > 0 {
+		req = WithMaxBodySize(req, maxBodySize)
+	}
+
+	return req, nil
+}
+
+// fetchAsset fetches an asset, only requesting the headers of subresources
+// when HeadAssets is set
+// Servers that don't say how large a subresource is are asked for it
+func (c *Crawler) fetchAsset(asset *Asset) (*Response, error) {
+	if !c.config.HeadAssets || asset.Type == PAGE {
+		return c.fetch(asset.URL)
+	}
+
+	resp, err := c.fetchMethod(http.MethodHead, asset.URL, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	// Fetchers without the network may answer with the body anyway
+	if len(resp.Body) > 0 {
+		return resp, nil
+	}
+
+	sized := resp.Header.Get("Content-Length") != ""
+	if resp.StatusCode == http.StatusMethodNotAllowed || resp.StatusCode == http.StatusNotImplemented ||
+		(resp.StatusCode >= 200 && resp.StatusCode < 300 && !sized) {
+		return c.fetch(asset.URL)
+	}
+
+	resp.Head = true
+	return resp, nil
+}
@@ -0,0 +1,147 @@
+package crawler_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	u "net/url"
+	"os"
+	"path/filepath"
+
+	. "github.com/kevinoconnor7/digitalocean-crawler/crawler"
+	"github.com/kevinoconnor7/digitalocean-crawler/warc"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Fetcher", func() {
+	var dir string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = os.MkdirTemp("", "fetcher")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	fixture := func(local string, content string) {
+		file := filepath.Join(dir, filepath.FromSlash(local))
+		Expect(os.MkdirAll(filepath.Dir(file), 0755)).To(Succeed())
+		Expect(os.WriteFile(file, []byte(content), 0644)).To(Succeed())
+	}
+
+	request := func(url string) *http.Request {
+		req, err := GenerateRequest("GET", url, nil)
+		Expect(err).ToNot(HaveOccurred())
+		return req
+	}
+
+	Describe("HTTPFetcher", func() {
+		It("Should read the whole response", func() {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("X-Test", "yes")
+				w.WriteHeader(http.StatusTeapot)
+				fmt.Fprint(w, "body")
+			}))
+			defer ts.Close()
+
+			resp, err := GenerateHTTPFetcher(http.DefaultClient).Fetch(request(ts.URL))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusTeapot))
+			Expect(resp.Header.Get("X-Test")).To(Equal("yes"))
+			Expect(string(resp.Body)).To(Equal("body"))
+		})
+	})
+
+	Describe("FixtureFetcher", func() {
+		It("Should serve files by URL path", func() {
+			fixture("about/index.html", "<p>About</p>")
+			fixture("css/site.css", "body {}")
+
+			f := GenerateFixtureFetcher(dir)
+
+			resp, err := f.Fetch(request("http://example.com/about"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			Expect(resp.Header.Get("Content-Type")).To(HavePrefix("text/html"))
+			Expect(string(resp.Body)).To(Equal("<p>About</p>"))
+
+			resp, err = f.Fetch(request("http://example.com/css/site.css"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.Header.Get("Content-Type")).To(HavePrefix("text/css"))
+		})
+
+		It("Should not find missing files", func() {
+			resp, err := GenerateFixtureFetcher(dir).Fetch(request("http://example.com/missing"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusNotFound))
+		})
+
+		It("Should crawl without the network", func() {
+			fixture("index.html", `<a href="/about">About</a><a href="/missing">Missing</a><img src="/logo.png">`)
+			fixture("about/index.html", `<a href="/">Home</a>`)
+			fixture("logo.png", "\x89PNG")
+
+			url, _ := u.Parse("http://example.com/")
+			config := DefaultConfig()
+			config.Fetcher = GenerateFixtureFetcher(dir)
+
+			c := GenerateCrawlerWithConfig(url, config)
+			Expect(c.Run(10)).To(Succeed())
+
+			Expect(c.Assets["http://example.com/about"].StatusCode).To(Equal(http.StatusOK))
+			Expect(c.Assets["http://example.com/missing"].StatusCode).To(Equal(http.StatusNotFound))
+			Expect(c.Assets["http://example.com/logo.png"].StatusCode).To(Equal(http.StatusOK))
+			Expect(c.Links).To(HaveLen(4))
+		})
+	})
+
+	Describe("Recording and replaying", func() {
+		It("Should replay recorded responses", func() {
+			fixture("index.html", `<a href="/about">About</a>`)
+			fixture("about/index.html", `<a href="/">Home</a>`)
+
+			writer := warc.GenerateWriter(dir, "test", 0)
+			recorder := &RecordingFetcher{Fetcher: GenerateFixtureFetcher(dir), Writer: writer}
+
+			url, _ := u.Parse("http://example.com/")
+			config := DefaultConfig()
+			config.Fetcher = recorder
+
+			recorded := GenerateCrawlerWithConfig(url, config)
+			Expect(recorded.Run(10)).To(Succeed())
+			Expect(writer.Close()).To(Succeed())
+
+			file, err := os.Open(writer.Files()[0])
+			Expect(err).ToNot(HaveOccurred())
+			defer file.Close()
+
+			replayer, err := LoadReplayFetcher(file)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(replayer.URLs()).To(Equal([]string{"http://example.com/", "http://example.com/about"}))
+
+			config.Fetcher = replayer
+			replayed := GenerateCrawlerWithConfig(url, config)
+			Expect(replayed.Run(10)).To(Succeed())
+
+			Expect(len(replayed.AssetsArray)).To(Equal(len(recorded.AssetsArray)))
+			for _, asset := range recorded.AssetsArray {
+				Expect(asset.WARCRecordID).ToNot(BeEmpty())
+				Expect(replayed.Assets[asset.URL].ContentHash).To(Equal(asset.ContentHash))
+				Expect(replayed.Assets[asset.URL].WARCRecordID).To(Equal(asset.WARCRecordID))
+			}
+		})
+
+		It("Should error for responses that weren't recorded", func() {
+			replayer, err := LoadReplayFetcher()
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = replayer.Fetch(request("http://example.com/"))
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
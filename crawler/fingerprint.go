@@ -0,0 +1,110 @@
+package crawler
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/bits"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// shingleSize is the number of consecutive words hashed together by SimHash
+const shingleSize = 3
+
+// nonContentSelector matches elements whose text isn't part of a page's
+// main content
+const nonContentSelector = "script, style, noscript, template, " + boilerplateSelector
+
+// SimHash is a locality sensitive fingerprint of a page's text, similar
+// pages have fingerprints that differ in few bits
+type SimHash uint64
+
+// Distance counts the bits that differ between two fingerprints
+func (h SimHash) Distance(other SimHash) int {
+	return bits.OnesCount64(uint64(h ^ other))
+}
+
+// MarshalText encodes the fingerprint as hex, since JSON numbers can't
+// hold every uint64
+func (h SimHash) MarshalText() ([]byte, error) {
+	return []byte(fmt.Sprintf("%016x", uint64(h))), nil
+}
+
+// UnmarshalText decodes a fingerprint written by MarshalText
+func (h *SimHash) UnmarshalText(text []byte) error {
+	value, err := strconv.ParseUint(string(text), 16, 64)
+	if err != nil {
+		return fmt.Errorf("Invalid SimHash %q", text)
+	}
+
+	*h = SimHash(value)
+
+	return nil
+}
+
+// MainText extracts the normalized text of a document's content, leaving
+// out scripts, styles and boilerplate such as navigation and footers
+// The document itself isn't modified
+func MainText(doc *goquery.Document) string {
+	body := doc.Find("body").Clone()
+	body.Find(nonContentSelector).Remove()
+
+	return strings.Join(words(body.Text()), " ")
+}
+
+// HashText returns the hex encoded SHA-256 of normalized text
+// Empty text has no hash
+func HashText(text string) string {
+	if text == "" {
+		return ""
+	}
+
+	return HashContent([]byte(text))
+}
+
+// ComputeSimHash fingerprints text from its overlapping word shingles
+func ComputeSimHash(text string) SimHash {
+	tokens := words(text)
+	if len(tokens) == 0 {
+		return 0
+	}
+
+	var weights [64]int
+	for i := 0; i+shingleSize <= len(tokens) || i == 0; i++ {
+		end := i + shingleSize
+		if end > len(tokens) {
+			end = len(tokens)
+		}
+
+		h := fnv.New64a()
+		h.Write([]byte(strings.Join(tokens[i:end], " ")))
+		sum := h.Sum64()
+
+		for bit := range weights {
+			if sum&(1<<uint(bit)) != 0 {
+				weights[bit]++
+			} else {
+				weights[bit]--
+			}
+		}
+	}
+
+	var fingerprint SimHash
+	for bit, weight := range weights {
+		if weight > 0 {
+			fingerprint |= 1 << uint(bit)
+		}
+	}
+
+	return fingerprint
+}
+
+// words lowercases text and splits it on anything but letters and numbers
+func words(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
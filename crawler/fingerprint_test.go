@@ -0,0 +1,88 @@
+package crawler_test
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/PuerkitoBio/goquery"
+	. "github.com/kevinoconnor7/digitalocean-crawler/crawler"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// article is long enough that small edits barely change its SimHash
+var article = "The quick brown fox jumps over the lazy dog while the farmer " +
+	"watches from the porch and the cows graze in the far field beyond " +
+	"the old red barn that his grandfather built many years ago. Every " +
+	"morning he walks down to the creek to check on the water level, " +
+	"because the summer has been dry and the well is running low. His " +
+	"neighbours have offered to share their pond, but he is too proud to " +
+	"accept and would rather haul buckets up the hill himself. In the " +
+	"evenings the family gathers on the porch to watch the sun set behind " +
+	"the mountains, telling stories about the harvests of years gone by " +
+	"and the storms that nearly took the roof off the house."
+
+var _ = Describe("Fingerprint", func() {
+	parse := func(html string) *goquery.Document {
+		doc, _ := goquery.NewDocumentFromReader(bytes.NewBufferString(html))
+		return doc
+	}
+
+	Describe("MainText", func() {
+		It("Should normalize case, punctuation and whitespace", func() {
+			doc := parse("<p>Hello,   World!</p>\n<p>Again.</p>")
+			Expect(MainText(doc)).To(Equal("hello world again"))
+		})
+
+		It("Should leave out scripts and boilerplate", func() {
+			doc := parse(`<nav>Home</nav><script>var x;</script><p>Content</p><footer>Copyright</footer>`)
+			Expect(MainText(doc)).To(Equal("content"))
+		})
+
+		It("Should not modify the document", func() {
+			doc := parse(`<nav><a href="/">Home</a></nav>`)
+			MainText(doc)
+			Expect(doc.Find("nav a").Length()).To(Equal(1))
+		})
+	})
+
+	Describe("HashText", func() {
+		It("Should not hash empty text", func() {
+			Expect(HashText("")).To(Equal(""))
+		})
+
+		It("Should hash equal text equally", func() {
+			Expect(HashText("foo")).To(Equal(HashText("foo")))
+			Expect(HashText("foo")).ToNot(Equal(HashText("bar")))
+		})
+	})
+
+	Describe("ComputeSimHash", func() {
+		It("Should give similar text a close fingerprint", func() {
+			a := ComputeSimHash(article)
+			b := ComputeSimHash(article + " page two")
+			Expect(a.Distance(b)).To(BeNumerically("<=", DefaultSimHashDistance))
+		})
+
+		It("Should give different text a distant fingerprint", func() {
+			a := ComputeSimHash(article)
+			b := ComputeSimHash("Lorem ipsum dolor sit amet consectetur adipiscing elit sed do eiusmod tempor")
+			Expect(a.Distance(b)).To(BeNumerically(">", DefaultSimHashDistance))
+		})
+
+		It("Should fingerprint text shorter than a shingle", func() {
+			Expect(ComputeSimHash("hello")).ToNot(BeZero())
+			Expect(ComputeSimHash("")).To(BeZero())
+		})
+
+		It("Should encode as hex in JSON", func() {
+			out, _ := json.Marshal(SimHash(255))
+			Expect(string(out)).To(Equal(`"00000000000000ff"`))
+
+			var h SimHash
+			Expect(json.Unmarshal(out, &h)).To(Succeed())
+			Expect(h).To(Equal(SimHash(255)))
+		})
+	})
+})
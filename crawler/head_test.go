@@ -0,0 +1,108 @@
+package crawler_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	u "net/url"
+	"strings"
+	"sync"
+
+	. "github.com/kevinoconnor7/digitalocean-crawler/crawler"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("HeadAssets", func() {
+	var (
+		ts      *httptest.Server
+		mu      sync.Mutex
+		methods map[string]string
+	)
+
+	method := func(path string) string {
+		mu.Lock()
+		defer mu.Unlock()
+		return methods[path]
+	}
+
+	BeforeEach(func() {
+		methods = make(map[string]string)
+
+		ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			methods[r.URL.Path] = r.Method
+			mu.Unlock()
+
+			switch r.URL.Path {
+			case "/":
+				w.Write([]byte(`<link rel="stylesheet" href="/site.css"><script src="/app.js"></script><img src="/logo.png"><img src="/old.png">`))
+			case "/site.css":
+				w.Header().Set("Content-Type", "text/css")
+				w.Header().Set("Content-Length", "1234")
+				w.Write([]byte(strings.Repeat("a", 1234)))
+			case "/app.js":
+				// Streamed without a length
+				w.Header().Set("Content-Type", "application/javascript")
+				w.(http.Flusher).Flush()
+				w.Write([]byte("alert(1)"))
+			case "/logo.png":
+				if r.Method == http.MethodHead {
+					w.WriteHeader(http.StatusMethodNotAllowed)
+					return
+				}
+				w.Write([]byte("png"))
+			case "/old.png":
+				http.Redirect(w, r, "/logo.png", http.StatusMovedPermanently)
+			}
+		}))
+	})
+
+	AfterEach(func() {
+		ts.Close()
+	})
+
+	crawl := func(head bool) *Crawler {
+		url, _ := u.Parse(ts.URL + "/")
+		config := DefaultConfig()
+		config.HeadAssets = head
+		c := GenerateCrawlerWithConfig(url, config)
+		Expect(c.Run(10)).To(Succeed())
+		return c
+	}
+
+	It("Should size subresources from their headers", func() {
+		c := crawl(true)
+		Expect(method("/")).To(Equal(http.MethodGet))
+		Expect(method("/site.css")).To(Equal(http.MethodHead))
+
+		css := c.Assets[ts.URL+"/site.css"]
+		Expect(css.StatusCode).To(Equal(http.StatusOK))
+		Expect(css.Size).To(Equal(int64(1234)))
+		Expect(css.TransferSize).To(Equal(int64(1234)))
+		Expect(css.ContentType).To(Equal("text/css"))
+		Expect(css.ContentHash).To(BeEmpty())
+	})
+
+	It("Should download subresources without a length", func() {
+		c := crawl(true)
+		Expect(method("/app.js")).To(Equal(http.MethodGet))
+		Expect(c.Assets[ts.URL+"/app.js"].Size).To(Equal(int64(8)))
+	})
+
+	It("Should download subresources HEAD isn't allowed for", func() {
+		c := crawl(true)
+		Expect(c.Assets[ts.URL+"/logo.png"].StatusCode).To(Equal(http.StatusOK))
+		Expect(c.Assets[ts.URL+"/logo.png"].Size).To(Equal(int64(3)))
+	})
+
+	It("Should follow redirects", func() {
+		c := crawl(true)
+		Expect(c.Assets[ts.URL+"/old.png"].RedirectTo).To(Equal(ts.URL + "/logo.png"))
+	})
+
+	It("Should download subresources by default", func() {
+		crawl(false)
+		Expect(method("/site.css")).To(Equal(http.MethodGet))
+	})
+})
@@ -0,0 +1,112 @@
+package crawler
+
+import "net/http"
+
+// previousAsset is an asset from an earlier crawl with the links found on it
+type previousAsset struct {
+	asset Asset
+	links []previousLink
+}
+
+type previousLink struct {
+	url         string
+	contentType ContentType
+	link        Link
+}
+
+// indexPrevious maps the assets of an earlier crawl by URL
+func indexPrevious(s *Snapshot) map[string]*previousAsset {
+	previous := make(map[string]*previousAsset, len(s.Nodes))
+
+	for _, node := range s.Nodes {
+		previous[node.URL] = &previousAsset{asset: node}
+	}
+
+	for _, link := range s.Links {
+		if link.Source >= len(s.Nodes) || link.Target >= len(s.Nodes) {
+			continue
+		}
+
+		source := previous[s.Nodes[link.Source].URL]
+		target := s.Nodes[link.Target]
+		source.links = append(source.links, previousLink{
+			url:         target.URL,
+			contentType: target.Type,
+			link:        link,
+		})
+	}
+
+	return previous
+}
+
+// conditional asks the server to only send the asset at urlStr if it has
+// changed since the previous crawl
+func (c *Crawler) conditional(req *http.Request, urlStr string) {
+	previous, ok := c.previous[urlStr]
+	if !ok || previous.asset.StatusCode < 200 || previous.asset.StatusCode >= 300 {
+		return
+	}
+
+	if previous.asset.ETag != "" {
+		req.Header.Set("If-None-Match", previous.asset.ETag)
+	}
+
+	if previous.asset.LastModified != "" {
+		req.Header.Set("If-Modified-Since", previous.asset.LastModified)
+	}
+}
+
+// reusePrevious fills in an asset the server reports as unchanged from
+// the previous crawl, following the links stored for it instead of
+// downloading and parsing it again
+// Audit findings for the page alone are carried over, and site rules judge
+// it by the values it had
+func (c *Crawler) reusePrevious(asset *Asset, previous *previousAsset, resp *Response) {
+	findings := c.reuseAudit(asset, &previous.asset)
+
+	c.mu.Lock()
+	asset.StatusCode = previous.asset.StatusCode
+	asset.RedirectTo = previous.asset.RedirectTo
+	asset.ContentHash = previous.asset.ContentHash
+	asset.TextHash = previous.asset.TextHash
+	asset.SimHash = previous.asset.SimHash
+	asset.Findings = findings
+	asset.AuditValues = previous.asset.AuditValues
+	asset.Metadata = previous.asset.Metadata
+	asset.Fields = previous.asset.Fields
+	asset.Charset = previous.asset.Charset
+	asset.Rendered = previous.asset.Rendered
+	asset.ContentType = previous.asset.ContentType
+	asset.Encoding = previous.asset.Encoding
+	asset.DecodeError = previous.asset.DecodeError
+	asset.Size = previous.asset.Size
+	asset.TransferSize = previous.asset.TransferSize
+	asset.ETag = previous.asset.ETag
+	asset.LastModified = previous.asset.LastModified
+	asset.NotModified = true
+
+	// Servers may send updated validators with a 304
+	if etag := resp.Header.Get("ETag"); etag != "" {
+		asset.ETag = etag
+	}
+	if lastModified := resp.Header.Get("Last-Modified"); lastModified != "" {
+		asset.LastModified = lastModified
+	}
+	c.mu.Unlock()
+
+	for _, link := range previous.links {
+		for i := 0; i < link.link.Value; i++ {
+			attrs := LinkAttributes{
+				Nofollow:    i < link.link.Nofollow,
+				Boilerplate: i < link.link.Boilerplate,
+				Inferred:    i < link.link.Inferred,
+			}
+
+			c.HandleLink(link.url, asset, link.contentType, attrs)
+		}
+	}
+
+	c.mu.Lock()
+	c.emitAsset(ASSET_FETCHED, asset)
+	c.mu.Unlock()
+}
@@ -0,0 +1,162 @@
+package crawler_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	u "net/url"
+
+	"github.com/kevinoconnor7/digitalocean-crawler/audit"
+	. "github.com/kevinoconnor7/digitalocean-crawler/crawler"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Incremental", func() {
+	var (
+		ts         *httptest.Server
+		pages      map[string]string
+		downloads  map[string]int
+		conditions map[string]string
+	)
+
+	BeforeEach(func() {
+		pages = map[string]string{
+			"/":      `<html><head><title>Home page title</title></head><body><nav><a href="/a" rel="nofollow">A</a></nav><a href="/a">A</a><a href="/b">B</a></body></html>`,
+			"/a":     `<a href="/">Home</a>`,
+			"/b":     `<a href="/">Home</a>`,
+			"/new":   `<a href="/">Home</a>`,
+			"/dated": `<a href="/">Home</a>`,
+		}
+		downloads = make(map[string]int)
+		conditions = make(map[string]string)
+
+		ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, ok := pages[r.URL.Path]
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+
+			conditions[r.URL.Path] = r.Header.Get("If-None-Match") + r.Header.Get("If-Modified-Since")
+
+			if r.URL.Path == "/dated" {
+				w.Header().Set("Last-Modified", "Mon, 02 Jan 2006 15:04:05 GMT")
+				if r.Header.Get("If-Modified-Since") != "" {
+					w.WriteHeader(http.StatusNotModified)
+					return
+				}
+			} else {
+				etag := fmt.Sprintf(`"%x"`, HashContent([]byte(body))[:8])
+				w.Header().Set("ETag", etag)
+				if r.Header.Get("If-None-Match") == etag {
+					w.WriteHeader(http.StatusNotModified)
+					return
+				}
+			}
+
+			downloads[r.URL.Path]++
+			fmt.Fprint(w, body)
+		}))
+	})
+
+	AfterEach(func() {
+		ts.Close()
+	})
+
+	crawl := func(previous *Snapshot) *Crawler {
+		url, _ := u.Parse(ts.URL + "/")
+		config := DefaultConfig()
+		config.Previous = previous
+
+		c := GenerateCrawlerWithConfig(url, config)
+		Expect(c.Run(10)).To(Succeed())
+
+		return c
+	}
+
+	It("Should record validators", func() {
+		c := crawl(nil)
+		Expect(c.Assets[ts.URL+"/"].ETag).ToNot(BeEmpty())
+		Expect(c.Assets[ts.URL+"/"].NotModified).To(BeFalse())
+	})
+
+	It("Should reuse unchanged assets from the previous crawl", func() {
+		pages["/a"] = `<a href="/">Home</a><a href="/dated">Dated</a>`
+		first := crawl(nil).Snapshot()
+
+		pages["/b"] = `<a href="/">Home</a><a href="/new">New</a>`
+		c := crawl(first)
+
+		Expect(conditions["/"]).ToNot(BeEmpty())
+		Expect(downloads["/"]).To(Equal(1))
+		Expect(downloads["/a"]).To(Equal(1))
+		Expect(downloads["/dated"]).To(Equal(1))
+		Expect(downloads["/b"]).To(Equal(2))
+
+		home := c.Assets[ts.URL+"/"]
+		Expect(home.NotModified).To(BeTrue())
+		Expect(home.StatusCode).To(Equal(http.StatusOK))
+		Expect(home.Metadata.Title).To(Equal("Home page title"))
+		Expect(home.ContentHash).To(Equal(first.Nodes[0].ContentHash))
+
+		Expect(c.Assets[ts.URL+"/dated"].NotModified).To(BeTrue())
+		Expect(c.Assets[ts.URL+"/b"].NotModified).To(BeFalse())
+		Expect(c.Assets[ts.URL+"/new"]).ToNot(BeNil())
+
+		// Links of unchanged pages are kept with their attributes
+		link := home.Links[ts.URL+"/a"]
+		Expect(link.Value).To(Equal(2))
+		Expect(link.Nofollow).To(Equal(1))
+		Expect(link.Boilerplate).To(Equal(1))
+		Expect(c.Links).To(HaveLen(len(first.Links) + 2))
+	})
+
+	It("Should judge unchanged pages against the rest of the site again", func() {
+		pages["/a"] = `<title>Shared</title><a href="/">Home</a>`
+		pages["/b"] = `<title>Shared</title><a href="/">Home</a>`
+		first := crawl(nil).Snapshot()
+		Expect(ruleFindings(first.Nodes, ts.URL+"/a", "title-duplicate")).To(HaveLen(1))
+
+		pages["/b"] = `<title>Home page title</title><a href="/">Home</a>`
+		c := crawl(first)
+
+		// The stale duplicate of /a is dropped and the new one of / is found
+		Expect(c.Assets[ts.URL+"/a"].NotModified).To(BeTrue())
+		Expect(c.Assets[ts.URL+"/"].NotModified).To(BeTrue())
+		nodes := c.Snapshot().Nodes
+		Expect(ruleFindings(nodes, ts.URL+"/a", "title-duplicate")).To(BeEmpty())
+		Expect(ruleFindings(nodes, ts.URL+"/", "title-duplicate")).To(HaveLen(1))
+		Expect(ruleFindings(nodes, ts.URL+"/b", "title-duplicate")).To(HaveLen(1))
+	})
+
+	It("Should download pages that weren't in the previous crawl", func() {
+		home := pages["/"]
+		pages["/"] = `<a href="/b">B</a>`
+		first := crawl(nil).Snapshot()
+		Expect(downloads["/a"]).To(Equal(0))
+
+		pages["/"] = home
+		crawl(first)
+		Expect(conditions["/a"]).To(BeEmpty())
+		Expect(downloads["/a"]).To(Equal(1))
+	})
+})
+
+// ruleFindings returns the findings of a rule for the node at url
+func ruleFindings(nodes []Asset, url string, rule string) []audit.Finding {
+	var findings []audit.Finding
+	for _, node := range nodes {
+		if node.URL != url {
+			continue
+		}
+		for _, finding := range node.Findings {
+			if finding.Rule == rule {
+				findings = append(findings, finding)
+			}
+		}
+	}
+
+	return findings
+}
@@ -0,0 +1,161 @@
+package crawler
+
+import (
+	"bytes"
+	"io"
+	"path"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// absoluteURL matches http(s) URLs, including those with JSON escaped slashes
+var absoluteURL = regexp.MustCompile(`https?:(?:\\?/){2}[^\s"'<>()\\` + "`" + `]+(?:\\/[^\s"'<>()\\` + "`" + `]*)*`)
+
+// quotedPath matches root relative paths in quotes, as written in scripts
+var quotedPath = regexp.MustCompile(`["'` + "`" + `](\\?/(?:[A-Za-z0-9_\-.~%!$&+,;=:@]|\\?/)+(?:\?[^\s"'` + "`" + `<>]*)?)["'` + "`" + `]`)
+
+// inferredTypes guesses the asset type of an inferred URL by its extension
+var inferredTypes = map[string]ContentType{
+	".css": STYLESHEETS,
+	".js":  SCRIPTS, ".mjs": SCRIPTS,
+	".png": IMAGES, ".jpg": IMAGES, ".jpeg": IMAGES, ".gif": IMAGES,
+	".svg": IMAGES, ".webp": IMAGES, ".avif": IMAGES, ".ico": IMAGES,
+}
+
+// uninferredExtensions are files that look like URLs in scripts but aren't
+// pages or assets the crawler tracks
+var uninferredExtensions = map[string]bool{
+	".json": true, ".map": true, ".woff": true, ".woff2": true, ".ttf": true,
+	".otf": true, ".eot": true, ".mp4": true, ".webm": true, ".mp3": true,
+}
+
+// InferLinks tokenizes HTML from r and calls found for every distinct
+// URL-looking string in inline scripts, JSON-LD, data-* attributes and
+// event handlers, in document order
+// Whether they're really links is only a guess
+func InferLinks(r io.Reader, found func(url string, contentType ContentType)) error {
+	tokenizer := html.NewTokenizer(r)
+	seen := make(map[string]bool)
+	inScript := false
+
+	add := func(url string) {
+		if seen[url] {
+			return
+		}
+		seen[url] = true
+
+		if contentType, ok := inferredType(url); ok {
+			found(url, contentType)
+		}
+	}
+
+	infer := func(text string) {
+		for _, url := range findURLs(text) {
+			add(url)
+		}
+	}
+
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			if tokenizer.Err() == io.EOF {
+				return nil
+			}
+			return tokenizer.Err()
+
+		case html.StartTagToken, html.SelfClosingTagToken:
+			token := tokenizer.Token()
+			inScript = token.Data == "script" && token.Type == html.StartTagToken
+
+			for _, attr := range token.Attr {
+				key := strings.ToLower(attr.Key)
+
+				switch {
+				case strings.HasPrefix(key, "data-"):
+					// Data attributes often hold a bare path
+					if value := strings.TrimSpace(attr.Val); isPath(value) {
+						add(value)
+					} else {
+						infer(attr.Val)
+					}
+				case strings.HasPrefix(key, "on"):
+					infer(attr.Val)
+				}
+			}
+
+		case html.TextToken:
+			if inScript {
+				infer(string(tokenizer.Text()))
+			}
+
+		case html.EndTagToken:
+			inScript = false
+		}
+	}
+}
+
+// findURLs finds absolute URLs and quoted root relative paths in text
+func findURLs(text string) []string {
+	var urls []string
+
+	for _, match := range absoluteURL.FindAllString(text, -1) {
+		urls = append(urls, strings.TrimRight(strings.ReplaceAll(match, `\/`, "/"), ".,;:"))
+	}
+
+	for _, match := range quotedPath.FindAllStringSubmatch(text, -1) {
+		url := strings.ReplaceAll(match[1], `\/`, "/")
+
+		// Comments and protocol relative URLs aren't paths
+		if !strings.HasPrefix(url, "//") {
+			urls = append(urls, url)
+		}
+	}
+
+	return urls
+}
+
+// isPath reports whether value is nothing but a root relative path
+func isPath(value string) bool {
+	return strings.HasPrefix(value, "/") && !strings.HasPrefix(value, "//") && !strings.ContainsAny(value, " \t\n\"'<>")
+}
+
+// inferredType guesses the asset type of url from its extension
+// Returns false for files the crawler doesn't track
+func inferredType(url string) (ContentType, bool) {
+	file := url
+	if i := strings.IndexAny(file, "?#"); i >= 0 {
+		file = file[:i]
+	}
+
+	extension := strings.ToLower(path.Ext(file))
+	if uninferredExtensions[extension] {
+		return 0, false
+	}
+
+	if contentType, ok := inferredTypes[extension]; ok {
+		return contentType, true
+	}
+
+	return PAGE, true
+}
+
+// inferLinks links a page to the same-host URLs InferLinks finds in it, if
+// InferLinks is configured
+func (c *Crawler) inferLinks(asset *Asset, body []byte) error {
+	if !c.config.InferLinks || asset.Type != PAGE {
+		return nil
+	}
+
+	return InferLinks(bytes.NewReader(body), func(url string, contentType ContentType) {
+		resolved, external, err := ResolveURL(url, c.baseURL)
+
+		// Pages refer to themselves in structured data all the time
+		if err != nil || external || resolved == asset.URL {
+			return
+		}
+
+		c.HandleLink(resolved, asset, contentType, LinkAttributes{Inferred: true})
+	})
+}
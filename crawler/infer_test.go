@@ -0,0 +1,116 @@
+package crawler_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	u "net/url"
+	"strings"
+
+	. "github.com/kevinoconnor7/digitalocean-crawler/crawler"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("InferLinks", func() {
+	infer := func(html string) map[string]ContentType {
+		found := make(map[string]ContentType)
+		err := InferLinks(strings.NewReader(html), func(url string, contentType ContentType) {
+			Expect(found).ToNot(HaveKey(url))
+			found[url] = contentType
+		})
+		Expect(err).ToNot(HaveOccurred())
+		return found
+	}
+
+	It("Should find paths and URLs in inline scripts", func() {
+		found := infer(`<script>
+// Not a path: /comment
+var routes = ["/pricing", '/docs/intro?lang=en'];
+fetch("https://foo.faketld/products");
+load(` + "`/app.js`" + `);
+var ratio = width / 2;
+</script>`)
+		Expect(found).To(Equal(map[string]ContentType{
+			"/pricing":                     PAGE,
+			"/docs/intro?lang=en":          PAGE,
+			"https://foo.faketld/products": PAGE,
+			"/app.js":                      SCRIPTS,
+		}))
+	})
+
+	It("Should unescape URLs in JSON", func() {
+		found := infer(`<script type="application/ld+json">{"url": "https:\/\/foo.faketld\/about", "logo": "\/logo.png"}</script>`)
+		Expect(found).To(HaveKeyWithValue("https://foo.faketld/about", PAGE))
+		Expect(found).To(HaveKeyWithValue("/logo.png", IMAGES))
+	})
+
+	It("Should find URLs in data and event handler attributes", func() {
+		found := infer(`<div data-href="/signup" data-config='{"next": "/welcome"}' onclick="location.href='/checkout'" title="/not-a-link">`)
+		Expect(found).To(Equal(map[string]ContentType{
+			"/signup":   PAGE,
+			"/welcome":  PAGE,
+			"/checkout": PAGE,
+		}))
+	})
+
+	It("Should ignore script sources and untracked files", func() {
+		found := infer(`<script src="/main.js"></script><script>load("/data.json", "/font.woff2", "//cdn.faketld/x.js")</script>`)
+		Expect(found).To(BeEmpty())
+	})
+
+	Describe("Crawling", func() {
+		var ts *httptest.Server
+
+		BeforeEach(func() {
+			ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/" {
+					return
+				}
+
+				fmt.Fprintf(w, `<a href="/about">About</a>
+<button data-href="/about">About</button>
+<button onclick="go('/signup')">Sign up</button>
+<script type="application/ld+json">{"url": "%[1]s/", "sameAs": "https://elsewhere.faketld/"}</script>`, "http://"+r.Host)
+			}))
+		})
+
+		AfterEach(func() {
+			ts.Close()
+		})
+
+		crawl := func(infer bool) *Asset {
+			url, _ := u.Parse(ts.URL + "/")
+			config := DefaultConfig()
+			config.InferLinks = infer
+			config.TrackExternal = true
+			c := GenerateCrawlerWithConfig(url, config)
+			Expect(c.Run(10)).To(Succeed())
+			return c.Assets[ts.URL+"/"]
+		}
+
+		It("Should mark inferred links", func() {
+			home := crawl(true)
+			Expect(home.Links[ts.URL+"/signup"].Inferred).To(Equal(1))
+			Expect(home.Links[ts.URL+"/signup"].OnlyInferred()).To(BeTrue())
+
+			about := home.Links[ts.URL+"/about"]
+			Expect(about.Value).To(Equal(2))
+			Expect(about.Inferred).To(Equal(1))
+			Expect(about.OnlyInferred()).To(BeFalse())
+		})
+
+		It("Should only infer links to the same host", func() {
+			home := crawl(true)
+			Expect(home.Links).ToNot(HaveKey("https://elsewhere.faketld/"))
+			Expect(home.Links).ToNot(HaveKey(ts.URL + "/"))
+		})
+
+		It("Should not infer links unless configured", func() {
+			home := crawl(false)
+			Expect(home.Links).ToNot(HaveKey(ts.URL + "/signup"))
+			Expect(home.Links[ts.URL+"/about"].Value).To(Equal(1))
+		})
+	})
+})
@@ -0,0 +1,105 @@
+package crawler
+
+import (
+	"io"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// voidElements never have end tags, so they're never left open
+var voidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true,
+	"hr": true, "img": true, "input": true, "link": true, "meta": true,
+	"param": true, "source": true, "track": true, "wbr": true,
+}
+
+// linkAttributes maps the elements ScanLinks reports to the attribute
+// holding their URL and the type of asset it points at, as in ProcessDoc
+var linkAttributes = map[string]struct {
+	attr        string
+	contentType ContentType
+}{
+	"a":      {"href", PAGE},
+	"img":    {"src", IMAGES},
+	"script": {"src", SCRIPTS},
+	"link":   {"href", STYLESHEETS},
+}
+
+type openElement struct {
+	name        string
+	boilerplate bool
+}
+
+// ScanLinks tokenizes HTML from r and calls found for every link in it, in
+// document order, without building a DOM
+// It finds the same links with the same attributes as ProcessDoc
+func ScanLinks(r io.Reader, found func(url string, contentType ContentType, attrs LinkAttributes)) error {
+	tokenizer := html.NewTokenizer(r)
+	var open []openElement
+
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			if tokenizer.Err() == io.EOF {
+				return nil
+			}
+			return tokenizer.Err()
+
+		case html.StartTagToken, html.SelfClosingTagToken:
+			token := tokenizer.Token()
+			attrs := make(map[string]string, len(token.Attr))
+			for _, attr := range token.Attr {
+				if _, ok := attrs[attr.Key]; !ok {
+					attrs[attr.Key] = attr.Val
+				}
+			}
+
+			boilerplate := isBoilerplate(token.Data, attrs) || (len(open) > 0 && open[len(open)-1].boilerplate)
+
+			if link, ok := linkAttributes[token.Data]; ok {
+				if url, ok := attrs[link.attr]; ok {
+					linkAttrs := LinkAttributes{}
+					if link.contentType == PAGE {
+						linkAttrs.Boilerplate = boilerplate
+						linkAttrs.Nofollow = isNofollow(attrs["rel"])
+					}
+
+					found(url, link.contentType, linkAttrs)
+				}
+			}
+
+			if token.Type == html.StartTagToken && !voidElements[token.Data] {
+				open = append(open, openElement{name: token.Data, boilerplate: boilerplate})
+			}
+
+		case html.EndTagToken:
+			name, _ := tokenizer.TagName()
+
+			// Close everything left open inside the element, ignoring stray
+			// end tags for elements that aren't open
+			for i := len(open) - 1; i >= 0; i-- {
+				if open[i].name == string(name) {
+					open = open[:i]
+					break
+				}
+			}
+		}
+	}
+}
+
+// isBoilerplate reports whether an element matches boilerplateSelector
+func isBoilerplate(name string, attrs map[string]string) bool {
+	role := attrs["role"]
+	return name == "nav" || name == "footer" || role == "navigation" || role == "contentinfo"
+}
+
+func isNofollow(rel string) bool {
+	for _, value := range strings.Fields(strings.ToLower(rel)) {
+		if value == "nofollow" {
+			return true
+		}
+	}
+
+	return false
+}
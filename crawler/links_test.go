@@ -0,0 +1,155 @@
+package crawler_test
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	u "net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	. "github.com/kevinoconnor7/digitalocean-crawler/crawler"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type scannedLink struct {
+	URL   string
+	Type  ContentType
+	Attrs LinkAttributes
+}
+
+func scan(html string) []scannedLink {
+	var links []scannedLink
+
+	err := ScanLinks(strings.NewReader(html), func(url string, contentType ContentType, attrs LinkAttributes) {
+		links = append(links, scannedLink{url, contentType, attrs})
+	})
+	Expect(err).ToNot(HaveOccurred())
+
+	return links
+}
+
+var _ = Describe("ScanLinks", func() {
+	It("Should find links in document order", func() {
+		Expect(scan(`<html><head>
+<link rel="stylesheet" href="/site.css">
+<script src="/app.js"></script>
+</head><body>
+<a href="/a">A</a><img src="/logo.png"><a>No href</a>
+</body></html>`)).To(Equal([]scannedLink{
+			{"/site.css", STYLESHEETS, LinkAttributes{}},
+			{"/app.js", SCRIPTS, LinkAttributes{}},
+			{"/a", PAGE, LinkAttributes{}},
+			{"/logo.png", IMAGES, LinkAttributes{}},
+		}))
+	})
+
+	It("Should mark nofollow and boilerplate links", func() {
+		links := scan(`<nav><ul><li><a href="/nav">Nav</a></li></ul></nav>
+<div role="contentinfo"><p><a href="/info" rel="Nofollow noopener">Info</a></p></div>
+<footer><br><img src="/footer.png"></footer>
+<a href="/body">Body</a>`)
+
+		Expect(links).To(Equal([]scannedLink{
+			{"/nav", PAGE, LinkAttributes{Boilerplate: true}},
+			{"/info", PAGE, LinkAttributes{Boilerplate: true, Nofollow: true}},
+			{"/footer.png", IMAGES, LinkAttributes{}},
+			{"/body", PAGE, LinkAttributes{}},
+		}))
+	})
+
+	It("Should ignore markup inside scripts", func() {
+		Expect(scan(`<script>document.write('<a href="/fake">')</script><a href="/real">Real</a>`)).To(Equal([]scannedLink{
+			{"/real", PAGE, LinkAttributes{}},
+		}))
+	})
+
+	It("Should recover from unclosed and stray tags", func() {
+		links := scan(`<nav><div><a href="/nav">Nav</a></nav></span><a href="/body">Body</a>`)
+		Expect(links[0].Attrs.Boilerplate).To(BeTrue())
+		Expect(links[1].Attrs.Boilerplate).To(BeFalse())
+	})
+
+	It("Should agree with the DOM", func() {
+		html := `<nav><a href="/a">A</a></nav><footer><a href="/b" rel="nofollow">B</a></footer><p><a href="/c">C</a></p>`
+		doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+		Expect(err).ToNot(HaveOccurred())
+
+		var expected []scannedLink
+		doc.Find("a").Each(func(i int, s *goquery.Selection) {
+			href, _ := s.Attr("href")
+			expected = append(expected, scannedLink{href, PAGE, GetLinkAttributes(s)})
+		})
+
+		Expect(scan(html)).To(Equal(expected))
+	})
+})
+
+var _ = Describe("Body limits", func() {
+	var ts *httptest.Server
+
+	BeforeEach(func() {
+		ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/" {
+				fmt.Fprint(w, "small")
+				return
+			}
+
+			fmt.Fprint(w, `<a href="/early">Early</a>`)
+			fmt.Fprint(w, strings.Repeat(" ", 1000))
+			fmt.Fprint(w, `<a href="/late">Late</a>`)
+		}))
+	})
+
+	AfterEach(func() {
+		ts.Close()
+	})
+
+	It("Should truncate bodies over the limit", func() {
+		req, _ := GenerateRequest("GET", ts.URL, nil)
+		f := GenerateHTTPFetcher(http.DefaultClient)
+		f.MaxBodySize = 100
+
+		resp, err := f.Fetch(req)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resp.Body).To(HaveLen(100))
+		Expect(resp.Truncated).To(BeTrue())
+
+		req, _ = GenerateRequest("GET", ts.URL+"/small", nil)
+		resp, err = f.Fetch(req)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(bytes.Equal(resp.Body, []byte("small"))).To(BeTrue())
+		Expect(resp.Truncated).To(BeFalse())
+	})
+
+	It("Should only follow links before the limit", func() {
+		url, _ := u.Parse(ts.URL + "/")
+
+		for _, stream := range []bool{false, true} {
+			config := DefaultConfig()
+			config.MaxBodySize = 100
+			config.StreamLinks = stream
+
+			c := GenerateCrawlerWithConfig(url, config)
+			Expect(c.Run(10)).To(Succeed())
+			Expect(c.Assets[ts.URL+"/"].Truncated).To(BeTrue())
+			Expect(c.Assets[ts.URL+"/early"]).ToNot(BeNil())
+			Expect(c.Assets[ts.URL+"/late"]).To(BeNil())
+		}
+	})
+
+	It("Should skip the DOM when streaming links", func() {
+		url, _ := u.Parse(ts.URL + "/")
+		config := DefaultConfig()
+		config.StreamLinks = true
+
+		c := GenerateCrawlerWithConfig(url, config)
+		Expect(c.Run(10)).To(Succeed())
+		Expect(c.Assets[ts.URL+"/late"]).ToNot(BeNil())
+		Expect(c.Assets[ts.URL+"/"].Metadata).To(BeNil())
+		Expect(c.Assets[ts.URL+"/"].TextHash).To(BeEmpty())
+	})
+})
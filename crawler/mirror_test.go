@@ -0,0 +1,90 @@
+package crawler_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	u "net/url"
+	"os"
+	"path/filepath"
+
+	. "github.com/kevinoconnor7/digitalocean-crawler/crawler"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Mirror", func() {
+	var (
+		dir string
+		ts  *httptest.Server
+	)
+
+	BeforeEach(func() {
+		var err error
+		dir, err = os.MkdirTemp("", "mirror")
+		Expect(err).ToNot(HaveOccurred())
+
+		mux := http.NewServeMux()
+		mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `<html><head><link rel="stylesheet" href="/site.css"></head><body><a href="/about">About</a></body></html>`)
+		})
+		mux.HandleFunc("/missing", http.NotFound)
+		mux.HandleFunc("/about", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `<html><body><a href="/">Home</a><a href="/missing">Missing</a></body></html>`)
+		})
+		mux.HandleFunc("/site.css", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/css")
+			fmt.Fprint(w, `body { background: url(/bg.png) }`)
+		})
+		ts = httptest.NewServer(mux)
+	})
+
+	AfterEach(func() {
+		ts.Close()
+		os.RemoveAll(dir)
+	})
+
+	It("Should save fetched assets with local links", func() {
+		url, _ := u.Parse(ts.URL + "/")
+		config := DefaultConfig()
+		config.MirrorDir = dir
+
+		c := GenerateCrawlerWithConfig(url, config)
+		Expect(c.Run(10)).To(Succeed())
+
+		index, err := os.ReadFile(filepath.Join(dir, "index.html"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(index)).To(ContainSubstring(`href="site.css"`))
+		Expect(string(index)).To(ContainSubstring(`href="about/index.html"`))
+
+		about, err := os.ReadFile(filepath.Join(dir, "about", "index.html"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(about)).To(ContainSubstring(`href="../index.html"`))
+
+		css, err := os.ReadFile(filepath.Join(dir, "site.css"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(css)).To(Equal(`body { background: url(bg.png) }`))
+
+		// Error responses aren't worth keeping
+		_, err = os.Stat(filepath.Join(dir, "missing", "index.html"))
+		Expect(os.IsNotExist(err)).To(BeTrue())
+	})
+
+	It("Should keep crawling assets that can't be saved", func() {
+		// A file where the about page's directory belongs makes it unwritable
+		Expect(os.WriteFile(filepath.Join(dir, "about"), nil, 0644)).To(Succeed())
+
+		url, _ := u.Parse(ts.URL + "/")
+		config := DefaultConfig()
+		config.MirrorDir = dir
+
+		c := GenerateCrawlerWithConfig(url, config)
+		Expect(c.Run(10)).To(Succeed())
+
+		Expect(c.Assets[ts.URL+"/about"].StatusCode).To(Equal(http.StatusOK))
+		Expect(c.Assets[ts.URL+"/about"].MirrorError).ToNot(BeEmpty())
+		Expect(c.Assets[ts.URL+"/missing"]).ToNot(BeNil())
+		Expect(c.Assets[ts.URL+"/"].MirrorError).To(BeEmpty())
+	})
+})
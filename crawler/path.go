@@ -0,0 +1,150 @@
+package crawler
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/kevinoconnor7/digitalocean-crawler/graph"
+)
+
+// defaultPathLimit caps how many shortest paths are returned by default
+const defaultPathLimit = 10
+
+// MaxPathLimit is the most shortest paths ever returned, as there can be
+// exponentially many
+const MaxPathLimit = 100
+
+// PathOptions controls which links ShortestPaths may follow
+type PathOptions struct {
+	// IgnoreNofollow skips links that are only ever marked nofollow
+	IgnoreNofollow bool
+
+	// IgnoreBoilerplate skips links that only appear in navigation or footers
+	IgnoreBoilerplate bool
+
+	// IgnoreInferred skips links that were only ever inferred from scripts
+	// and attributes
+	IgnoreInferred bool
+
+	// Limit is the most paths to return, defaulting to 10 and capped at
+	// MaxPathLimit
+	Limit int
+}
+
+// PathResult lists the shortest click paths between two assets
+type PathResult struct {
+	From   Node     `json:"from"`
+	To     Node     `json:"to"`
+	Clicks int      `json:"clicks"`
+	Paths  [][]Node `json:"paths"`
+}
+
+// Lookup finds the node for a URL
+// URLs are compared as the crawler stores them, so they may differ in case,
+// escaping or a missing root path, and relative ones are resolved against
+// the first node
+func (s *Snapshot) Lookup(urlStr string) (Node, error) {
+	for i := range s.Nodes {
+		if s.Nodes[i].URL == urlStr {
+			return Node{Index: i, Asset: s.Nodes[i]}, nil
+		}
+	}
+
+	if len(s.Nodes) > 0 {
+		base, err := url.Parse(s.Nodes[0].URL)
+		if err == nil {
+			key := lookupKey(urlStr, base)
+			for i := range s.Nodes {
+				if lookupKey(s.Nodes[i].URL, base) == key {
+					return Node{Index: i, Asset: s.Nodes[i]}, nil
+				}
+			}
+		}
+	}
+
+	return Node{}, fmt.Errorf("No asset with URL %s", urlStr)
+}
+
+// lookupKey normalizes a URL as ResolveURL does, also giving URLs without
+// a path the root one
+func lookupKey(urlStr string, base *url.URL) string {
+	resolved, _, err := ResolveURL(urlStr, base)
+	if err != nil {
+		return urlStr
+	}
+
+	parsed, err := url.Parse(resolved)
+	if err != nil || parsed.Path != "" || parsed.Opaque != "" {
+		return resolved
+	}
+
+	parsed.Path = "/"
+
+	return parsed.String()
+}
+
+// ShortestPaths finds the fewest clicks needed to get from one URL to another
+// Only links found on pages are followed
+func (s *Snapshot) ShortestPaths(from string, to string, options PathOptions) (*PathResult, error) {
+	source, err := s.Lookup(from)
+
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := s.Lookup(to)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if options.Limit <= 0 {
+		options.Limit = defaultPathLimit
+	}
+
+	if options.Limit > MaxPathLimit {
+		options.Limit = MaxPathLimit
+	}
+
+	g := graph.GenerateGraph(len(s.Nodes))
+	for i := range s.Links {
+		link := &s.Links[i]
+
+		if s.Nodes[link.Source].Type != PAGE {
+			continue
+		}
+
+		if options.IgnoreNofollow && link.OnlyNofollow() {
+			continue
+		}
+
+		if options.IgnoreBoilerplate && link.OnlyBoilerplate() {
+			continue
+		}
+
+		if options.IgnoreInferred && link.OnlyInferred() {
+			continue
+		}
+
+		g.AddEdge(link.Source, link.Target)
+	}
+
+	result := &PathResult{
+		From:   source,
+		To:     target,
+		Clicks: -1,
+		Paths:  [][]Node{},
+	}
+
+	for _, path := range g.ShortestPaths(source.Index, target.Index, options.Limit) {
+		nodes := make([]Node, len(path))
+		for i, index := range path {
+			nodes[i] = Node{Index: index, Asset: s.Nodes[index]}
+		}
+
+		result.Paths = append(result.Paths, nodes)
+		result.Clicks = len(path) - 1
+	}
+
+	return result, nil
+}
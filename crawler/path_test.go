@@ -0,0 +1,134 @@
+package crawler_test
+
+import (
+	"bytes"
+
+	"github.com/PuerkitoBio/goquery"
+	. "github.com/kevinoconnor7/digitalocean-crawler/crawler"
+
+	u "net/url"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Path", func() {
+	var (
+		c *Crawler
+		s *Snapshot
+	)
+
+	process := func(url string, html string) {
+		asset := c.Assets[url]
+		if asset == nil {
+			asset = GenerateAsset(url, PAGE)
+			c.StoreAsset(asset)
+		}
+		doc, _ := goquery.NewDocumentFromReader(bytes.NewBufferString(html))
+		c.ProcessDoc(doc, asset)
+	}
+
+	// The seed reaches /target directly through its footer, or in two
+	// clicks through body content via /a or a nofollow link to /b
+	BeforeEach(func() {
+		url, _ := u.Parse("http://foo.faketld")
+		c = GenerateCrawler(url)
+		process("http://foo.faketld/", `
+<main>
+	<a href="/a">A</a>
+	<a href="/b" rel="nofollow">B</a>
+	<img src="/image.png">
+</main>
+<footer><a href="/target">Target</a></footer>`)
+		process("http://foo.faketld/a", `<a href="/target">Target</a>`)
+		process("http://foo.faketld/b", `<a href="/target">Target</a>`)
+		s = c.Snapshot()
+	})
+
+	Describe("GetLinkAttributes", func() {
+		It("Should count nofollow and boilerplate occurrences", func() {
+			seed := c.Assets["http://foo.faketld/"]
+			Expect(seed.Links["http://foo.faketld/b"].Nofollow).To(Equal(1))
+			Expect(seed.Links["http://foo.faketld/b"].OnlyNofollow()).To(BeTrue())
+			Expect(seed.Links["http://foo.faketld/target"].Boilerplate).To(Equal(1))
+			Expect(seed.Links["http://foo.faketld/a"].OnlyBoilerplate()).To(BeFalse())
+		})
+	})
+
+	Describe("ShortestPaths", func() {
+		It("Should follow every link by default", func() {
+			result, err := s.ShortestPaths("http://foo.faketld/", "http://foo.faketld/target", PathOptions{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.Clicks).To(Equal(1))
+			Expect(len(result.Paths)).To(Equal(1))
+			Expect(result.Paths[0][1].URL).To(Equal("http://foo.faketld/target"))
+		})
+
+		It("Should ignore boilerplate links", func() {
+			result, _ := s.ShortestPaths("http://foo.faketld/", "http://foo.faketld/target", PathOptions{IgnoreBoilerplate: true})
+			Expect(result.Clicks).To(Equal(2))
+			Expect(len(result.Paths)).To(Equal(2))
+		})
+
+		It("Should ignore nofollow links", func() {
+			result, _ := s.ShortestPaths("http://foo.faketld/", "http://foo.faketld/target", PathOptions{
+				IgnoreBoilerplate: true,
+				IgnoreNofollow:    true,
+			})
+			Expect(result.Clicks).To(Equal(2))
+			Expect(len(result.Paths)).To(Equal(1))
+			Expect(result.Paths[0][1].URL).To(Equal("http://foo.faketld/a"))
+		})
+
+		It("Should ignore inferred links", func() {
+			c.HandleLink("/target", c.Assets["http://foo.faketld/a"], PAGE, LinkAttributes{Inferred: true})
+			s = c.Snapshot()
+
+			result, _ := s.ShortestPaths("http://foo.faketld/a", "http://foo.faketld/target", PathOptions{IgnoreInferred: true})
+			Expect(result.Clicks).To(Equal(1))
+
+			c.HandleLink("/b", c.Assets["http://foo.faketld/a"], PAGE, LinkAttributes{Inferred: true})
+			s = c.Snapshot()
+
+			result, _ = s.ShortestPaths("http://foo.faketld/a", "http://foo.faketld/b", PathOptions{IgnoreInferred: true})
+			Expect(result.Clicks).To(Equal(-1))
+		})
+
+		It("Should report unreachable targets", func() {
+			result, err := s.ShortestPaths("http://foo.faketld/target", "http://foo.faketld/", PathOptions{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.Clicks).To(Equal(-1))
+			Expect(result.Paths).To(BeEmpty())
+		})
+
+		It("Should match URLs the way the crawler stores them", func() {
+			result, err := s.ShortestPaths("HTTP://FOO.faketld", "/a", PathOptions{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.From.URL).To(Equal("http://foo.faketld/"))
+			Expect(result.To.URL).To(Equal("http://foo.faketld/a"))
+			Expect(result.Clicks).To(Equal(1))
+		})
+
+		It("Should error for unknown URLs", func() {
+			_, err := s.ShortestPaths("http://foo.faketld/", "http://foo.faketld/missing", PathOptions{})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("LoadSnapshot", func() {
+		It("Should read the output of OutputResults", func() {
+			results, err := c.OutputResults()
+			Expect(err).ToNot(HaveOccurred())
+
+			loaded, err := LoadSnapshot(bytes.NewBufferString(results))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(len(loaded.Nodes)).To(Equal(len(s.Nodes)))
+			Expect(loaded.Links).To(Equal(s.Links))
+		})
+
+		It("Should reject links to missing assets", func() {
+			_, err := LoadSnapshot(bytes.NewBufferString(`{"nodes": [], "links": [{"source": 0, "target": 1}]}`))
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,111 @@
+package crawler
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Node pairs an asset with its index in a Snapshot
+type Node struct {
+	Index int `json:"index"`
+	Asset
+}
+
+// Neighborhood lists the assets linking to and from a node
+type Neighborhood struct {
+	Node     Node   `json:"node"`
+	Inbound  []Node `json:"inbound"`
+	Outbound []Node `json:"outbound"`
+}
+
+// Filter selects assets from a Snapshot
+// Zero values and nil pointers match every asset
+type Filter struct {
+	Query      string
+	Types      []ContentType
+	StatusCode int
+	MaxDepth   *int
+	External   *bool
+}
+
+// Matches reports whether the asset satisfies every criteria of the filter
+func (f *Filter) Matches(asset *Asset) bool {
+	if f.Query != "" && !strings.Contains(strings.ToLower(asset.URL), strings.ToLower(f.Query)) {
+		return false
+	}
+
+	if len(f.Types) > 0 {
+		found := false
+		for _, t := range f.Types {
+			if t == asset.Type {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if f.StatusCode != 0 && f.StatusCode != asset.StatusCode {
+		return false
+	}
+
+	if f.MaxDepth != nil && asset.Depth > *f.MaxDepth {
+		return false
+	}
+
+	if f.External != nil && asset.External != *f.External {
+		return false
+	}
+
+	return true
+}
+
+// Node looks up the asset at index
+func (s *Snapshot) Node(index int) (Node, error) {
+	if index < 0 || index >= len(s.Nodes) {
+		return Node{}, fmt.Errorf("No asset with index %d", index)
+	}
+
+	return Node{Index: index, Asset: s.Nodes[index]}, nil
+}
+
+// Find returns every node matching the filter, in index order
+func (s *Snapshot) Find(f Filter) []Node {
+	nodes := []Node{}
+
+	for i := range s.Nodes {
+		if f.Matches(&s.Nodes[i]) {
+			nodes = append(nodes, Node{Index: i, Asset: s.Nodes[i]})
+		}
+	}
+
+	return nodes
+}
+
+// Neighbors returns the node at index with its inbound and outbound links
+func (s *Snapshot) Neighbors(index int) (*Neighborhood, error) {
+	node, err := s.Node(index)
+
+	if err != nil {
+		return nil, err
+	}
+
+	n := &Neighborhood{
+		Node:     node,
+		Inbound:  []Node{},
+		Outbound: []Node{},
+	}
+
+	for _, link := range s.Links {
+		if link.Target == index {
+			n.Inbound = append(n.Inbound, Node{Index: link.Source, Asset: s.Nodes[link.Source]})
+		}
+		if link.Source == index {
+			n.Outbound = append(n.Outbound, Node{Index: link.Target, Asset: s.Nodes[link.Target]})
+		}
+	}
+
+	return n, nil
+}
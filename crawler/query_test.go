@@ -0,0 +1,78 @@
+package crawler_test
+
+import (
+	. "github.com/kevinoconnor7/digitalocean-crawler/crawler"
+
+	u "net/url"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Query", func() {
+	var s *Snapshot
+
+	BeforeEach(func() {
+		url, _ := u.Parse("http://foo.faketld")
+		c := GenerateCrawlerWithConfig(url, Config{TrackExternal: true})
+		seed := GenerateAsset("http://foo.faketld/", PAGE)
+		c.StoreAsset(seed)
+		c.HandleURL("/about", seed, PAGE)
+		c.HandleURL("/main.js", seed, SCRIPTS)
+		c.HandleURL("http://bar.faketld/", seed, PAGE)
+		c.HandleURL("/", c.Assets["http://foo.faketld/about"], PAGE)
+		c.Assets["http://foo.faketld/about"].StatusCode = 404
+		s = c.Snapshot()
+	})
+
+	Describe("Find", func() {
+		It("Should match everything with an empty filter", func() {
+			Expect(len(s.Find(Filter{}))).To(Equal(4))
+		})
+
+		It("Should search URLs case-insensitively", func() {
+			nodes := s.Find(Filter{Query: "ABOUT"})
+			Expect(len(nodes)).To(Equal(1))
+			Expect(nodes[0].Index).To(Equal(1))
+			Expect(nodes[0].URL).To(Equal("http://foo.faketld/about"))
+		})
+
+		It("Should filter by type", func() {
+			Expect(len(s.Find(Filter{Types: []ContentType{SCRIPTS}}))).To(Equal(1))
+			Expect(len(s.Find(Filter{Types: []ContentType{SCRIPTS, PAGE}}))).To(Equal(4))
+		})
+
+		It("Should filter by status code", func() {
+			Expect(len(s.Find(Filter{StatusCode: 404}))).To(Equal(1))
+		})
+
+		It("Should filter by depth", func() {
+			depth := 0
+			Expect(len(s.Find(Filter{MaxDepth: &depth}))).To(Equal(1))
+		})
+
+		It("Should filter by external", func() {
+			external := true
+			Expect(len(s.Find(Filter{External: &external}))).To(Equal(1))
+			external = false
+			Expect(len(s.Find(Filter{External: &external}))).To(Equal(3))
+		})
+	})
+
+	Describe("Neighbors", func() {
+		It("Should list inbound and outbound links", func() {
+			n, err := s.Neighbors(1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(n.Node.URL).To(Equal("http://foo.faketld/about"))
+			Expect(len(n.Inbound)).To(Equal(1))
+			Expect(n.Inbound[0].Index).To(Equal(0))
+			Expect(len(n.Outbound)).To(Equal(1))
+			Expect(n.Outbound[0].Index).To(Equal(0))
+		})
+
+		It("Should error for unknown indices", func() {
+			_, err := s.Neighbors(10)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,90 @@
+package crawler
+
+import (
+	"net/http"
+
+	"github.com/kevinoconnor7/digitalocean-crawler/render"
+)
+
+// requestTypes are the asset types the requests of a rendered page are
+// linked as
+var requestTypes = map[string]ContentType{
+	render.STYLESHEET: STYLESHEETS,
+	render.SCRIPT:     SCRIPTS,
+	render.IMAGE:      IMAGES,
+}
+
+// renders reports whether the page in resp should be rendered before its
+// links are found
+func (c *Crawler) renders(asset *Asset, resp *Response) bool {
+	if c.config.Renderer == nil || asset.Type != PAGE || resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return false
+	}
+
+	switch mediaType(resp.Header.Get("Content-Type")) {
+	case "", "text/html", "application/xhtml+xml":
+		return c.config.RenderPatterns.Match(asset.URL)
+	}
+
+	return false
+}
+
+// render loads the page in the Renderer, returning its rendered DOM and the
+// requests it made
+// Pages that fail to render keep their fetched body, with the error
+// recorded against the asset
+func (c *Crawler) render(asset *Asset, body []byte) ([]byte, []render.Request) {
+	result, err := c.renderPage(asset.URL)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err != nil {
+		asset.RenderError = err.Error()
+		return body, nil
+	}
+
+	asset.Rendered = true
+	return result.HTML, result.Requests
+}
+
+func (c *Crawler) renderPage(urlStr string) (*render.Result, error) {
+	req, err := c.newRequest(http.MethodGet, urlStr, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// A logged in session carries over to the browser
+	if c.httpClient.Jar != nil {
+		for _, cookie := range c.httpClient.Jar.Cookies(req.URL) {
+			req.AddCookie(cookie)
+		}
+	}
+
+	return c.config.Renderer.Render(req)
+}
+
+// linkRequests links a rendered page to the stylesheets, scripts and images
+// it requested that aren't already linked from its DOM, such as those
+// added by scripts
+func (c *Crawler) linkRequests(asset *Asset, requests []render.Request) {
+	for _, request := range requests {
+		contentType, ok := requestTypes[request.Type]
+		if !ok {
+			continue
+		}
+
+		url, _, err := ResolveURL(request.URL, c.baseURL)
+		if err != nil {
+			continue
+		}
+
+		c.mu.RLock()
+		_, linked := asset.Links[url]
+		c.mu.RUnlock()
+
+		if !linked {
+			c.HandleURL(url, asset, contentType)
+		}
+	}
+}
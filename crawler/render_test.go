@@ -0,0 +1,100 @@
+package crawler_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	u "net/url"
+
+	. "github.com/kevinoconnor7/digitalocean-crawler/crawler"
+	"github.com/kevinoconnor7/digitalocean-crawler/render"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Render", func() {
+	var (
+		ts       *httptest.Server
+		renderer *render.FakeRenderer
+	)
+
+	BeforeEach(func() {
+		ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/", "/app/":
+				w.Write([]byte(`<div id="root"></div><script src="/app.js"></script>`))
+			case "/feed.xml":
+				w.Header().Set("Content-Type", "application/xml")
+				w.Write([]byte(`<feed></feed>`))
+			}
+		}))
+
+		renderer = render.GenerateFakeRenderer()
+		renderer.Add(ts.URL+"/",
+			`<div id="root"><a href="/app/">App</a></div><script src="/app.js"></script><a href="/feed.xml">Feed</a>`,
+			render.Request{URL: ts.URL + "/", Method: "GET", Type: render.DOCUMENT, StatusCode: 200},
+			render.Request{URL: ts.URL + "/app.js", Method: "GET", Type: render.SCRIPT, StatusCode: 200},
+			render.Request{URL: ts.URL + "/chunk.js", Method: "GET", Type: render.SCRIPT, StatusCode: 200},
+			render.Request{URL: ts.URL + "/api/items", Method: "GET", Type: "Fetch", StatusCode: 200},
+		)
+	})
+
+	AfterEach(func() {
+		ts.Close()
+	})
+
+	crawl := func(patterns ...string) *Crawler {
+		url, _ := u.Parse(ts.URL + "/")
+		config := DefaultConfig()
+		config.Renderer = renderer
+
+		var err error
+		config.RenderPatterns, err = render.CompilePatterns(patterns...)
+		Expect(err).ToNot(HaveOccurred())
+
+		c := GenerateCrawlerWithConfig(url, config)
+		Expect(c.Run(10)).To(Succeed())
+		return c
+	}
+
+	It("Should find links in the rendered DOM", func() {
+		c := crawl(`:\d+/$`)
+		home := c.Assets[ts.URL+"/"]
+		Expect(home.Rendered).To(BeTrue())
+		Expect(home.Links).To(HaveKey(ts.URL + "/app/"))
+		Expect(home.ContentHash).ToNot(BeEmpty())
+	})
+
+	It("Should link scripts, stylesheets and images the page requested", func() {
+		c := crawl(`:\d+/$`)
+		home := c.Assets[ts.URL+"/"]
+		Expect(home.Links).To(HaveKey(ts.URL + "/chunk.js"))
+		Expect(c.Assets[ts.URL+"/chunk.js"].Type).To(Equal(SCRIPTS))
+		Expect(home.Links).ToNot(HaveKey(ts.URL + "/api/items"))
+	})
+
+	It("Should not double count requests already linked", func() {
+		c := crawl(`:\d+/$`)
+		Expect(c.Assets[ts.URL+"/"].Links[ts.URL+"/app.js"].Value).To(Equal(1))
+	})
+
+	It("Should only render pages matching the patterns", func() {
+		c := crawl(`:\d+/$`)
+		Expect(c.Assets[ts.URL+"/app/"].Rendered).To(BeFalse())
+		Expect(c.Assets[ts.URL+"/feed.xml"].Rendered).To(BeFalse())
+		Expect(renderer.Rendered()).To(HaveLen(1))
+	})
+
+	It("Should keep the fetched page when rendering fails", func() {
+		c := crawl()
+		app := c.Assets[ts.URL+"/app/"]
+		Expect(app.Rendered).To(BeFalse())
+		Expect(app.RenderError).To(ContainSubstring("Unable to render"))
+		Expect(app.Links).To(HaveKey(ts.URL + "/app.js"))
+	})
+
+	It("Should send the crawler's headers to the renderer", func() {
+		crawl(`:\d+/$`)
+		Expect(renderer.Rendered()[0].Header.Get("User-Agent")).To(Equal(DefaultUserAgent))
+	})
+})
@@ -0,0 +1,64 @@
+package crawler
+
+import "io"
+
+// Replay rebuilds a crawl from WARC archives instead of the network,
+// processing every archived response in the order it was captured as if
+// it had just been fetched
+func (c *Crawler) Replay(archives ...io.ReaderAt) error {
+	err := c.start()
+	if err != nil {
+		return err
+	}
+
+	fetcher, err := LoadReplayFetcher(archives...)
+	if err == nil {
+		fetcher.MaxBodySize = c.config.MaxBodySize
+		c.fetcher = fetcher
+		err = c.replay(fetcher)
+	}
+	c.finishAudit()
+	c.finish(err)
+
+	return err
+}
+
+func (c *Crawler) replay(fetcher *ReplayFetcher) error {
+	if c.config.SitemapURL != "" {
+		err := c.LoadSitemap(c.config.SitemapURL)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, url := range fetcher.URLs() {
+		if !c.proceed() {
+			return nil
+		}
+
+		c.mu.Lock()
+		asset, ok := c.Assets[url]
+
+		// Everything the crawl fetched was discovered before it was
+		// fetched, so anything else is a sitemap or some other download
+		if !ok || asset.processed {
+			c.mu.Unlock()
+			continue
+		}
+
+		asset.processed = true
+		c.mu.Unlock()
+
+		resp, err := c.fetch(url)
+		if err != nil {
+			return err
+		}
+
+		err = c.processResponse(asset, resp)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,206 @@
+package crawler_test
+
+import (
+	"compress/gzip"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	u "net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	. "github.com/kevinoconnor7/digitalocean-crawler/crawler"
+	"github.com/kevinoconnor7/digitalocean-crawler/warc"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Replay", func() {
+	var (
+		dir string
+		ts  *httptest.Server
+	)
+
+	BeforeEach(func() {
+		var err error
+		dir, err = os.MkdirTemp("", "warc")
+		Expect(err).ToNot(HaveOccurred())
+
+		mux := http.NewServeMux()
+		mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `<html><head><title>Home page title</title></head><body><a href="/about">About</a><a href="/old">Old</a></body></html>`)
+		})
+		mux.HandleFunc("/about", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `<html><body><a href="/">Home</a><img src="/logo.png"></body></html>`)
+		})
+		mux.HandleFunc("/old", func(w http.ResponseWriter, r *http.Request) {
+			http.Redirect(w, r, "/about", http.StatusMovedPermanently)
+		})
+		ts = httptest.NewServer(mux)
+	})
+
+	AfterEach(func() {
+		ts.Close()
+		os.RemoveAll(dir)
+	})
+
+	archive := func() (*Crawler, []string) {
+		url, _ := u.Parse(ts.URL + "/")
+		config := DefaultConfig()
+		config.WARCDir = dir
+
+		c := GenerateCrawlerWithConfig(url, config)
+		Expect(c.Run(10)).To(Succeed())
+
+		files, err := os.ReadDir(dir)
+		Expect(err).ToNot(HaveOccurred())
+
+		var paths []string
+		for _, file := range files {
+			paths = append(paths, dir+"/"+file.Name())
+		}
+
+		return c, paths
+	}
+
+	It("Should record the WARC record of every fetched asset", func() {
+		c, paths := archive()
+
+		Expect(paths).To(HaveLen(1))
+		for _, asset := range c.AssetsArray {
+			Expect(asset.WARCRecordID).To(HavePrefix("<urn:uuid:"))
+		}
+	})
+
+	It("Should rebuild the crawl from the archive without the network", func() {
+		original, paths := archive()
+		ts.Close()
+
+		file, err := os.Open(paths[0])
+		Expect(err).ToNot(HaveOccurred())
+		defer file.Close()
+
+		url, _ := u.Parse(ts.URL + "/")
+		c := GenerateCrawler(url)
+		Expect(c.Replay(file)).To(Succeed())
+		Expect(c.State()).To(Equal(FINISHED))
+
+		Expect(len(c.AssetsArray)).To(Equal(len(original.AssetsArray)))
+		Expect(len(c.Links)).To(Equal(len(original.Links)))
+
+		for _, asset := range original.AssetsArray {
+			replayed := c.Assets[asset.URL]
+			Expect(replayed).ToNot(BeNil())
+			Expect(replayed.StatusCode).To(Equal(asset.StatusCode))
+			Expect(replayed.ContentHash).To(Equal(asset.ContentHash))
+			Expect(replayed.RedirectTo).To(Equal(asset.RedirectTo))
+			Expect(replayed.WARCRecordID).To(Equal(asset.WARCRecordID))
+		}
+
+		Expect(c.Assets[ts.URL+"/"].Metadata.Title).To(Equal("Home page title"))
+	})
+
+	It("Should replay pages truncated at the maximum size", func() {
+		ts.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body := `<a href="/early">Early</a>` + strings.Repeat(" ", 1000)
+			w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+			fmt.Fprint(w, body)
+		})
+
+		url, _ := u.Parse(ts.URL + "/")
+		config := DefaultConfig()
+		config.WARCDir = dir
+		config.MaxBodySize = 100
+
+		original := GenerateCrawlerWithConfig(url, config)
+		Expect(original.Run(10)).To(Succeed())
+		Expect(original.Assets[ts.URL+"/"].Truncated).To(BeTrue())
+
+		files, err := os.ReadDir(dir)
+		Expect(err).ToNot(HaveOccurred())
+
+		file, err := os.Open(dir + "/" + files[0].Name())
+		Expect(err).ToNot(HaveOccurred())
+		defer file.Close()
+
+		c := GenerateCrawler(url)
+		Expect(c.Replay(file)).To(Succeed())
+		Expect(c.Assets[ts.URL+"/"].Truncated).To(BeTrue())
+		Expect(c.Assets[ts.URL+"/"].ContentHash).To(Equal(original.Assets[ts.URL+"/"].ContentHash))
+		Expect(c.Assets[ts.URL+"/early"]).ToNot(BeNil())
+	})
+
+	It("Should archive bodies as they were received and decode them on replay", func() {
+		ts.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Header().Set("Content-Encoding", "gzip")
+			gz := gzip.NewWriter(w)
+			fmt.Fprint(gz, `<html><head><title>Compressed</title></head><body>`+strings.Repeat("lorem ipsum ", 200)+`</body></html>`)
+			gz.Close()
+		})
+
+		original, paths := archive()
+		home := original.Assets[ts.URL+"/"]
+		Expect(home.Encoding).To(Equal("gzip"))
+
+		file, err := os.Open(paths[0])
+		Expect(err).ToNot(HaveOccurred())
+		defer file.Close()
+
+		reader, err := warc.GenerateReader(file)
+		Expect(err).ToNot(HaveOccurred())
+		for {
+			record, err := reader.Next()
+			Expect(err).ToNot(HaveOccurred())
+			if record.Type() != warc.RESPONSE {
+				continue
+			}
+
+			Expect(string(record.Block)).To(ContainSubstring("Content-Encoding: gzip\r\n"))
+			Expect(string(record.Block)).ToNot(ContainSubstring("lorem ipsum"))
+			break
+		}
+
+		url, _ := u.Parse(ts.URL + "/")
+		c := GenerateCrawler(url)
+		Expect(c.Replay(file)).To(Succeed())
+
+		replayed := c.Assets[ts.URL+"/"]
+		Expect(replayed.Encoding).To(Equal("gzip"))
+		Expect(replayed.TransferSize).To(Equal(home.TransferSize))
+		Expect(replayed.ContentHash).To(Equal(home.ContentHash))
+		Expect(replayed.Metadata.Title).To(Equal("Compressed"))
+	})
+
+	It("Should not archive the headers of subresources", func() {
+		url, _ := u.Parse(ts.URL + "/")
+		config := DefaultConfig()
+		config.WARCDir = dir
+		config.HeadAssets = true
+
+		original := GenerateCrawlerWithConfig(url, config)
+		Expect(original.Run(10)).To(Succeed())
+		Expect(original.Assets[ts.URL+"/logo.png"].StatusCode).To(Equal(http.StatusOK))
+		Expect(original.Assets[ts.URL+"/logo.png"].WARCRecordID).To(BeEmpty())
+
+		files, err := os.ReadDir(dir)
+		Expect(err).ToNot(HaveOccurred())
+
+		file, err := os.Open(dir + "/" + files[0].Name())
+		Expect(err).ToNot(HaveOccurred())
+		defer file.Close()
+
+		fetcher, err := LoadReplayFetcher(file)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(fetcher.URLs()).ToNot(ContainElement(ts.URL + "/logo.png"))
+	})
+
+	It("Should not replay a crawl that has started", func() {
+		url, _ := u.Parse(ts.URL + "/")
+		c := GenerateCrawler(url)
+		Expect(c.Run(10)).To(Succeed())
+		Expect(c.Replay()).ToNot(Succeed())
+	})
+})
@@ -0,0 +1,84 @@
+package crawler
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// maxSitemapDepth limits how deeply sitemap indexes may nest
+const maxSitemapDepth = 3
+
+// MaxSitemapSize is the largest uncompressed sitemap the protocol allows
+// Sitemaps are read up to this size even if MaxBodySize is smaller
+const MaxSitemapSize = 50 << 20
+
+type sitemapLocation struct {
+	Loc string `xml:"loc"`
+}
+
+// sitemapDocument covers both <urlset> sitemaps and <sitemapindex> indexes
+type sitemapDocument struct {
+	URLs     []sitemapLocation `xml:"url"`
+	Sitemaps []sitemapLocation `xml:"sitemap"`
+}
+
+// LoadSitemap fetches the sitemap or sitemap index at urlStr
+// Every internal page it lists is marked InSitemap and queued for crawling
+func (c *Crawler) LoadSitemap(urlStr string) error {
+	return c.loadSitemap(urlStr, 0)
+}
+
+func (c *Crawler) loadSitemap(urlStr string, depth int) error {
+	if depth > maxSitemapDepth {
+		return fmt.Errorf("Sitemap index nested too deeply at %s", urlStr)
+	}
+
+	resp, err := c.fetchMethod(http.MethodGet, urlStr, MaxSitemapSize)
+
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Unable to fetch sitemap %s: %d %s", urlStr, resp.StatusCode, http.StatusText(resp.StatusCode))
+	}
+
+	var doc sitemapDocument
+
+	err = xml.Unmarshal(resp.Body, &doc)
+	if err != nil {
+		return fmt.Errorf("Unable to parse sitemap %s: %v", urlStr, err)
+	}
+
+	for _, sitemap := range doc.Sitemaps {
+		err = c.loadSitemap(strings.TrimSpace(sitemap.Loc), depth+1)
+		if err != nil {
+			return err
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, entry := range doc.URLs {
+		url, external, err := ResolveURL(strings.TrimSpace(entry.Loc), c.baseURL)
+		if err != nil || external {
+			continue
+		}
+
+		asset, ok := c.Assets[url]
+
+		if !ok {
+			// Sitemaps are effectively linked from the site root
+			asset = GenerateAsset(url, PAGE)
+			asset.Depth = 1
+			c.storeAsset(asset)
+		}
+
+		asset.InSitemap = true
+	}
+
+	return nil
+}
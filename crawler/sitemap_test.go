@@ -0,0 +1,97 @@
+package crawler_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	u "net/url"
+
+	. "github.com/kevinoconnor7/digitalocean-crawler/crawler"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Sitemap", func() {
+	var (
+		c  *Crawler
+		ts *httptest.Server
+	)
+
+	BeforeEach(func() {
+		mux := http.NewServeMux()
+		mux.HandleFunc("/sitemap_index.xml", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?>
+<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+	<sitemap><loc>%s/sitemap.xml</loc></sitemap>
+</sitemapindex>`, "http://"+r.Host)
+		})
+		mux.HandleFunc("/sitemap.xml", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+	<url><loc>%[1]s/</loc></url>
+	<url><loc>%[1]s/listed</loc></url>
+	<url><loc>http://elsewhere.faketld/</loc></url>
+</urlset>`, "http://"+r.Host)
+		})
+		mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, "test")
+		})
+		ts = httptest.NewServer(mux)
+
+		url, _ := u.Parse(ts.URL)
+		c = GenerateCrawler(url)
+		c.StoreAsset(GenerateAsset(ts.URL+"/", PAGE))
+	})
+
+	AfterEach(func() {
+		ts.Close()
+	})
+
+	It("Should queue internal pages from the sitemap", func() {
+		Expect(c.LoadSitemap(ts.URL + "/sitemap.xml")).To(Succeed())
+		Expect(len(c.Assets)).To(Equal(2))
+		Expect(c.Assets[ts.URL+"/listed"].InSitemap).To(BeTrue())
+		Expect(c.GetQueue().Length).To(Equal(2))
+	})
+
+	It("Should mark known pages as listed", func() {
+		Expect(c.LoadSitemap(ts.URL + "/sitemap.xml")).To(Succeed())
+		Expect(c.Assets[ts.URL+"/"].InSitemap).To(BeTrue())
+	})
+
+	It("Should follow sitemap indexes", func() {
+		Expect(c.LoadSitemap(ts.URL + "/sitemap_index.xml")).To(Succeed())
+		Expect(c.Assets[ts.URL+"/listed"]).ToNot(BeNil())
+	})
+
+	It("Should read sitemaps larger than the maximum body size", func() {
+		ts.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+	<url><loc>%s/listed</loc></url>
+</urlset>`, "http://"+r.Host)
+		})
+
+		url, _ := u.Parse(ts.URL)
+		config := DefaultConfig()
+		config.MaxBodySize = 100
+
+		c = GenerateCrawlerWithConfig(url, config)
+		Expect(c.LoadSitemap(ts.URL + "/sitemap.xml")).To(Succeed())
+		Expect(c.Assets[ts.URL+"/listed"].InSitemap).To(BeTrue())
+	})
+
+	It("Should error for missing sitemaps", func() {
+		ts.Config.Handler = http.NotFoundHandler()
+		Expect(c.LoadSitemap(ts.URL + "/sitemap.xml")).ToNot(Succeed())
+	})
+
+	It("Should be loaded by Run when configured", func() {
+		url, _ := u.Parse(ts.URL + "/")
+		c = GenerateCrawlerWithConfig(url, Config{SitemapURL: ts.URL + "/sitemap.xml"})
+		Expect(c.Run(5)).To(Succeed())
+		Expect(c.Assets[ts.URL+"/listed"].InSitemap).To(BeTrue())
+		Expect(c.AssetsArray[0].URL).To(Equal(ts.URL + "/"))
+	})
+})
@@ -0,0 +1,57 @@
+package crawler
+
+// Stats summarizes the progress of a crawl
+type Stats struct {
+	State     State               `json:"state"`
+	Assets    int                 `json:"assets"`
+	Links     int                 `json:"links"`
+	Processed int                 `json:"processed"`
+	Queued    int                 `json:"queued"`
+	ByType    map[ContentType]int `json:"byType"`
+}
+
+// Snapshot is an immutable copy of the crawl graph at a point in time
+type Snapshot struct {
+	Nodes []Asset `json:"nodes"`
+	Links []Link  `json:"links"`
+	Stats Stats   `json:"stats"`
+
+	Duplicates []DuplicateCluster `json:"duplicates,omitempty"`
+}
+
+// Snapshot copies the current assets, links and stats of the crawl
+// The Findings, AuditValues, Metadata and Fields of its nodes are shared
+// with the Crawler, which replaces them rather than modifying them, so they
+// mustn't be modified either
+func (c *Crawler) Snapshot() *Snapshot {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	s := &Snapshot{
+		Nodes: make([]Asset, len(c.AssetsArray)),
+		Links: make([]Link, len(c.Links)),
+		Stats: Stats{
+			State:  c.state,
+			Assets: len(c.AssetsArray),
+			Links:  len(c.Links),
+			Queued: c.toProcess.Length,
+			ByType: make(map[ContentType]int),
+		},
+	}
+
+	for i, asset := range c.AssetsArray {
+		s.Nodes[i] = *asset
+		s.Nodes[i].Links = nil
+		s.Stats.ByType[asset.Type]++
+
+		if asset.processed {
+			s.Stats.Processed++
+		}
+	}
+
+	for i, link := range c.Links {
+		s.Links[i] = *link
+	}
+
+	return s
+}
@@ -0,0 +1,69 @@
+package crawler_test
+
+import (
+	"encoding/json"
+
+	. "github.com/kevinoconnor7/digitalocean-crawler/crawler"
+
+	u "net/url"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Snapshot", func() {
+	var (
+		c     *Crawler
+		asset *Asset
+	)
+
+	BeforeEach(func() {
+		url, _ := u.Parse("http://foo.faketld")
+		c = GenerateCrawler(url)
+		asset = GenerateAsset("http://foo.faketld", PAGE)
+		c.StoreAsset(asset)
+		c.HandleURL("/subpage", asset, PAGE)
+		c.HandleURL("/main.js", asset, SCRIPTS)
+	})
+
+	It("Should copy every node and link", func() {
+		s := c.Snapshot()
+		Expect(len(s.Nodes)).To(Equal(3))
+		Expect(len(s.Links)).To(Equal(2))
+		Expect(s.Nodes[0].URL).To(Equal("http://foo.faketld"))
+	})
+
+	It("Should not share state with the crawler", func() {
+		s := c.Snapshot()
+		c.HandleURL("/other", asset, PAGE)
+		c.Links[0].Value = 5
+
+		Expect(len(s.Nodes)).To(Equal(3))
+		Expect(len(s.Links)).To(Equal(2))
+		Expect(s.Links[0].Value).To(Equal(1))
+		Expect(s.Nodes[0].Links).To(BeNil())
+	})
+
+	It("Should compute stats", func() {
+		s := c.Snapshot()
+		Expect(s.Stats.Assets).To(Equal(3))
+		Expect(s.Stats.Links).To(Equal(2))
+		Expect(s.Stats.Queued).To(Equal(3))
+		Expect(s.Stats.Processed).To(Equal(0))
+		Expect(s.Stats.ByType[PAGE]).To(Equal(2))
+		Expect(s.Stats.ByType[SCRIPTS]).To(Equal(1))
+	})
+
+	Describe("OutputResults", func() {
+		It("Should serialize the snapshot", func() {
+			results, err := c.OutputResults()
+			Expect(err).ToNot(HaveOccurred())
+
+			var s Snapshot
+			Expect(json.Unmarshal([]byte(results), &s)).To(Succeed())
+			Expect(len(s.Nodes)).To(Equal(3))
+			Expect(len(s.Links)).To(Equal(2))
+			Expect(s.Stats.Assets).To(Equal(3))
+		})
+	})
+})
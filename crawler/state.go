@@ -0,0 +1,153 @@
+package crawler
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// State defines an enum for the lifecycle of a crawl
+type State int
+
+const (
+	PENDING State = 1 + iota
+	RUNNING
+	PAUSED
+	CANCELLED
+	FINISHED
+	FAILED
+)
+
+var stateNames = map[State]string{
+	PENDING:   "pending",
+	RUNNING:   "running",
+	PAUSED:    "paused",
+	CANCELLED: "cancelled",
+	FINISHED:  "finished",
+	FAILED:    "failed",
+}
+
+func (s State) String() string {
+	return stateNames[s]
+}
+
+// MarshalJSON encodes the State by name
+func (s State) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.String())
+}
+
+// UnmarshalJSON decodes a State from its name
+func (s *State) UnmarshalJSON(data []byte) error {
+	var name string
+
+	err := json.Unmarshal(data, &name)
+	if err != nil {
+		return err
+	}
+
+	for state, stateName := range stateNames {
+		if stateName == name {
+			*s = state
+			return nil
+		}
+	}
+
+	return fmt.Errorf("Unknown crawl state %q", name)
+}
+
+// Stopped reports whether the crawl can no longer make progress
+func (s State) Stopped() bool {
+	return s == CANCELLED || s == FINISHED || s == FAILED
+}
+
+// State is an accessor for the current lifecycle state of the crawl
+func (c *Crawler) State() State {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.state
+}
+
+// Err returns the error that caused the crawl to fail, if any
+func (c *Crawler) Err() error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.err
+}
+
+// Pause stops the crawl from fetching further assets until resumed
+// Fetches already in flight are allowed to complete
+func (c *Crawler) Pause() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.state != RUNNING {
+		return fmt.Errorf("Crawl is %s, not running", c.state)
+	}
+
+	c.state = PAUSED
+
+	return nil
+}
+
+// Resume continues a paused crawl
+func (c *Crawler) Resume() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.state != PAUSED {
+		return fmt.Errorf("Crawl is %s, not paused", c.state)
+	}
+
+	c.state = RUNNING
+	c.resumed.Broadcast()
+
+	return nil
+}
+
+// Cancel stops the crawl and aborts any fetch in flight
+func (c *Crawler) Cancel() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.state.Stopped() {
+		return fmt.Errorf("Crawl has already %s", c.state)
+	}
+
+	c.state = CANCELLED
+	c.cancel()
+	c.resumed.Broadcast()
+
+	return nil
+}
+
+// proceed blocks while the crawl is paused
+// Returns false once the crawl has been cancelled
+func (c *Crawler) proceed() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for c.state == PAUSED {
+		c.resumed.Wait()
+	}
+
+	return c.state != CANCELLED
+}
+
+// finish records the outcome of Run unless the crawl was cancelled
+func (c *Crawler) finish(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.state == CANCELLED {
+		return
+	}
+
+	if err != nil {
+		c.state = FAILED
+		c.err = err
+		return
+	}
+
+	c.state = FINISHED
+}
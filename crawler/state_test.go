@@ -0,0 +1,105 @@
+package crawler_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	u "net/url"
+
+	. "github.com/kevinoconnor7/digitalocean-crawler/crawler"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("State", func() {
+	var (
+		c       *Crawler
+		ts      *httptest.Server
+		hit     chan bool
+		release chan bool
+	)
+
+	BeforeEach(func() {
+		hit = make(chan bool, 1)
+		release = make(chan bool)
+		ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case hit <- true:
+			default:
+			}
+			<-release
+			fmt.Fprint(w, `<a href="/subpage"></a>`)
+		}))
+		url, _ := u.Parse(ts.URL)
+		c = GenerateCrawler(url)
+	})
+
+	AfterEach(func() {
+		ts.Close()
+	})
+
+	It("Should start pending", func() {
+		close(release)
+		Expect(c.State()).To(Equal(PENDING))
+		Expect(c.Pause()).ToNot(Succeed())
+		Expect(c.Resume()).ToNot(Succeed())
+	})
+
+	It("Should finish after running", func() {
+		close(release)
+		Expect(c.Run(5)).To(Succeed())
+		Expect(c.State()).To(Equal(FINISHED))
+		Expect(c.Run(5)).ToNot(Succeed())
+		Expect(c.Cancel()).ToNot(Succeed())
+	})
+
+	It("Should fail when the crawl errors", func() {
+		close(release)
+		url, _ := u.Parse("http://127.0.0.1:0")
+		c = GenerateCrawler(url)
+		Expect(c.Run(5)).ToNot(Succeed())
+		Expect(c.State()).To(Equal(FAILED))
+		Expect(c.Err()).To(HaveOccurred())
+	})
+
+	Context("While running", func() {
+		var done chan error
+
+		BeforeEach(func() {
+			done = make(chan error, 1)
+			go func() {
+				done <- c.Run(5)
+			}()
+			<-hit
+		})
+
+		It("Should pause and resume", func() {
+			Expect(c.Pause()).To(Succeed())
+			Expect(c.State()).To(Equal(PAUSED))
+			Expect(c.Pause()).ToNot(Succeed())
+
+			close(release)
+			Consistently(done).ShouldNot(Receive())
+			Expect(len(c.Snapshot().Nodes)).To(Equal(2))
+
+			Expect(c.Resume()).To(Succeed())
+			Eventually(done).Should(Receive(BeNil()))
+			Expect(c.State()).To(Equal(FINISHED))
+		})
+
+		It("Should cancel", func() {
+			Expect(c.Cancel()).To(Succeed())
+			Eventually(done).Should(Receive(BeNil()))
+			Expect(c.State()).To(Equal(CANCELLED))
+			close(release)
+		})
+	})
+
+	It("Should name states", func() {
+		close(release)
+		Expect(RUNNING.String()).To(Equal("running"))
+		Expect(CANCELLED.Stopped()).To(BeTrue())
+		Expect(PAUSED.Stopped()).To(BeFalse())
+	})
+})
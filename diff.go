@@ -0,0 +1,46 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/kevinoconnor7/digitalocean-crawler/report"
+)
+
+// diff compares two saved crawls of the same site
+func diff(args []string) error {
+	flags := flag.NewFlagSet("diff", flag.ExitOnError)
+	asJSON := flags.Bool("json", false, "Print the changes as JSON")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: diff [flags] before.json after.json")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() != 2 {
+		flags.Usage()
+		return fmt.Errorf("Expected two crawl files")
+	}
+
+	before, err := loadSnapshot(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	after, err := loadSnapshot(flags.Arg(1))
+	if err != nil {
+		return err
+	}
+
+	d := report.GenerateDiff(before, after)
+
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(d)
+	}
+
+	return d.WriteText(os.Stdout)
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/kevinoconnor7/digitalocean-crawler/report"
+)
+
+// export writes the custom fields extracted by a saved crawl
+func export(args []string) error {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	format := flags.String("format", "jsonl", "Output format, jsonl or csv")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: export [flags] crawl.json")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return fmt.Errorf("Expected a crawl file")
+	}
+
+	s, err := loadSnapshot(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	switch *format {
+	case "jsonl":
+		return report.WriteFieldsJSONL(os.Stdout, s)
+	case "csv":
+		return report.WriteFieldsCSV(os.Stdout, s)
+	}
+
+	return fmt.Errorf("Unknown format %q, expected jsonl or csv", *format)
+}
@@ -0,0 +1,179 @@
+package extract
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/andybalholm/cascadia"
+)
+
+// attrSuffix matches the @attribute that may end a field's selector
+var attrSuffix = regexp.MustCompile(`@([A-Za-z_:][-A-Za-z0-9_:.]*)$`)
+
+// Field extracts one named value from matching pages
+type Field struct {
+	Name     string
+	Selector string
+	Attr     string
+	Pages    *regexp.Regexp
+	matcher  cascadia.Selector
+}
+
+// GenerateField is a factory for a Field from a selector expression, which
+// is a CSS selector optionally followed by @attribute to read that
+// attribute instead of the element's text
+// Only pages with URLs matching pages are extracted from, unless it's empty
+func GenerateField(name string, expression string, pages string) (*Field, error) {
+	f := &Field{Name: name, Selector: strings.TrimSpace(expression)}
+
+	if match := attrSuffix.FindStringSubmatchIndex(f.Selector); match != nil {
+		f.Attr = f.Selector[match[2]:match[3]]
+		f.Selector = strings.TrimSpace(f.Selector[:match[0]])
+	}
+
+	matcher, err := cascadia.Compile(f.Selector)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid selector for field %s: %v", name, err)
+	}
+	f.matcher = matcher
+
+	if pages != "" {
+		f.Pages, err = regexp.Compile(pages)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid page pattern for field %s: %v", name, err)
+		}
+	}
+
+	return f, nil
+}
+
+// Extract reads the field from the first matching element of the document
+// Returns false if the element or attribute isn't there
+func (f *Field) Extract(doc *goquery.Document) (string, bool) {
+	s := doc.FindMatcher(f.matcher).First()
+	if s.Length() == 0 {
+		return "", false
+	}
+
+	if f.Attr != "" {
+		value, ok := s.Attr(f.Attr)
+		return strings.TrimSpace(value), ok
+	}
+
+	return clean(s.Text()), true
+}
+
+// Fields is a set of user defined fields to extract from pages
+type Fields struct {
+	Fields []*Field
+}
+
+// fieldsConfig is the JSON form of Fields, where each field is either a
+// selector expression or an object with its own page pattern
+type fieldsConfig struct {
+	Pages  string                     `json:"pages"`
+	Fields map[string]json.RawMessage `json:"fields"`
+}
+
+type fieldConfig struct {
+	Selector string `json:"selector"`
+	Pages    string `json:"pages"`
+}
+
+// LoadFields reads Fields from JSON such as
+//
+//	{
+//	  "pages": "/pricing",
+//	  "fields": {
+//	    "price": ".plan-price",
+//	    "author": {"selector": "meta[name=author]@content", "pages": "/blog/"}
+//	  }
+//	}
+//
+// where the top level pages pattern applies to fields without their own
+func LoadFields(r io.Reader) (*Fields, error) {
+	f := &Fields{}
+
+	err := json.NewDecoder(r).Decode(f)
+	if err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+// UnmarshalJSON decodes and validates Fields as read by LoadFields
+func (f *Fields) UnmarshalJSON(data []byte) error {
+	var config fieldsConfig
+
+	err := json.Unmarshal(data, &config)
+	if err != nil {
+		return err
+	}
+
+	var names []string
+	for name := range config.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	f.Fields = nil
+	for _, name := range names {
+		spec := fieldConfig{Pages: config.Pages}
+
+		if err := json.Unmarshal(config.Fields[name], &spec.Selector); err != nil {
+			err = json.Unmarshal(config.Fields[name], &spec)
+			if err != nil {
+				return fmt.Errorf("Field %s must be a selector or an object with one", name)
+			}
+		}
+
+		field, err := GenerateField(name, spec.Selector, spec.Pages)
+		if err != nil {
+			return err
+		}
+
+		f.Fields = append(f.Fields, field)
+	}
+
+	return nil
+}
+
+// Names lists the names of every field, in order
+func (f *Fields) Names() []string {
+	names := make([]string, len(f.Fields))
+	for i, field := range f.Fields {
+		names[i] = field.Name
+	}
+
+	return names
+}
+
+// Extract reads every field that applies to the page at url
+// Returns nil if none of the fields were found
+func (f *Fields) Extract(url string, doc *goquery.Document) map[string]string {
+	var values map[string]string
+
+	for _, field := range f.Fields {
+		if field.Pages != nil && !field.Pages.MatchString(url) {
+			continue
+		}
+
+		value, ok := field.Extract(doc)
+		if !ok {
+			continue
+		}
+
+		if values == nil {
+			values = make(map[string]string)
+		}
+		values[field.Name] = value
+	}
+
+	return values
+}
@@ -0,0 +1,95 @@
+package extract_test
+
+import (
+	"strings"
+
+	. "github.com/kevinoconnor7/digitalocean-crawler/extract"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Fields", func() {
+	html := `
+<head><meta name="author" content="Sammy"></head>
+<body>
+	<span class="plan-price"> $4 </span>
+	<span class="plan-price">$6</span>
+	<a href="mailto:sammy@foo.faketld">Email</a>
+</body>`
+
+	Describe("GenerateField", func() {
+		It("Should read the text of the first match", func() {
+			f, err := GenerateField("price", ".plan-price", "")
+			Expect(err).ToNot(HaveOccurred())
+
+			value, ok := f.Extract(parse(html))
+			Expect(ok).To(BeTrue())
+			Expect(value).To(Equal("$4"))
+		})
+
+		It("Should read an attribute after @", func() {
+			f, err := GenerateField("author", "meta[name=author]@content", "")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(f.Selector).To(Equal("meta[name=author]"))
+			Expect(f.Attr).To(Equal("content"))
+
+			value, _ := f.Extract(parse(html))
+			Expect(value).To(Equal("Sammy"))
+		})
+
+		It("Should not mistake @ inside a selector for an attribute", func() {
+			f, err := GenerateField("email", `a[href^="mailto:sammy@"]`, "")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(f.Attr).To(Equal(""))
+
+			value, _ := f.Extract(parse(`<a href="mailto:sammy@foo.faketld">Email</a>`))
+			Expect(value).To(Equal("Email"))
+		})
+
+		It("Should report missing elements and attributes", func() {
+			f, _ := GenerateField("missing", ".nothing", "")
+			_, ok := f.Extract(parse(html))
+			Expect(ok).To(BeFalse())
+
+			f, _ = GenerateField("missing", "meta[name=author]@lang", "")
+			_, ok = f.Extract(parse(html))
+			Expect(ok).To(BeFalse())
+		})
+
+		It("Should reject invalid selectors and patterns", func() {
+			_, err := GenerateField("bad", "div[", "")
+			Expect(err).To(HaveOccurred())
+
+			_, err = GenerateField("bad", "div", "(")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("LoadFields", func() {
+		It("Should read selectors and per field page patterns", func() {
+			f, err := LoadFields(strings.NewReader(`{
+				"pages": "/pricing",
+				"fields": {
+					"price": ".plan-price",
+					"author": {"selector": "meta[name=author]@content", "pages": "/blog/"}
+				}
+			}`))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(f.Names()).To(Equal([]string{"author", "price"}))
+
+			doc := parse(html)
+			Expect(f.Extract("http://foo.faketld/pricing", doc)).To(Equal(map[string]string{"price": "$4"}))
+			Expect(f.Extract("http://foo.faketld/blog/post", doc)).To(Equal(map[string]string{"author": "Sammy"}))
+			Expect(f.Extract("http://foo.faketld/", doc)).To(BeNil())
+		})
+
+		It("Should reject invalid fields", func() {
+			_, err := LoadFields(strings.NewReader(`{"fields": {"price": 4}}`))
+			Expect(err).To(HaveOccurred())
+
+			_, err = LoadFields(strings.NewReader(`{"fields": {"price": "div["}}`))
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,107 @@
+package extract
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Heading is an entry in a page's outline
+type Heading struct {
+	Level int    `json:"level"`
+	Text  string `json:"text"`
+}
+
+// Alternate is a translation of a page declared with hreflang
+type Alternate struct {
+	Lang string `json:"lang"`
+	URL  string `json:"url"`
+}
+
+// Metadata describes the content of a page
+type Metadata struct {
+	Title       string            `json:"title,omitempty"`
+	Description string            `json:"description,omitempty"`
+	Headings    []Heading         `json:"headings,omitempty"`
+	OpenGraph   map[string]string `json:"openGraph,omitempty"`
+	Twitter     map[string]string `json:"twitter,omitempty"`
+	Alternates  []Alternate       `json:"alternates,omitempty"`
+	JSONLD      []json.RawMessage `json:"jsonLD,omitempty"`
+	Microdata   []*Item           `json:"microdata,omitempty"`
+}
+
+// Extract reads the metadata of an HTML document
+func Extract(doc *goquery.Document) *Metadata {
+	m := &Metadata{
+		Title: clean(doc.Find("head title").First().Text()),
+	}
+
+	doc.Find("meta").Each(func(i int, s *goquery.Selection) {
+		content, ok := s.Attr("content")
+		if !ok {
+			return
+		}
+
+		// Open Graph uses property, though name is common in the wild
+		key, _ := s.Attr("property")
+		if key == "" {
+			key, _ = s.Attr("name")
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+
+		switch {
+		case key == "description" && m.Description == "":
+			m.Description = clean(content)
+		case strings.HasPrefix(key, "og:"):
+			m.OpenGraph = set(m.OpenGraph, strings.TrimPrefix(key, "og:"), content)
+		case strings.HasPrefix(key, "twitter:"):
+			m.Twitter = set(m.Twitter, strings.TrimPrefix(key, "twitter:"), content)
+		}
+	})
+
+	doc.Find("h1, h2, h3, h4, h5, h6").Each(func(i int, s *goquery.Selection) {
+		m.Headings = append(m.Headings, Heading{
+			Level: int(goquery.NodeName(s)[1] - '0'),
+			Text:  clean(s.Text()),
+		})
+	})
+
+	doc.Find("link[rel~=alternate][hreflang]").Each(func(i int, s *goquery.Selection) {
+		lang, _ := s.Attr("hreflang")
+		href, _ := s.Attr("href")
+		m.Alternates = append(m.Alternates, Alternate{Lang: lang, URL: href})
+	})
+
+	doc.Find(`script[type="application/ld+json"]`).Each(func(i int, s *goquery.Selection) {
+		data := strings.TrimSpace(s.Text())
+
+		// Invalid JSON can't be embedded in the output, so skip it
+		if json.Valid([]byte(data)) {
+			m.JSONLD = append(m.JSONLD, json.RawMessage(data))
+		}
+	})
+
+	m.Microdata = Microdata(doc)
+
+	return m
+}
+
+// set adds key to values, creating the map if needed
+// The first value for a key wins
+func set(values map[string]string, key string, value string) map[string]string {
+	if values == nil {
+		values = make(map[string]string)
+	}
+
+	if _, ok := values[key]; !ok {
+		values[key] = strings.TrimSpace(value)
+	}
+
+	return values
+}
+
+// clean collapses the whitespace in text
+func clean(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
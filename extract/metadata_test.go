@@ -0,0 +1,84 @@
+package extract_test
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/PuerkitoBio/goquery"
+	. "github.com/kevinoconnor7/digitalocean-crawler/extract"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// parse reads html into a document
+func parse(html string) *goquery.Document {
+	doc, _ := goquery.NewDocumentFromReader(bytes.NewBufferString(html))
+	return doc
+}
+
+var _ = Describe("Metadata", func() {
+	var m *Metadata
+
+	BeforeEach(func() {
+		m = Extract(parse(`
+<html>
+<head>
+	<title>
+		Cloud   Hosting
+	</title>
+	<meta name="description" content="Simple cloud hosting">
+	<meta property="og:title" content="Cloud Hosting">
+	<meta property="og:image" content="https://foo.faketld/og.png">
+	<meta name="twitter:card" content="summary">
+	<link rel="alternate" hreflang="de" href="https://foo.faketld/de/">
+	<link rel="alternate" type="application/rss+xml" href="/feed">
+	<script type="application/ld+json">{"@type": "Organization", "name": "Foo"}</script>
+	<script type="application/ld+json">{not json</script>
+</head>
+<body>
+	<h1>Cloud <em>Hosting</em></h1>
+	<h2>Pricing</h2>
+	<h3>Droplets</h3>
+</body>
+</html>`))
+	})
+
+	It("Should extract the title and description", func() {
+		Expect(m.Title).To(Equal("Cloud Hosting"))
+		Expect(m.Description).To(Equal("Simple cloud hosting"))
+	})
+
+	It("Should outline the headings", func() {
+		Expect(m.Headings).To(Equal([]Heading{
+			{Level: 1, Text: "Cloud Hosting"},
+			{Level: 2, Text: "Pricing"},
+			{Level: 3, Text: "Droplets"},
+		}))
+	})
+
+	It("Should extract Open Graph and Twitter tags", func() {
+		Expect(m.OpenGraph).To(Equal(map[string]string{
+			"title": "Cloud Hosting",
+			"image": "https://foo.faketld/og.png",
+		}))
+		Expect(m.Twitter).To(Equal(map[string]string{"card": "summary"}))
+	})
+
+	It("Should extract hreflang alternates", func() {
+		Expect(m.Alternates).To(Equal([]Alternate{{Lang: "de", URL: "https://foo.faketld/de/"}}))
+	})
+
+	It("Should keep valid JSON-LD", func() {
+		Expect(m.JSONLD).To(HaveLen(1))
+
+		var data map[string]string
+		Expect(json.Unmarshal(m.JSONLD[0], &data)).To(Succeed())
+		Expect(data["name"]).To(Equal("Foo"))
+	})
+
+	It("Should leave out what the page doesn't have", func() {
+		out, _ := json.Marshal(Extract(parse("<p>Nothing here</p>")))
+		Expect(string(out)).To(Equal("{}"))
+	})
+})
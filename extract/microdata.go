@@ -0,0 +1,106 @@
+package extract
+
+import (
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Item is a microdata item, with properties that are strings or nested
+// items
+type Item struct {
+	Type       []string                 `json:"type,omitempty"`
+	ID         string                   `json:"id,omitempty"`
+	Properties map[string][]interface{} `json:"properties"`
+}
+
+// urlAttributes names the attribute holding the value of elements whose
+// microdata value is a URL
+var urlAttributes = map[string]string{
+	"a":      "href",
+	"area":   "href",
+	"link":   "href",
+	"audio":  "src",
+	"embed":  "src",
+	"iframe": "src",
+	"img":    "src",
+	"source": "src",
+	"track":  "src",
+	"video":  "src",
+	"object": "data",
+}
+
+// Microdata returns the top level microdata items of the document
+func Microdata(doc *goquery.Document) []*Item {
+	var items []*Item
+
+	doc.Find("[itemscope]").Each(func(i int, s *goquery.Selection) {
+		if _, ok := s.Attr("itemprop"); ok {
+			return
+		}
+
+		items = append(items, readItem(s))
+	})
+
+	return items
+}
+
+func readItem(s *goquery.Selection) *Item {
+	item := &Item{Properties: make(map[string][]interface{})}
+
+	itemType, _ := s.Attr("itemtype")
+	item.Type = strings.Fields(itemType)
+	item.ID, _ = s.Attr("itemid")
+
+	var walk func(parent *goquery.Selection)
+	walk = func(parent *goquery.Selection) {
+		parent.Children().Each(func(i int, child *goquery.Selection) {
+			_, scoped := child.Attr("itemscope")
+
+			if names, ok := child.Attr("itemprop"); ok {
+				var value interface{}
+				if scoped {
+					value = readItem(child)
+				} else {
+					value = propertyValue(child)
+				}
+
+				for _, name := range strings.Fields(names) {
+					item.Properties[name] = append(item.Properties[name], value)
+				}
+			}
+
+			// Properties inside a nested item belong to that item
+			if !scoped {
+				walk(child)
+			}
+		})
+	}
+	walk(s)
+
+	return item
+}
+
+func propertyValue(s *goquery.Selection) string {
+	name := goquery.NodeName(s)
+
+	if attr, ok := urlAttributes[name]; ok {
+		value, _ := s.Attr(attr)
+		return value
+	}
+
+	switch name {
+	case "meta":
+		value, _ := s.Attr("content")
+		return value
+	case "data", "meter":
+		value, _ := s.Attr("value")
+		return value
+	case "time":
+		if value, ok := s.Attr("datetime"); ok {
+			return value
+		}
+	}
+
+	return clean(s.Text())
+}
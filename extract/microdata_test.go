@@ -0,0 +1,40 @@
+package extract_test
+
+import (
+	. "github.com/kevinoconnor7/digitalocean-crawler/extract"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Microdata", func() {
+	It("Should read nested items", func() {
+		items := Microdata(parse(`
+<div itemscope itemtype="https://schema.org/Product">
+	<h1 itemprop="name">Droplet</h1>
+	<img itemprop="image" src="/droplet.png">
+	<div itemprop="offers" itemscope itemtype="https://schema.org/Offer">
+		<meta itemprop="priceCurrency" content="USD">
+		<span itemprop="price">4.00</span>
+	</div>
+	<p>Released <time itemprop="releaseDate" datetime="2012-01-01">in 2012</time></p>
+</div>`))
+
+		Expect(items).To(HaveLen(1))
+		product := items[0]
+		Expect(product.Type).To(Equal([]string{"https://schema.org/Product"}))
+		Expect(product.Properties["name"]).To(Equal([]interface{}{"Droplet"}))
+		Expect(product.Properties["image"]).To(Equal([]interface{}{"/droplet.png"}))
+		Expect(product.Properties["releaseDate"]).To(Equal([]interface{}{"2012-01-01"}))
+		Expect(product.Properties).ToNot(HaveKey("price"))
+
+		offer := product.Properties["offers"][0].(*Item)
+		Expect(offer.Properties["priceCurrency"]).To(Equal([]interface{}{"USD"}))
+		Expect(offer.Properties["price"]).To(Equal([]interface{}{"4.00"}))
+	})
+
+	It("Should list every top level item", func() {
+		items := Microdata(parse(`<div itemscope></div><div itemscope><span itemprop="a" itemscope></span></div>`))
+		Expect(items).To(HaveLen(2))
+	})
+})
@@ -0,0 +1,355 @@
+package graph
+
+import "math"
+
+const (
+	// DefaultDamping is the usual PageRank damping factor
+	DefaultDamping = 0.85
+
+	// pageRankTolerance is the total change in rank below which PageRank
+	// is considered converged
+	pageRankTolerance = 1e-9
+	maxIterations     = 100
+)
+
+// Graph is a directed graph over the nodes 0..n-1
+type Graph struct {
+	out [][]int
+	in  [][]int
+}
+
+// Metrics holds the analysis results for a single node
+type Metrics struct {
+	PageRank      float64 `json:"pageRank"`
+	InDegree      int     `json:"inDegree"`
+	OutDegree     int     `json:"outDegree"`
+	Depth         int     `json:"depth"`
+	Component     int     `json:"component"`
+	ComponentSize int     `json:"componentSize"`
+	DeadEnd       bool    `json:"deadEnd,omitempty"`
+	Orphan        bool    `json:"orphan,omitempty"`
+}
+
+// GenerateGraph is a factory for a Graph of n nodes without edges
+func GenerateGraph(n int) *Graph {
+	return &Graph{
+		out: make([][]int, n),
+		in:  make([][]int, n),
+	}
+}
+
+// Len returns the number of nodes in the graph
+func (g *Graph) Len() int {
+	return len(g.out)
+}
+
+// AddEdge adds a directed edge from source to target
+func (g *Graph) AddEdge(source int, target int) {
+	g.out[source] = append(g.out[source], target)
+	g.in[target] = append(g.in[target], source)
+}
+
+// Outbound returns the targets of the node's edges
+func (g *Graph) Outbound(node int) []int {
+	return g.out[node]
+}
+
+// Inbound returns the sources of edges pointing at the node
+func (g *Graph) Inbound(node int) []int {
+	return g.in[node]
+}
+
+// InDegree counts the edges pointing at the node
+func (g *Graph) InDegree(node int) int {
+	return len(g.in[node])
+}
+
+// OutDegree counts the edges leaving the node
+func (g *Graph) OutDegree(node int) int {
+	return len(g.out[node])
+}
+
+// PageRank computes the rank of every node by power iteration
+// Rank held by nodes without outbound edges is spread evenly over the graph
+func (g *Graph) PageRank(damping float64) []float64 {
+	n := g.Len()
+	if n == 0 {
+		return []float64{}
+	}
+
+	rank := make([]float64, n)
+	for i := range rank {
+		rank[i] = 1 / float64(n)
+	}
+
+	next := make([]float64, n)
+	for iteration := 0; iteration < maxIterations; iteration++ {
+		dangling := 0.0
+		for i, targets := range g.out {
+			if len(targets) == 0 {
+				dangling += rank[i]
+			}
+		}
+
+		base := (1-damping)/float64(n) + damping*dangling/float64(n)
+		for i := range next {
+			next[i] = base
+		}
+
+		for i, targets := range g.out {
+			share := damping * rank[i] / float64(len(targets))
+			for _, target := range targets {
+				next[target] += share
+			}
+		}
+
+		delta := 0.0
+		for i := range rank {
+			delta += math.Abs(next[i] - rank[i])
+		}
+
+		rank, next = next, rank
+
+		if delta < pageRankTolerance {
+			break
+		}
+	}
+
+	return rank
+}
+
+// Depths returns the fewest edges needed to reach each node from source
+// Unreachable nodes have a depth of -1
+func (g *Graph) Depths(source int) []int {
+	depths := make([]int, g.Len())
+	for i := range depths {
+		depths[i] = -1
+	}
+
+	if source < 0 || source >= g.Len() {
+		return depths
+	}
+
+	depths[source] = 0
+	queue := []int{source}
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+
+		for _, target := range g.out[node] {
+			if depths[target] == -1 {
+				depths[target] = depths[node] + 1
+				queue = append(queue, target)
+			}
+		}
+	}
+
+	return depths
+}
+
+// StronglyConnectedComponents groups nodes that can all reach each other
+// Components are returned in reverse topological order
+func (g *Graph) StronglyConnectedComponents() [][]int {
+	n := g.Len()
+	index := make([]int, n)
+	lowlink := make([]int, n)
+	onStack := make([]bool, n)
+	for i := range index {
+		index[i] = -1
+	}
+
+	var (
+		components [][]int
+		stack      []int
+		counter    int
+	)
+
+	// Tarjan's algorithm, iteratively so large crawls can't overflow the
+	// goroutine stack
+	type frame struct {
+		node int
+		edge int
+	}
+
+	for root := 0; root < n; root++ {
+		if index[root] != -1 {
+			continue
+		}
+
+		calls := []frame{{node: root}}
+		index[root] = counter
+		lowlink[root] = counter
+		counter++
+		stack = append(stack, root)
+		onStack[root] = true
+
+		for len(calls) > 0 {
+			top := &calls[len(calls)-1]
+			node := top.node
+
+			if top.edge < len(g.out[node]) {
+				target := g.out[node][top.edge]
+				top.edge++
+
+				if index[target] == -1 {
+					index[target] = counter
+					lowlink[target] = counter
+					counter++
+					stack = append(stack, target)
+					onStack[target] = true
+					calls = append(calls, frame{node: target})
+				} else if onStack[target] && index[target] < lowlink[node] {
+					lowlink[node] = index[target]
+				}
+				continue
+			}
+
+			if lowlink[node] == index[node] {
+				var component []int
+				for {
+					member := stack[len(stack)-1]
+					stack = stack[:len(stack)-1]
+					onStack[member] = false
+					component = append(component, member)
+					if member == node {
+						break
+					}
+				}
+				components = append(components, component)
+			}
+
+			calls = calls[:len(calls)-1]
+			if len(calls) > 0 {
+				parent := calls[len(calls)-1].node
+				if lowlink[node] < lowlink[parent] {
+					lowlink[parent] = lowlink[node]
+				}
+			}
+		}
+	}
+
+	return components
+}
+
+// DeadEnds returns every node without outbound edges
+func (g *Graph) DeadEnds() []int {
+	nodes := []int{}
+
+	for i, targets := range g.out {
+		if len(targets) == 0 {
+			nodes = append(nodes, i)
+		}
+	}
+
+	return nodes
+}
+
+// Orphans returns the known nodes that nothing links to, other than root
+func (g *Graph) Orphans(known []int, root int) []int {
+	nodes := []int{}
+
+	for _, node := range known {
+		if node != root && len(g.in[node]) == 0 {
+			nodes = append(nodes, node)
+		}
+	}
+
+	return nodes
+}
+
+// Analyze computes Metrics for every node, measuring depth from root
+// Orphans are only reported among the known nodes
+func (g *Graph) Analyze(root int, known []int) []Metrics {
+	metrics := make([]Metrics, g.Len())
+	rank := g.PageRank(DefaultDamping)
+	depths := g.Depths(root)
+
+	for i := range metrics {
+		metrics[i] = Metrics{
+			PageRank:  rank[i],
+			InDegree:  g.InDegree(i),
+			OutDegree: g.OutDegree(i),
+			Depth:     depths[i],
+			DeadEnd:   g.OutDegree(i) == 0,
+		}
+	}
+
+	for id, component := range g.StronglyConnectedComponents() {
+		for _, node := range component {
+			metrics[node].Component = id
+			metrics[node].ComponentSize = len(component)
+		}
+	}
+
+	for _, node := range g.Orphans(known, root) {
+		metrics[node].Orphan = true
+	}
+
+	return metrics
+}
+
+// ShortestPaths returns up to limit distinct shortest paths from source to
+// target, each listing the nodes visited in order
+// Returns no paths if target can't be reached
+func (g *Graph) ShortestPaths(source int, target int, limit int) [][]int {
+	paths := [][]int{}
+
+	if source < 0 || source >= g.Len() || target < 0 || target >= g.Len() || limit <= 0 {
+		return paths
+	}
+
+	// Breadth first search recording every predecessor on a shortest path
+	depths := make([]int, g.Len())
+	for i := range depths {
+		depths[i] = -1
+	}
+	predecessors := make([][]int, g.Len())
+
+	depths[source] = 0
+	queue := []int{source}
+	for len(queue) > 0 && depths[target] == -1 {
+		level := queue
+		queue = nil
+
+		for _, node := range level {
+			for _, next := range g.out[node] {
+				if depths[next] == -1 {
+					depths[next] = depths[node] + 1
+					queue = append(queue, next)
+				}
+				if depths[next] == depths[node]+1 {
+					predecessors[next] = append(predecessors[next], node)
+				}
+			}
+		}
+	}
+
+	if depths[target] == -1 {
+		return paths
+	}
+
+	// Walk predecessors back from the target to enumerate the paths
+	var walk func(node int, suffix []int)
+	walk = func(node int, suffix []int) {
+		if len(paths) >= limit {
+			return
+		}
+
+		suffix = append([]int{node}, suffix...)
+		if node == source {
+			paths = append(paths, suffix)
+			return
+		}
+
+		seen := make(map[int]bool)
+		for _, previous := range predecessors[node] {
+			if !seen[previous] {
+				seen[previous] = true
+				walk(previous, suffix)
+			}
+		}
+	}
+	walk(target, nil)
+
+	return paths
+}
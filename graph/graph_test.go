@@ -0,0 +1,166 @@
+package graph_test
+
+import (
+	"sort"
+
+	. "github.com/kevinoconnor7/digitalocean-crawler/graph"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Graph", func() {
+	var g *Graph
+
+	// 0 -> 1 -> 2 -> 0 forms a cycle, 2 -> 3 is a dead end and 4 links
+	// into the cycle without anything linking to it
+	BeforeEach(func() {
+		g = GenerateGraph(5)
+		g.AddEdge(0, 1)
+		g.AddEdge(1, 2)
+		g.AddEdge(2, 0)
+		g.AddEdge(2, 3)
+		g.AddEdge(4, 1)
+	})
+
+	Describe("Degrees", func() {
+		It("Should count inbound and outbound edges", func() {
+			Expect(g.InDegree(1)).To(Equal(2))
+			Expect(g.OutDegree(1)).To(Equal(1))
+			Expect(g.OutDegree(2)).To(Equal(2))
+			Expect(g.InDegree(4)).To(Equal(0))
+		})
+	})
+
+	Describe("PageRank", func() {
+		It("Should sum to one", func() {
+			total := 0.0
+			for _, rank := range g.PageRank(DefaultDamping) {
+				total += rank
+			}
+			Expect(total).To(BeNumerically("~", 1, 1e-6))
+		})
+
+		It("Should rank well linked nodes higher", func() {
+			rank := g.PageRank(DefaultDamping)
+			Expect(rank[1]).To(BeNumerically(">", rank[0]))
+			Expect(rank[1]).To(BeNumerically(">", rank[4]))
+		})
+
+		It("Should handle empty graphs", func() {
+			Expect(GenerateGraph(0).PageRank(DefaultDamping)).To(BeEmpty())
+		})
+
+		It("Should rank symmetric graphs evenly", func() {
+			cycle := GenerateGraph(3)
+			cycle.AddEdge(0, 1)
+			cycle.AddEdge(1, 2)
+			cycle.AddEdge(2, 0)
+			for _, rank := range cycle.PageRank(DefaultDamping) {
+				Expect(rank).To(BeNumerically("~", 1.0/3, 1e-6))
+			}
+		})
+	})
+
+	Describe("Depths", func() {
+		It("Should measure the shortest distance from the source", func() {
+			Expect(g.Depths(0)).To(Equal([]int{0, 1, 2, 3, -1}))
+		})
+
+		It("Should mark everything unreachable for invalid sources", func() {
+			Expect(g.Depths(9)).To(Equal([]int{-1, -1, -1, -1, -1}))
+		})
+	})
+
+	Describe("StronglyConnectedComponents", func() {
+		It("Should group mutually reachable nodes", func() {
+			components := g.StronglyConnectedComponents()
+			Expect(len(components)).To(Equal(3))
+
+			var sizes []int
+			for _, component := range components {
+				sizes = append(sizes, len(component))
+				if len(component) == 3 {
+					sort.Ints(component)
+					Expect(component).To(Equal([]int{0, 1, 2}))
+				}
+			}
+			sort.Ints(sizes)
+			Expect(sizes).To(Equal([]int{1, 1, 3}))
+		})
+
+		It("Should handle long chains without recursion", func() {
+			chain := GenerateGraph(100000)
+			for i := 0; i < 99999; i++ {
+				chain.AddEdge(i, i+1)
+			}
+			Expect(len(chain.StronglyConnectedComponents())).To(Equal(100000))
+		})
+	})
+
+	Describe("DeadEnds", func() {
+		It("Should list nodes without outbound edges", func() {
+			Expect(g.DeadEnds()).To(Equal([]int{3}))
+		})
+	})
+
+	Describe("Orphans", func() {
+		It("Should list known nodes without inbound edges", func() {
+			Expect(g.Orphans([]int{1, 3, 4}, 0)).To(Equal([]int{4}))
+		})
+
+		It("Should never report the root", func() {
+			Expect(g.Orphans([]int{4}, 4)).To(BeEmpty())
+		})
+	})
+
+	Describe("Analyze", func() {
+		It("Should combine every metric", func() {
+			metrics := g.Analyze(0, []int{4})
+			Expect(metrics[2].OutDegree).To(Equal(2))
+			Expect(metrics[2].Depth).To(Equal(2))
+			Expect(metrics[2].ComponentSize).To(Equal(3))
+			Expect(metrics[0].Component).To(Equal(metrics[1].Component))
+			Expect(metrics[3].DeadEnd).To(BeTrue())
+			Expect(metrics[4].Orphan).To(BeTrue())
+			Expect(metrics[4].Depth).To(Equal(-1))
+		})
+	})
+})
+
+var _ = Describe("ShortestPaths", func() {
+	var g *Graph
+
+	// Two equally short routes from 0 to 3, plus a longer one through 4
+	BeforeEach(func() {
+		g = GenerateGraph(6)
+		g.AddEdge(0, 1)
+		g.AddEdge(0, 2)
+		g.AddEdge(1, 3)
+		g.AddEdge(2, 3)
+		g.AddEdge(0, 4)
+		g.AddEdge(4, 5)
+		g.AddEdge(5, 3)
+	})
+
+	It("Should return every shortest path", func() {
+		paths := g.ShortestPaths(0, 3, 10)
+		Expect(paths).To(ConsistOf([]int{0, 1, 3}, []int{0, 2, 3}))
+	})
+
+	It("Should respect the limit", func() {
+		Expect(len(g.ShortestPaths(0, 3, 1))).To(Equal(1))
+	})
+
+	It("Should return a single node path to itself", func() {
+		Expect(g.ShortestPaths(0, 0, 10)).To(Equal([][]int{{0}}))
+	})
+
+	It("Should return nothing for unreachable targets", func() {
+		Expect(g.ShortestPaths(3, 0, 10)).To(BeEmpty())
+	})
+
+	It("Should return nothing for invalid nodes", func() {
+		Expect(g.ShortestPaths(0, 10, 10)).To(BeEmpty())
+	})
+})
@@ -1,28 +1,181 @@
 package main
 
 import (
+	"encoding/json"
 	"flag"
 	"fmt"
 	"net/http"
+	"os"
+	"strings"
 
 	"github.com/kevinoconnor7/digitalocean-crawler/crawler"
+	"github.com/kevinoconnor7/digitalocean-crawler/extract"
+	"github.com/kevinoconnor7/digitalocean-crawler/render"
+	"github.com/kevinoconnor7/digitalocean-crawler/server"
 )
 
+// commands maps each subcommand to its entry point
+var commands = map[string]func(args []string) error{
+	"serve":  serve,
+	"crawl":  crawl,
+	"path":   path,
+	"diff":   diff,
+	"report": auditReport,
+	"export": export,
+	"replay": replay,
+	"mirror": mirrorSite,
+
+	"compression": compression,
+	"weight":      weight,
+}
+
 func main() {
-	maxPages := flag.Int("maxPages", 10, "Max number of pages to crawl")
-	flag.Parse()
-	c, err := crawler.Crawl("https://www.digitalocean.com", *maxPages)
+	name, args := "serve", os.Args[1:]
+
+	// Running without a subcommand serves the web UI, as it always has
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	}
+
+	command, ok := commands[name]
 
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Unknown command %q, expected one of serve, crawl, replay, mirror, path, diff, report, export, compression or weight\n", name)
+		os.Exit(2)
+	}
+
+	err := command(args)
 	if err != nil {
-		panic(err)
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
 	}
+}
+
+// crawlFlags registers the flags shared by every command that crawls
+func crawlFlags(flags *flag.FlagSet) *server.CrawlOptions {
+	options := &server.CrawlOptions{}
+
+	flags.StringVar(&options.Seed, "seed", "https://www.digitalocean.com", "URL to start crawling from")
+	flags.IntVar(&options.MaxPages, "maxPages", 10, "Max number of pages to crawl")
+	flags.BoolVar(&options.TrackExternal, "external", false, "Record links to other hosts without crawling them")
+	flags.StringVar(&options.Sitemap, "sitemap", "", "Sitemap URL to seed the crawl and detect orphan pages with")
+	flags.BoolVar(&options.SkipMetadata, "skipMetadata", false, "Don't extract titles, headings and structured data from pages")
+	flags.BoolVar(&options.StreamLinks, "streamLinks", false, "Find links without building a DOM, skipping audits and extraction")
+	flags.BoolVar(&options.InferLinks, "inferLinks", false, "Also link to same-host URLs guessed from inline scripts, JSON-LD and data-* and on* attributes")
+	flags.BoolVar(&options.HeadAssets, "headAssets", false, "Size images, scripts and stylesheets with HEAD requests instead of downloading them")
+	flags.BoolVar(&options.Render, "render", false, "Render pages in a headless browser before finding their links")
+	flags.Func("renderPattern", "Regular expression selecting the URLs of pages to render, may be repeated, defaults to every page", func(expression string) error {
+		patterns, err := render.CompilePatterns(expression)
+		options.RenderPatterns = append(options.RenderPatterns, patterns...)
+		return err
+	})
+	flags.Int64Var(&options.MaxBodySize, "maxBodySize", crawler.DefaultMaxBodySize, "Bytes of each response to read at most")
+
+	client := crawler.DefaultHTTPConfig()
+	options.HTTP = &client
+
+	flags.DurationVar(&client.ConnectTimeout, "connectTimeout", client.ConnectTimeout, "Time allowed to connect to a server, 0 for no limit")
+	flags.DurationVar(&client.ReadTimeout, "readTimeout", client.ReadTimeout, "Time allowed for response headers after sending a request, 0 for no limit")
+	flags.DurationVar(&client.Timeout, "timeout", client.Timeout, "Time allowed for each request in total, 0 for no limit")
+	flags.StringVar(&client.Proxy, "proxy", "", "http, https or socks5 proxy URL, defaults to HTTP_PROXY and HTTPS_PROXY")
+	flags.StringVar(&client.CABundle, "caBundle", "", "PEM file of extra certificates to trust")
+	flags.BoolVar(&client.InsecureSkipVerify, "insecure", false, "Accept any TLS certificate")
+	flags.StringVar(&client.UserAgent, "userAgent", "", "User-Agent to send, defaults to "+crawler.DefaultUserAgent)
+	flags.BoolVar(&client.Cookies, "cookies", false, "Keep cookies set by the site between requests")
+	flags.BoolVar(&client.DisableHTTP2, "disableHTTP2", false, "Only use HTTP/1.1")
+	flags.Func("header", "Header to send with every request to the crawled site as 'Name: value', may be repeated, not sent by crawls started through the API", func(header string) error {
+		name, value, ok := strings.Cut(header, ":")
+		if !ok || strings.TrimSpace(name) == "" {
+			return fmt.Errorf("Header must be 'Name: value'")
+		}
 
-	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
-		http.ServeFile(w, r, "index.html")
+		if client.Headers == nil {
+			client.Headers = make(http.Header)
+		}
+		client.Headers.Add(strings.TrimSpace(name), strings.TrimSpace(value))
+		return nil
 	})
-	http.HandleFunc("/graph.json", func(w http.ResponseWriter, r *http.Request) {
-		fmt.Fprint(w, c.OutputResults())
+	flags.Func("auth", "JSON file of per-host credentials and a login form to submit before crawling", func(path string) error {
+		file, err := os.Open(path)
+
+		if err != nil {
+			return err
+		}
+
+		defer file.Close()
+
+		options.Auth = &crawler.AuthConfig{}
+		return json.NewDecoder(file).Decode(options.Auth)
 	})
-	fmt.Println("Starting web server on :8080")
-	panic(http.ListenAndServe(":8080", nil))
+	flags.Func("fields", "JSON file of custom fields to extract from pages", func(path string) error {
+		file, err := os.Open(path)
+
+		if err != nil {
+			return err
+		}
+
+		defer file.Close()
+
+		options.Fields, err = extract.LoadFields(file)
+		return err
+	})
+
+	return options
+}
+
+// renderFlags registers the flags choosing the browser pages are rendered in
+func renderFlags(flags *flag.FlagSet) *render.ChromeOptions {
+	chrome := render.DefaultChromeOptions()
+
+	flags.StringVar(&chrome.ExecPath, "chrome", "", "Chrome or Chromium binary to render pages with, found on the PATH by default")
+	flags.StringVar(&chrome.RemoteURL, "chromeURL", "", "DevTools URL of a running browser to render pages with instead of starting one")
+	flags.DurationVar(&chrome.Wait, "renderWait", chrome.Wait, "Time scripts are given to run after a page has loaded")
+	flags.DurationVar(&chrome.Timeout, "renderTimeout", chrome.Timeout, "Time allowed to render each page, 0 for no limit")
+
+	return &chrome
+}
+
+// startRenderer starts the browser if pages are to be rendered, returning
+// a function that stops it
+func startRenderer(options *server.CrawlOptions, chrome *render.ChromeOptions) (func(), error) {
+	if !options.Render {
+		return func() {}, nil
+	}
+
+	// Pages are rendered through the same proxy they're fetched through
+	chrome.Proxy = options.HTTP.Proxy
+
+	renderer, err := render.GenerateChromeRenderer(*chrome)
+	if err != nil {
+		return nil, err
+	}
+	options.Renderer = renderer
+
+	return func() { renderer.Close() }, nil
+}
+
+func serve(args []string) error {
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	options := crawlFlags(flags)
+	chrome := renderFlags(flags)
+	addr := flags.String("addr", ":8080", "Address for the web server to listen on")
+	flags.Parse(args)
+
+	stop, err := startRenderer(options, chrome)
+	if err != nil {
+		return err
+	}
+	defer stop()
+
+	manager := server.GenerateManager()
+	manager.SetHTTPConfig(*options.HTTP)
+	manager.SetRenderer(options.Renderer)
+	_, err = manager.Start(*options)
+
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Starting web server on", *addr)
+	return http.ListenAndServe(*addr, server.GenerateServer(manager))
 }
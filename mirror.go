@@ -0,0 +1,56 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/url"
+
+	"github.com/kevinoconnor7/digitalocean-crawler/crawler"
+)
+
+// mirrorSite crawls a site and saves a copy of it that can be browsed
+// offline
+func mirrorSite(args []string) error {
+	flags := flag.NewFlagSet("mirror", flag.ExitOnError)
+	options := crawlFlags(flags)
+	chrome := renderFlags(flags)
+	out := flags.String("out", "", "File to also write the crawl to")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: mirror [flags] dir")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return fmt.Errorf("Expected a directory to save the mirror to")
+	}
+
+	seed, err := url.Parse(options.Seed)
+
+	if err != nil {
+		return err
+	}
+
+	stop, err := startRenderer(options, chrome)
+	if err != nil {
+		return err
+	}
+	defer stop()
+
+	config := options.Config()
+	config.MirrorDir = flags.Arg(0)
+
+	c := crawler.GenerateCrawlerWithConfig(seed, config)
+
+	err = c.Run(options.MaxPages)
+	if err != nil {
+		return err
+	}
+
+	if *out == "" {
+		return nil
+	}
+
+	return writeResults(c, *out)
+}
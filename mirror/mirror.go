@@ -0,0 +1,253 @@
+package mirror
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+)
+
+// ResolveFunc resolves a reference found in a page against the site's base
+// URL, returning the absolute URL and whether it's on another host
+// It's the crawler's own resolution so mirrored links match crawled assets
+type ResolveFunc func(ref string, base *url.URL) (string, bool, error)
+
+// cssURL matches url(...) references and @import strings in stylesheets
+var cssURL = regexp.MustCompile(`url\(\s*(['"]?)([^'")]+)(['"]?)\s*\)|@import\s+(['"])([^'"]+)(['"])`)
+
+// attributes lists the HTML attributes holding links that are rewritten
+var attributes = map[string][]string{
+	"a":      {"href"},
+	"area":   {"href"},
+	"link":   {"href"},
+	"img":    {"src", "srcset"},
+	"script": {"src"},
+	"iframe": {"src"},
+	"source": {"src", "srcset"},
+	"video":  {"src", "poster"},
+	"audio":  {"src"},
+}
+
+// Mirror saves fetched assets into a directory tree following their URL
+// paths, rewriting links between them so the copy can be browsed offline
+type Mirror struct {
+	dir     string
+	base    *url.URL
+	resolve ResolveFunc
+}
+
+// GenerateMirror is a factory for a Mirror writing into dir
+func GenerateMirror(dir string, base *url.URL, resolve ResolveFunc) *Mirror {
+	return &Mirror{
+		dir:     dir,
+		base:    base,
+		resolve: resolve,
+	}
+}
+
+// LocalPath maps a URL to the slash separated path it's saved at
+// URLs ending in a slash or without a file extension are directories and
+// saved as their index.html, and query strings are folded into the file
+// name as a hash of the sorted parameters, so the same URL always maps to
+// the same file whatever order its parameters are in
+func LocalPath(rawURL string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+
+	// Cleaning the rooted path keeps .. segments inside the mirror
+	p := path.Clean("/" + u.Path)
+	if strings.HasSuffix(u.Path, "/") || path.Ext(p) == "" {
+		p = path.Join(p, "index.html")
+	}
+
+	if u.RawQuery != "" {
+		sum := sha1.Sum([]byte(u.Query().Encode()))
+		ext := path.Ext(p)
+		p = strings.TrimSuffix(p, ext) + "-" + hex.EncodeToString(sum[:4]) + ext
+	}
+
+	return strings.TrimPrefix(p, "/"), nil
+}
+
+// Relative returns the link from the file at one local path to another
+func Relative(from string, to string) string {
+	rel, err := filepath.Rel(filepath.FromSlash(path.Dir(from)), filepath.FromSlash(to))
+	if err != nil {
+		return to
+	}
+
+	return filepath.ToSlash(rel)
+}
+
+// Save writes a fetched asset into the mirror, rewriting the links of
+// HTML pages and stylesheets, and returns the local path it was saved at
+func (m *Mirror) Save(rawURL string, contentType string, body []byte) (string, error) {
+	local, err := LocalPath(rawURL)
+	if err != nil {
+		return "", err
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+
+	switch {
+	case mediaType == "text/html" || mediaType == "application/xhtml+xml":
+		body, err = m.RewriteHTML(local, body)
+		if err != nil {
+			return "", err
+		}
+	case mediaType == "text/css" || path.Ext(local) == ".css":
+		body = m.RewriteCSS(local, body, false)
+	}
+
+	file := filepath.Join(m.dir, filepath.FromSlash(local))
+
+	err = os.MkdirAll(filepath.Dir(file), 0755)
+	if err != nil {
+		return "", err
+	}
+
+	err = os.WriteFile(file, body, 0644)
+	if err != nil {
+		return "", fmt.Errorf("Unable to save %s: %v", rawURL, err)
+	}
+
+	return local, nil
+}
+
+// RewriteHTML points the links of the page saved at local at the mirrored
+// copies of their targets, including those in inline styles
+func (m *Mirror) RewriteHTML(local string, body []byte) ([]byte, error) {
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	for tag, attrs := range attributes {
+		for _, attr := range attrs {
+			doc.Find(tag + "[" + attr + "]").Each(func(i int, s *goquery.Selection) {
+				value, _ := s.Attr(attr)
+
+				if attr == "srcset" {
+					s.SetAttr(attr, m.rewriteSrcset(local, value))
+					return
+				}
+
+				s.SetAttr(attr, m.rewrite(local, value))
+			})
+		}
+	}
+
+	// Style contents are raw text, so the nodes are changed directly rather
+	// than with SetText, which would escape them
+	doc.Find("style").Each(func(i int, s *goquery.Selection) {
+		for child := s.Get(0).FirstChild; child != nil; child = child.NextSibling {
+			if child.Type == html.TextNode {
+				child.Data = string(m.RewriteCSS(local, []byte(child.Data), true))
+			}
+		}
+	})
+
+	doc.Find("[style]").Each(func(i int, s *goquery.Selection) {
+		style, _ := s.Attr("style")
+		s.SetAttr("style", string(m.RewriteCSS(local, []byte(style), true)))
+	})
+
+	rewritten, err := doc.Html()
+	if err != nil {
+		return nil, err
+	}
+
+	return []byte(rewritten), nil
+}
+
+// RewriteCSS points the url() and @import references of a stylesheet saved
+// at local at their mirrored copies
+// Stylesheets keep their paths, so document relative references already
+// work offline and are only rewritten for styles embedded in pages
+func (m *Mirror) RewriteCSS(local string, css []byte, embedded bool) []byte {
+	return cssURL.ReplaceAllFunc(css, func(match []byte) []byte {
+		groups := cssURL.FindSubmatch(match)
+
+		quote, ref, closing := groups[1], string(groups[2]), groups[3]
+		prefix, suffix := "url(", ")"
+		if groups[5] != nil {
+			quote, ref, closing = groups[4], string(groups[5]), groups[6]
+			prefix, suffix = "@import ", ""
+		}
+
+		ref = strings.TrimSpace(ref)
+		if !embedded && !rooted(ref) {
+			return match
+		}
+
+		return []byte(prefix + string(quote) + m.rewrite(local, ref) + string(closing) + suffix)
+	})
+}
+
+// rewrite maps a single reference in the file at local to its mirrored copy
+// References to other hosts and other schemes are left alone
+// Pages are saved before the assets they link to are fetched, so every
+// internal reference is rewritten, and those that are never saved, such as
+// error responses or URLs the crawl doesn't reach, are broken in the copy
+func (m *Mirror) rewrite(local string, ref string) string {
+	ref = strings.TrimSpace(ref)
+	if ref == "" || strings.HasPrefix(ref, "#") {
+		return ref
+	}
+
+	parsed, err := url.Parse(ref)
+	if err != nil || (parsed.Scheme != "" && parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return ref
+	}
+
+	resolved, external, err := m.resolve(ref, m.base)
+	if err != nil || external {
+		return ref
+	}
+
+	target, err := LocalPath(resolved)
+	if err != nil {
+		return ref
+	}
+
+	rel := Relative(local, target)
+	if parsed.Fragment != "" {
+		rel += "#" + parsed.Fragment
+	}
+
+	return rel
+}
+
+// rewriteSrcset rewrites every candidate URL of a srcset attribute
+func (m *Mirror) rewriteSrcset(local string, srcset string) string {
+	candidates := strings.Split(srcset, ",")
+
+	for i, candidate := range candidates {
+		fields := strings.Fields(candidate)
+		if len(fields) == 0 {
+			continue
+		}
+
+		fields[0] = m.rewrite(local, fields[0])
+		candidates[i] = strings.Join(fields, " ")
+	}
+
+	return strings.Join(candidates, ", ")
+}
+
+// rooted reports whether a reference is absolute or relative to the root
+func rooted(ref string) bool {
+	return strings.HasPrefix(ref, "/") || strings.Contains(ref, "://")
+}
@@ -0,0 +1,126 @@
+package mirror_test
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"github.com/kevinoconnor7/digitalocean-crawler/crawler"
+	. "github.com/kevinoconnor7/digitalocean-crawler/mirror"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Mirror", func() {
+	Describe("LocalPath", func() {
+		It("Should save directories as their index", func() {
+			Expect(LocalPath("http://example.com")).To(Equal("index.html"))
+			Expect(LocalPath("http://example.com/")).To(Equal("index.html"))
+			Expect(LocalPath("http://example.com/about")).To(Equal("about/index.html"))
+			Expect(LocalPath("http://example.com/about/")).To(Equal("about/index.html"))
+		})
+
+		It("Should keep file names with extensions", func() {
+			Expect(LocalPath("http://example.com/css/site.css")).To(Equal("css/site.css"))
+			Expect(LocalPath("http://example.com/img/logo.png#top")).To(Equal("img/logo.png"))
+		})
+
+		It("Should keep paths inside the mirror", func() {
+			Expect(LocalPath("http://example.com/../../etc/passwd.txt")).To(Equal("etc/passwd.txt"))
+		})
+
+		It("Should name query strings deterministically", func() {
+			a, _ := LocalPath("http://example.com/search?q=x&page=2")
+			b, _ := LocalPath("http://example.com/search?page=2&q=x")
+			c, _ := LocalPath("http://example.com/search?page=3&q=x")
+
+			Expect(a).To(MatchRegexp(`^search/index-[0-9a-f]{8}\.html$`))
+			Expect(a).To(Equal(b))
+			Expect(a).ToNot(Equal(c))
+
+			Expect(LocalPath("http://example.com/site.css?v=1")).To(MatchRegexp(`^site-[0-9a-f]{8}\.css$`))
+		})
+	})
+
+	It("Should link between local paths", func() {
+		Expect(Relative("index.html", "about/index.html")).To(Equal("about/index.html"))
+		Expect(Relative("about/index.html", "css/site.css")).To(Equal("../css/site.css"))
+		Expect(Relative("about/index.html", "about/index.html")).To(Equal("index.html"))
+	})
+
+	Describe("Save", func() {
+		var (
+			dir string
+			m   *Mirror
+		)
+
+		BeforeEach(func() {
+			var err error
+			dir, err = os.MkdirTemp("", "mirror")
+			Expect(err).ToNot(HaveOccurred())
+
+			base, _ := url.Parse("http://example.com/")
+			m = GenerateMirror(dir, base, crawler.ResolveURL)
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(dir)
+		})
+
+		read := func(local string) string {
+			data, err := os.ReadFile(filepath.Join(dir, local))
+			Expect(err).ToNot(HaveOccurred())
+			return string(data)
+		}
+
+		It("Should rewrite links in pages to local paths", func() {
+			local, err := m.Save("http://example.com/blog/post", "text/html; charset=utf-8", []byte(`<html><head>
+<link rel="stylesheet" href="/css/site.css">
+<style>body { background: url("/img/bg.png") }</style>
+</head><body>
+<a href="/">Home</a>
+<a href="http://example.com/about#team">About</a>
+<a href="https://elsewhere.com/">Elsewhere</a>
+<a href="mailto:hi@example.com">Mail</a>
+<a href="#top">Top</a>
+<img src="/img/logo.png" srcset="/img/logo.png 1x, /img/logo@2x.png 2x">
+<div style="background-image: url(/img/hero.jpg)"></div>
+</body></html>`))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(local).To(Equal("blog/post/index.html"))
+
+			html := read(local)
+			Expect(html).To(ContainSubstring(`href="../../css/site.css"`))
+			Expect(html).To(ContainSubstring(`url("../../img/bg.png")`))
+			Expect(html).To(ContainSubstring(`href="../../index.html"`))
+			Expect(html).To(ContainSubstring(`href="../../about/index.html#team"`))
+			Expect(html).To(ContainSubstring(`href="https://elsewhere.com/"`))
+			Expect(html).To(ContainSubstring(`href="mailto:hi@example.com"`))
+			Expect(html).To(ContainSubstring(`href="#top"`))
+			Expect(html).To(ContainSubstring(`src="../../img/logo.png"`))
+			Expect(html).To(ContainSubstring(`srcset="../../img/logo.png 1x, ../../img/logo@2x.png 2x"`))
+			Expect(html).To(ContainSubstring(`url(../../img/hero.jpg)`))
+		})
+
+		It("Should rewrite rooted references in stylesheets", func() {
+			_, err := m.Save("http://example.com/css/site.css", "text/css", []byte(`@import "/css/base.css";
+body { background: url('/img/bg.png'); }
+.icon { background: url(icons.svg); }
+.inline { background: url(data:image/png;base64,AAAA); }`))
+			Expect(err).ToNot(HaveOccurred())
+
+			css := read("css/site.css")
+			Expect(css).To(ContainSubstring(`@import "base.css";`))
+			Expect(css).To(ContainSubstring(`url('../img/bg.png')`))
+			Expect(css).To(ContainSubstring(`url(icons.svg)`))
+			Expect(css).To(ContainSubstring(`url(data:image/png;base64,AAAA)`))
+		})
+
+		It("Should save other assets unchanged", func() {
+			_, err := m.Save("http://example.com/js/app.js", "application/javascript", []byte(`location.href = "/about"`))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(read("js/app.js")).To(Equal(`location.href = "/about"`))
+		})
+	})
+})
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/kevinoconnor7/digitalocean-crawler/crawler"
+)
+
+// path prints the shortest click paths between two URLs of a saved crawl
+func path(args []string) error {
+	flags := flag.NewFlagSet("path", flag.ExitOnError)
+	options := crawler.PathOptions{}
+	flags.BoolVar(&options.IgnoreNofollow, "ignoreNofollow", false, "Don't follow links that are only ever nofollow")
+	flags.BoolVar(&options.IgnoreBoilerplate, "ignoreBoilerplate", false, "Don't follow links that only appear in navigation or footers")
+	flags.BoolVar(&options.IgnoreInferred, "ignoreInferred", false, "Don't follow links only guessed from scripts and attributes")
+	flags.IntVar(&options.Limit, "limit", 10, "Max number of paths to print")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: path [flags] crawl.json FROM TO")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() != 3 {
+		flags.Usage()
+		return fmt.Errorf("Expected a crawl file and two URLs")
+	}
+
+	s, err := loadSnapshot(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	result, err := s.ShortestPaths(flags.Arg(1), flags.Arg(2), options)
+	if err != nil {
+		return err
+	}
+
+	if len(result.Paths) == 0 {
+		fmt.Printf("%s can't be reached from %s\n", result.To.URL, result.From.URL)
+		return nil
+	}
+
+	fmt.Printf("%d shortest path(s) of %d click(s) from %s to %s\n", len(result.Paths), result.Clicks, result.From.URL, result.To.URL)
+	for i, nodes := range result.Paths {
+		fmt.Printf("\n%d. %s\n", i+1, nodes[0].URL)
+		for _, node := range nodes[1:] {
+			fmt.Printf("   -> %s\n", node.URL)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,262 @@
+package render
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/chromedp/cdproto/emulation"
+	"github.com/chromedp/cdproto/fetch"
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/chromedp"
+)
+
+// ChromeOptions configures the browser a ChromeRenderer drives
+type ChromeOptions struct {
+	// ExecPath is the Chrome or Chromium binary to start, looked up on the
+	// PATH if empty
+	ExecPath string
+
+	// RemoteURL is the DevTools URL of an already running browser to use
+	// instead of starting one
+	RemoteURL string
+
+	// Proxy is the proxy a started browser sends requests through
+	Proxy string
+
+	// Wait is how long scripts are left to run after the page has loaded
+	Wait time.Duration
+
+	// Timeout limits how long each page may take to render, if positive
+	Timeout time.Duration
+}
+
+// DefaultChromeOptions returns the ChromeOptions used when none are set
+func DefaultChromeOptions() ChromeOptions {
+	return ChromeOptions{
+		Wait:    time.Second,
+		Timeout: 30 * time.Second,
+	}
+}
+
+// ChromeRenderer renders pages in a headless Chrome over the DevTools
+// Protocol, each in a new tab
+type ChromeRenderer struct {
+	options ChromeOptions
+	browser context.Context
+	cancel  context.CancelFunc
+}
+
+// GenerateChromeRenderer starts or connects to the browser
+func GenerateChromeRenderer(options ChromeOptions) (*ChromeRenderer, error) {
+	var (
+		allocator       context.Context
+		cancelAllocator context.CancelFunc
+	)
+
+	if options.RemoteURL != "" {
+		allocator, cancelAllocator = chromedp.NewRemoteAllocator(context.Background(), options.RemoteURL)
+	} else {
+		opts := append([]chromedp.ExecAllocatorOption{}, chromedp.DefaultExecAllocatorOptions[:]...)
+
+		if options.ExecPath != "" {
+			opts = append(opts, chromedp.ExecPath(options.ExecPath))
+		}
+
+		if options.Proxy != "" {
+			opts = append(opts, chromedp.ProxyServer(options.Proxy))
+		}
+
+		allocator, cancelAllocator = chromedp.NewExecAllocator(context.Background(), opts...)
+	}
+
+	browser, cancelBrowser := chromedp.NewContext(allocator)
+
+	// Running nothing starts the browser, so a missing one is reported now
+	err := chromedp.Run(browser)
+	if err != nil {
+		cancelBrowser()
+		cancelAllocator()
+		return nil, fmt.Errorf("Unable to start the browser: %v", err)
+	}
+
+	return &ChromeRenderer{
+		options: options,
+		browser: browser,
+		cancel: func() {
+			cancelBrowser()
+			cancelAllocator()
+		},
+	}, nil
+}
+
+// Render loads the page in a new tab and serializes its DOM once it has
+// loaded and scripts have had ChromeOptions.Wait to run
+func (r *ChromeRenderer) Render(req *http.Request) (*Result, error) {
+	tab, cancel := chromedp.NewContext(r.browser)
+	defer cancel()
+
+	// Cancelling the request stops the render
+	stop := context.AfterFunc(req.Context(), cancel)
+	defer stop()
+
+	if r.options.Timeout > 0 {
+		var cancelTimeout context.CancelFunc
+		tab, cancelTimeout = context.WithTimeout(tab, r.options.Timeout)
+		defer cancelTimeout()
+	}
+
+	// The User-Agent is overridden for the whole tab instead
+	headers := req.Header.Clone()
+	headers.Del("User-Agent")
+
+	rec := &recorder{ids: make(map[network.RequestID]int)}
+
+	chromedp.ListenTarget(tab, func(ev any) {
+		switch ev := ev.(type) {
+		case *network.EventRequestWillBeSent:
+			rec.request(ev)
+		case *network.EventResponseReceived:
+			rec.response(ev)
+		case *fetch.EventRequestPaused:
+			// Listeners mustn't block, and continuing waits for a reply
+			go chromedp.Run(tab, continueRequest(ev, req.URL.Host, headers))
+		}
+	})
+
+	actions := []chromedp.Action{network.Enable()}
+
+	if userAgent := req.Header.Get("User-Agent"); userAgent != "" {
+		actions = append(actions, emulation.SetUserAgentOverride(userAgent))
+	}
+
+	if len(headers) > 0 {
+		actions = append(actions, fetch.Enable().WithPatterns([]*fetch.RequestPattern{{URLPattern: "*"}}))
+	}
+
+	var html string
+	actions = append(actions,
+		chromedp.Navigate(req.URL.String()),
+		chromedp.Sleep(r.options.Wait),
+		chromedp.Evaluate(`document.documentElement.outerHTML`, &html),
+	)
+
+	err := chromedp.Run(tab, actions...)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to render %s: %v", req.URL, err)
+	}
+
+	result := rec.result()
+	result.HTML = []byte(html)
+
+	if result.URL == "" {
+		result.URL = req.URL.String()
+	}
+
+	return result, nil
+}
+
+// Close shuts down a started browser, or disconnects from a remote one
+func (r *ChromeRenderer) Close() error {
+	err := chromedp.Cancel(r.browser)
+	r.cancel()
+
+	return err
+}
+
+// continueRequest lets a request paused for interception continue, with the
+// crawler's headers added if it's to host
+// Requests to other hosts are left alone, so credentials stay on the site
+// they're for
+func continueRequest(ev *fetch.EventRequestPaused, host string, headers http.Header) chromedp.Action {
+	continued := fetch.ContinueRequest(ev.RequestID)
+
+	target, err := url.Parse(ev.Request.URL)
+	if err != nil || target.Host != host {
+		return continued
+	}
+
+	var entries []*fetch.HeaderEntry
+
+	for name, value := range ev.Request.Headers {
+		if headers.Get(name) == "" {
+			entries = append(entries, &fetch.HeaderEntry{Name: name, Value: fmt.Sprint(value)})
+		}
+	}
+
+	for name, values := range headers {
+		for _, value := range values {
+			entries = append(entries, &fetch.HeaderEntry{Name: name, Value: value})
+		}
+	}
+
+	return continued.WithHeaders(entries)
+}
+
+// recorder collects the network requests of a tab from its events
+type recorder struct {
+	mu       sync.Mutex
+	requests []Request
+	ids      map[network.RequestID]int
+
+	// The main frame is the one the first document is loaded in
+	frame  string
+	url    string
+	status int
+}
+
+func (r *recorder) request(ev *network.EventRequestWillBeSent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.frame == "" && ev.Type == network.ResourceTypeDocument {
+		r.frame = string(ev.FrameID)
+	}
+
+	// Redirects reuse the ID of the request they answer
+	if i, ok := r.ids[ev.RequestID]; ok && ev.RedirectResponse != nil {
+		r.requests[i].StatusCode = int(ev.RedirectResponse.Status)
+	}
+
+	target, err := url.Parse(ev.Request.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") {
+		return
+	}
+
+	r.ids[ev.RequestID] = len(r.requests)
+	r.requests = append(r.requests, Request{
+		URL:    ev.Request.URL,
+		Method: ev.Request.Method,
+		Type:   string(ev.Type),
+	})
+}
+
+func (r *recorder) response(ev *network.EventResponseReceived) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if i, ok := r.ids[ev.RequestID]; ok {
+		r.requests[i].StatusCode = int(ev.Response.Status)
+	}
+
+	// The page's own response is the last document loaded in the main frame
+	// by its navigation, whose request shares the loader's ID
+	if ev.Type == network.ResourceTypeDocument && string(ev.FrameID) == r.frame && string(ev.RequestID) == string(ev.LoaderID) {
+		r.url = ev.Response.URL
+		r.status = int(ev.Response.Status)
+	}
+}
+
+func (r *recorder) result() *Result {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return &Result{
+		URL:        r.url,
+		StatusCode: r.status,
+		Requests:   append([]Request(nil), r.requests...),
+	}
+}
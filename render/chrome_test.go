@@ -0,0 +1,85 @@
+package render_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+
+	. "github.com/kevinoconnor7/digitalocean-crawler/render"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ChromeRenderer", func() {
+	var (
+		r     *ChromeRenderer
+		site  *httptest.Server
+		other *httptest.Server
+
+		mu   sync.Mutex
+		auth map[string]string
+	)
+
+	BeforeEach(func() {
+		var err error
+		r, err = GenerateChromeRenderer(DefaultChromeOptions())
+		if err != nil {
+			Skip("No browser to render with: " + err.Error())
+		}
+
+		auth = make(map[string]string)
+		record := func(name string, h http.HandlerFunc) http.HandlerFunc {
+			return func(w http.ResponseWriter, req *http.Request) {
+				mu.Lock()
+				auth[name+req.URL.Path] = req.Header.Get("Authorization")
+				mu.Unlock()
+				h(w, req)
+			}
+		}
+
+		other = httptest.NewServer(record("other", func(w http.ResponseWriter, req *http.Request) {
+			w.Header().Set("Content-Type", "image/gif")
+		}))
+
+		site = httptest.NewServer(record("site", func(w http.ResponseWriter, req *http.Request) {
+			switch req.URL.Path {
+			case "/":
+				w.Header().Set("Content-Type", "text/html")
+				w.Write([]byte(`<div id="root"></div><img src="` + other.URL + `/pixel.gif"><script src="/app.js"></script>`))
+			case "/app.js":
+				w.Header().Set("Content-Type", "application/javascript")
+				w.Write([]byte(`document.getElementById("root").innerHTML = '<a href="/injected">Injected</a>'`))
+			}
+		}))
+	})
+
+	AfterEach(func() {
+		if r != nil {
+			r.Close()
+			site.Close()
+			other.Close()
+		}
+	})
+
+	It("Should render the page and record its requests", func() {
+		req, _ := http.NewRequest("GET", site.URL+"/", nil)
+		req.Header.Set("Authorization", "Bearer secret")
+
+		result, err := r.Render(req)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.StatusCode).To(Equal(http.StatusOK))
+		Expect(string(result.HTML)).To(ContainSubstring(`<a href="/injected">`))
+
+		var types []string
+		for _, request := range result.Requests {
+			types = append(types, request.Type)
+		}
+		Expect(types).To(ContainElements(DOCUMENT, SCRIPT, IMAGE))
+
+		mu.Lock()
+		defer mu.Unlock()
+		Expect(auth["site/app.js"]).To(Equal("Bearer secret"))
+		Expect(auth["other/pixel.gif"]).To(BeEmpty())
+	})
+})
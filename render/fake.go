@@ -0,0 +1,57 @@
+package render
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+// FakeRenderer renders pages from canned results, for tests
+type FakeRenderer struct {
+	mu       sync.Mutex
+	pages    map[string]*Result
+	rendered []*http.Request
+}
+
+// GenerateFakeRenderer is a factory for an empty FakeRenderer
+func GenerateFakeRenderer() *FakeRenderer {
+	return &FakeRenderer{pages: make(map[string]*Result)}
+}
+
+// Add sets the DOM and requests url renders to
+func (f *FakeRenderer) Add(url string, html string, requests ...Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.pages[url] = &Result{
+		URL:        url,
+		StatusCode: http.StatusOK,
+		HTML:       []byte(html),
+		Requests:   requests,
+	}
+}
+
+// Render returns the page added for the request's URL
+// URLs without a page fail to render
+func (f *FakeRenderer) Render(req *http.Request) (*Result, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.rendered = append(f.rendered, req)
+
+	result, ok := f.pages[req.URL.String()]
+	if !ok {
+		return nil, fmt.Errorf("Unable to render %s", req.URL)
+	}
+
+	copied := *result
+	return &copied, nil
+}
+
+// Rendered lists every request rendered so far
+func (f *FakeRenderer) Rendered() []*http.Request {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]*http.Request(nil), f.rendered...)
+}
@@ -0,0 +1,108 @@
+package render
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+)
+
+// Resource types of the requests a page makes that the crawler links to
+const (
+	DOCUMENT   = "Document"
+	STYLESHEET = "Stylesheet"
+	SCRIPT     = "Script"
+	IMAGE      = "Image"
+)
+
+// Request is a network request made by a page while it was rendered
+type Request struct {
+	URL    string `json:"url"`
+	Method string `json:"method"`
+
+	// Type is how the browser used the resource, such as Script,
+	// Stylesheet or Image
+	Type string `json:"type"`
+
+	// StatusCode is zero if no response was received
+	StatusCode int `json:"statusCode,omitempty"`
+}
+
+// Result is a page after its scripts have run
+type Result struct {
+	// URL is where the page ended up after any redirects
+	URL        string
+	StatusCode int
+
+	// HTML is the serialized DOM, encoded as UTF-8
+	HTML []byte
+
+	// Requests lists every request the page made, in the order they were
+	// sent
+	Requests []Request
+}
+
+// Renderer loads pages in a browser
+type Renderer interface {
+	// Render loads the page the request is for, sending its headers with
+	// every request the page makes to the same host
+	Render(req *http.Request) (*Result, error)
+}
+
+// Patterns selects pages by matching their URLs against regular
+// expressions
+type Patterns []*regexp.Regexp
+
+// CompilePatterns compiles every expression into Patterns
+func CompilePatterns(expressions ...string) (Patterns, error) {
+	patterns := make(Patterns, len(expressions))
+
+	for i, expression := range expressions {
+		pattern, err := regexp.Compile(expression)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid render pattern %q: %v", expression, err)
+		}
+		patterns[i] = pattern
+	}
+
+	return patterns, nil
+}
+
+// Match reports whether the URL matches any pattern, or whether there are
+// no patterns to select by
+func (p Patterns) Match(url string) bool {
+	if len(p) == 0 {
+		return true
+	}
+
+	for _, pattern := range p {
+		if pattern.MatchString(url) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// MarshalJSON encodes the patterns as a list of expressions
+func (p Patterns) MarshalJSON() ([]byte, error) {
+	expressions := make([]string, len(p))
+	for i, pattern := range p {
+		expressions[i] = pattern.String()
+	}
+
+	return json.Marshal(expressions)
+}
+
+// UnmarshalJSON compiles a list of expressions
+func (p *Patterns) UnmarshalJSON(data []byte) error {
+	var expressions []string
+
+	err := json.Unmarshal(data, &expressions)
+	if err != nil {
+		return err
+	}
+
+	*p, err = CompilePatterns(expressions...)
+	return err
+}
@@ -0,0 +1,65 @@
+package render_test
+
+import (
+	"encoding/json"
+	"net/http"
+
+	. "github.com/kevinoconnor7/digitalocean-crawler/render"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Render", func() {
+	Describe("Patterns", func() {
+		It("Should match any pattern", func() {
+			patterns, err := CompilePatterns(`/app/`, `\?view=`)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(patterns.Match("http://foo.faketld/app/settings")).To(BeTrue())
+			Expect(patterns.Match("http://foo.faketld/list?view=grid")).To(BeTrue())
+			Expect(patterns.Match("http://foo.faketld/about")).To(BeFalse())
+		})
+
+		It("Should match everything without patterns", func() {
+			Expect(Patterns(nil).Match("http://foo.faketld/")).To(BeTrue())
+		})
+
+		It("Should reject invalid patterns", func() {
+			_, err := CompilePatterns(`(`)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("Should round trip through JSON", func() {
+			var patterns Patterns
+			Expect(json.Unmarshal([]byte(`["^/app/", "\\.html$"]`), &patterns)).To(Succeed())
+			Expect(patterns).To(HaveLen(2))
+
+			data, err := json.Marshal(patterns)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(data)).To(Equal(`["^/app/","\\.html$"]`))
+
+			Expect(json.Unmarshal([]byte(`["("]`), &patterns)).ToNot(Succeed())
+		})
+	})
+
+	Describe("FakeRenderer", func() {
+		It("Should return the pages added", func() {
+			f := GenerateFakeRenderer()
+			f.Add("http://foo.faketld/", "<p>Rendered</p>", Request{URL: "http://foo.faketld/app.js", Type: SCRIPT})
+
+			req, _ := http.NewRequest("GET", "http://foo.faketld/", nil)
+			result, err := f.Render(req)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(result.HTML)).To(Equal("<p>Rendered</p>"))
+			Expect(result.StatusCode).To(Equal(http.StatusOK))
+			Expect(result.Requests).To(HaveLen(1))
+			Expect(f.Rendered()).To(Equal([]*http.Request{req}))
+		})
+
+		It("Should fail for other pages", func() {
+			req, _ := http.NewRequest("GET", "http://foo.faketld/missing", nil)
+			_, err := GenerateFakeRenderer().Render(req)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
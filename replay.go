@@ -0,0 +1,106 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+
+	"github.com/kevinoconnor7/digitalocean-crawler/crawler"
+	"github.com/kevinoconnor7/digitalocean-crawler/warc"
+)
+
+// replay rebuilds a crawl from WARC archives without fetching anything
+func replay(args []string) error {
+	flags := flag.NewFlagSet("replay", flag.ExitOnError)
+	options := crawlFlags(flags)
+	out := flags.String("out", "", "File to write the crawl to, defaults to stdout")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: replay [flags] archive.warc.gz...")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return fmt.Errorf("Expected at least one WARC file")
+	}
+
+	// A browser would load the live site instead of the archive
+	if options.Render {
+		return fmt.Errorf("Pages can't be rendered while replaying")
+	}
+
+	// The seed defaults to the first URL archived rather than the live site
+	seedSet := false
+	flags.Visit(func(f *flag.Flag) {
+		seedSet = seedSet || f.Name == "seed"
+	})
+
+	if !seedSet {
+		target, err := firstTarget(flags.Arg(0))
+		if err != nil {
+			return err
+		}
+		options.Seed = target
+	}
+
+	seed, err := url.Parse(options.Seed)
+
+	if err != nil {
+		return err
+	}
+
+	var archives []io.ReaderAt
+	for _, path := range flags.Args() {
+		file, err := os.Open(path)
+
+		if err != nil {
+			return err
+		}
+
+		defer file.Close()
+		archives = append(archives, file)
+	}
+
+	c := crawler.GenerateCrawlerWithConfig(seed, options.Config())
+
+	err = c.Replay(archives...)
+	if err != nil {
+		return err
+	}
+
+	return writeResults(c, *out)
+}
+
+// firstTarget returns the URL of the first response archived in a WARC file
+func firstTarget(path string) (string, error) {
+	file, err := os.Open(path)
+
+	if err != nil {
+		return "", err
+	}
+
+	defer file.Close()
+
+	reader, err := warc.GenerateReader(file)
+	if err != nil {
+		return "", err
+	}
+
+	for {
+		record, err := reader.Next()
+		if err == io.EOF {
+			return "", fmt.Errorf("No responses archived in %s", path)
+		}
+
+		if err != nil {
+			return "", err
+		}
+
+		if record.Type() == warc.RESPONSE {
+			return record.TargetURI(), nil
+		}
+	}
+}
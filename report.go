@@ -0,0 +1,51 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/kevinoconnor7/digitalocean-crawler/audit"
+	"github.com/kevinoconnor7/digitalocean-crawler/report"
+)
+
+// auditReport summarizes the audit findings of a saved crawl
+func auditReport(args []string) error {
+	flags := flag.NewFlagSet("report", flag.ExitOnError)
+	asJSON := flags.Bool("json", false, "Print the report as JSON")
+	severity := flags.String("severity", "info", "Leave out findings less severe than this, one of info, warning or error")
+	category := flags.String("category", "", "Only include findings of this category, seo or accessibility")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: report [flags] crawl.json")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return fmt.Errorf("Expected a crawl file")
+	}
+
+	minimum, err := audit.ParseSeverity(*severity)
+	if err != nil {
+		return err
+	}
+
+	filter := report.AuditFilter{Severity: minimum, Category: audit.Category(*category)}
+
+	s, err := loadSnapshot(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	a := report.GenerateAudit(s, filter)
+
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(a)
+	}
+
+	return a.WriteText(os.Stdout)
+}
@@ -0,0 +1,133 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/kevinoconnor7/digitalocean-crawler/audit"
+	"github.com/kevinoconnor7/digitalocean-crawler/crawler"
+)
+
+// AuditFilter selects the findings included in an Audit
+// Zero values match every finding
+type AuditFilter struct {
+	Severity audit.Severity
+	Category audit.Category
+}
+
+// Matches reports whether the finding passes the filter
+func (f AuditFilter) Matches(finding audit.Finding) bool {
+	if finding.Severity < f.Severity {
+		return false
+	}
+
+	return f.Category == "" || f.Category == finding.Category
+}
+
+// RuleSummary counts the findings of one audit rule across a crawl
+type RuleSummary struct {
+	Rule     string         `json:"rule"`
+	Category audit.Category `json:"category"`
+	Severity audit.Severity `json:"severity"`
+	Findings int            `json:"findings"`
+	URLs     []string       `json:"urls"`
+	Indices  []int          `json:"indices"`
+}
+
+// Audit summarizes the audit findings of every page in a crawl
+type Audit struct {
+	Pages         int                    `json:"pages"`
+	AffectedPages int                    `json:"affectedPages"`
+	Findings      int                    `json:"findings"`
+	Categories    map[audit.Category]int `json:"categories"`
+	Rules         []RuleSummary          `json:"rules"`
+}
+
+// GenerateAudit groups the findings of the snapshot that match the filter
+// by rule
+// Rules are ordered from most to least severe, then by how often they fired
+func GenerateAudit(s *crawler.Snapshot, filter AuditFilter) *Audit {
+	a := &Audit{
+		Categories: make(map[audit.Category]int),
+		Rules:      []RuleSummary{},
+	}
+	rules := make(map[string]*RuleSummary)
+
+	for i, node := range s.Nodes {
+		if node.Type == crawler.PAGE && !node.External && node.StatusCode >= 200 && node.StatusCode < 300 {
+			a.Pages++
+		}
+
+		seen := make(map[string]bool)
+		for _, finding := range node.Findings {
+			if !filter.Matches(finding) {
+				continue
+			}
+
+			summary, ok := rules[finding.Rule]
+			if !ok {
+				summary = &RuleSummary{
+					Rule:     finding.Rule,
+					Category: finding.Category,
+					Severity: finding.Severity,
+				}
+				rules[finding.Rule] = summary
+			}
+
+			summary.Findings++
+			a.Findings++
+			a.Categories[finding.Category]++
+
+			if len(seen) == 0 {
+				a.AffectedPages++
+			}
+
+			if !seen[finding.Rule] {
+				seen[finding.Rule] = true
+				summary.URLs = append(summary.URLs, node.URL)
+				summary.Indices = append(summary.Indices, i)
+			}
+		}
+	}
+
+	for _, summary := range rules {
+		a.Rules = append(a.Rules, *summary)
+	}
+
+	sort.Slice(a.Rules, func(i, j int) bool {
+		if a.Rules[i].Severity != a.Rules[j].Severity {
+			return a.Rules[i].Severity > a.Rules[j].Severity
+		}
+		if a.Rules[i].Findings != a.Rules[j].Findings {
+			return a.Rules[i].Findings > a.Rules[j].Findings
+		}
+		return a.Rules[i].Rule < a.Rules[j].Rule
+	})
+
+	return a
+}
+
+// WriteText writes the audit in a human readable form
+func (a *Audit) WriteText(w io.Writer) error {
+	_, err := fmt.Fprintf(w, "%d finding(s) on %d of %d page(s)\n", a.Findings, a.AffectedPages, a.Pages)
+	if err != nil {
+		return err
+	}
+
+	for _, rule := range a.Rules {
+		_, err = fmt.Fprintf(w, "\n[%s] %s/%s: %d finding(s) on %d page(s)\n", rule.Severity, rule.Category, rule.Rule, rule.Findings, len(rule.URLs))
+		if err != nil {
+			return err
+		}
+
+		for _, url := range rule.URLs {
+			_, err = fmt.Fprintf(w, "  %s\n", url)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,78 @@
+package report_test
+
+import (
+	"bytes"
+
+	"github.com/kevinoconnor7/digitalocean-crawler/audit"
+	"github.com/kevinoconnor7/digitalocean-crawler/crawler"
+	. "github.com/kevinoconnor7/digitalocean-crawler/report"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Audit", func() {
+	var s *crawler.Snapshot
+
+	BeforeEach(func() {
+		s = &crawler.Snapshot{
+			Nodes: []crawler.Asset{
+				{URL: "http://foo.faketld/", Type: crawler.PAGE, StatusCode: 200, Findings: []audit.Finding{
+					{Rule: "title-missing", Category: audit.SEO, Severity: audit.ERROR},
+					{Rule: "img-name-missing", Category: audit.ACCESSIBILITY, Severity: audit.WARNING},
+					{Rule: "img-name-missing", Category: audit.ACCESSIBILITY, Severity: audit.WARNING},
+				}},
+				{URL: "http://foo.faketld/a", Type: crawler.PAGE, StatusCode: 200, Findings: []audit.Finding{
+					{Rule: "img-name-missing", Category: audit.ACCESSIBILITY, Severity: audit.WARNING},
+					{Rule: "custom", Category: audit.SEO, Severity: audit.INFO},
+				}},
+				{URL: "http://foo.faketld/b", Type: crawler.PAGE, StatusCode: 200},
+				{URL: "http://foo.faketld/main.js", Type: crawler.SCRIPTS, StatusCode: 200},
+			},
+		}
+	})
+
+	It("Should group findings by rule", func() {
+		a := GenerateAudit(s, AuditFilter{})
+
+		Expect(a.Pages).To(Equal(3))
+		Expect(a.AffectedPages).To(Equal(2))
+		Expect(a.Findings).To(Equal(5))
+		Expect(a.Rules).To(Equal([]RuleSummary{
+			{Rule: "title-missing", Category: audit.SEO, Severity: audit.ERROR, Findings: 1, URLs: []string{"http://foo.faketld/"}, Indices: []int{0}},
+			{Rule: "img-name-missing", Category: audit.ACCESSIBILITY, Severity: audit.WARNING, Findings: 3, URLs: []string{"http://foo.faketld/", "http://foo.faketld/a"}, Indices: []int{0, 1}},
+			{Rule: "custom", Category: audit.SEO, Severity: audit.INFO, Findings: 1, URLs: []string{"http://foo.faketld/a"}, Indices: []int{1}},
+		}))
+	})
+
+	It("Should count findings by category", func() {
+		a := GenerateAudit(s, AuditFilter{})
+
+		Expect(a.Categories).To(Equal(map[audit.Category]int{
+			audit.SEO:           2,
+			audit.ACCESSIBILITY: 3,
+		}))
+	})
+
+	It("Should leave out findings below the minimum severity", func() {
+		a := GenerateAudit(s, AuditFilter{Severity: audit.WARNING})
+
+		Expect(a.Findings).To(Equal(4))
+		Expect(len(a.Rules)).To(Equal(2))
+	})
+
+	It("Should only include findings of the category", func() {
+		a := GenerateAudit(s, AuditFilter{Category: audit.ACCESSIBILITY})
+
+		Expect(a.Findings).To(Equal(3))
+		Expect(a.AffectedPages).To(Equal(2))
+		Expect(a.Rules[0].Rule).To(Equal("img-name-missing"))
+	})
+
+	It("Should write each rule as text", func() {
+		var out bytes.Buffer
+		GenerateAudit(s, AuditFilter{Severity: audit.ERROR}).WriteText(&out)
+
+		Expect(out.String()).To(Equal("1 finding(s) on 1 of 3 page(s)\n\n[error] seo/title-missing: 1 finding(s) on 1 page(s)\n  http://foo.faketld/\n"))
+	})
+})
@@ -0,0 +1,183 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/kevinoconnor7/digitalocean-crawler/crawler"
+)
+
+// MinCompressibleSize is the smallest text asset worth compressing
+const MinCompressibleSize = 1024
+
+// DefaultLargest is how many of the heaviest assets are listed by default
+const DefaultLargest = 20
+
+// AssetWeight is the size of one fetched asset
+type AssetWeight struct {
+	URL          string              `json:"url"`
+	Index        int                 `json:"index"`
+	Type         crawler.ContentType `json:"type"`
+	ContentType  string              `json:"contentType,omitempty"`
+	Encoding     string              `json:"encoding,omitempty"`
+	Size         int64               `json:"size"`
+	TransferSize int64               `json:"transferSize"`
+}
+
+// weigh is the AssetWeight of the node at index i
+func weigh(i int, node crawler.Asset) AssetWeight {
+	return AssetWeight{
+		URL:          node.URL,
+		Index:        i,
+		Type:         node.Type,
+		ContentType:  node.ContentType,
+		Encoding:     node.Encoding,
+		Size:         node.Size,
+		TransferSize: node.TransferSize,
+	}
+}
+
+// Weight is what the asset costs to download, its size when the
+// transferred size isn't known
+func (a AssetWeight) Weight() int64 {
+	if a.TransferSize > 0 {
+		return a.TransferSize
+	}
+
+	return a.Size
+}
+
+// Compression summarizes how much the assets of a crawl weigh and which
+// could be served smaller
+type Compression struct {
+	Assets       int   `json:"assets"`
+	Size         int64 `json:"size"`
+	TransferSize int64 `json:"transferSize"`
+
+	// Uncompressed lists text assets served without compression, largest
+	// first
+	Uncompressed []AssetWeight `json:"uncompressed"`
+
+	// Largest lists the heaviest assets
+	Largest []AssetWeight `json:"largest"`
+}
+
+// GenerateCompression weighs every internal asset fetched in the snapshot,
+// listing the largest number of them, or none if it's negative
+func GenerateCompression(s *crawler.Snapshot, largest int) *Compression {
+	c := &Compression{
+		Uncompressed: []AssetWeight{},
+		Largest:      []AssetWeight{},
+	}
+
+	var weights []AssetWeight
+	for i, node := range s.Nodes {
+		if node.External || node.StatusCode == 0 {
+			continue
+		}
+
+		weight := weigh(i, node)
+
+		c.Assets++
+		c.Size += weight.Size
+		c.TransferSize += weight.Weight()
+		weights = append(weights, weight)
+
+		if weight.Encoding == "" && IsText(weight.ContentType) && weight.Size >= MinCompressibleSize {
+			c.Uncompressed = append(c.Uncompressed, weight)
+		}
+	}
+
+	sort.SliceStable(c.Uncompressed, func(i, j int) bool {
+		return c.Uncompressed[i].Size > c.Uncompressed[j].Size
+	})
+
+	sort.SliceStable(weights, func(i, j int) bool {
+		return weights[i].Weight() > weights[j].Weight()
+	})
+
+	if largest < 0 {
+		largest = 0
+	}
+
+	if largest < len(weights) {
+		weights = weights[:largest]
+	}
+	c.Largest = append(c.Largest, weights...)
+
+	return c
+}
+
+// IsText reports whether a media type is text that compresses well
+func IsText(mediaType string) bool {
+	if strings.HasPrefix(mediaType, "text/") {
+		return true
+	}
+
+	switch mediaType {
+	case "application/javascript", "application/x-javascript", "application/ecmascript",
+		"application/json", "application/ld+json", "application/manifest+json",
+		"application/xml", "application/xhtml+xml", "application/rss+xml", "application/atom+xml",
+		"image/svg+xml":
+		return true
+	}
+
+	return strings.HasSuffix(mediaType, "+json") || strings.HasSuffix(mediaType, "+xml")
+}
+
+// WriteText writes the compression report in a human readable form
+func (c *Compression) WriteText(w io.Writer) error {
+	_, err := fmt.Fprintf(w, "%d asset(s), %s transferred, %s decoded\n", c.Assets, formatBytes(c.TransferSize), formatBytes(c.Size))
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "\n%d uncompressed text asset(s)\n", len(c.Uncompressed))
+	if err != nil {
+		return err
+	}
+
+	for _, asset := range c.Uncompressed {
+		_, err = fmt.Fprintf(w, "  %10s  %s  %s\n", formatBytes(asset.Size), asset.ContentType, asset.URL)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = fmt.Fprintf(w, "\nLargest %d asset(s)\n", len(c.Largest))
+	if err != nil {
+		return err
+	}
+
+	for _, asset := range c.Largest {
+		encoding := asset.Encoding
+		if encoding == "" {
+			encoding = "-"
+		}
+
+		_, err = fmt.Fprintf(w, "  %10s  %-5s  %s\n", formatBytes(asset.Weight()), encoding, asset.URL)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// formatBytes writes a byte count with a binary unit
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
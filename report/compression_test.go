@@ -0,0 +1,91 @@
+package report_test
+
+import (
+	"bytes"
+	u "net/url"
+
+	"github.com/kevinoconnor7/digitalocean-crawler/crawler"
+	. "github.com/kevinoconnor7/digitalocean-crawler/report"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Compression", func() {
+	var s *crawler.Snapshot
+
+	BeforeEach(func() {
+		url, _ := u.Parse("http://foo.faketld")
+		c := crawler.GenerateCrawler(url)
+		seed := crawler.GenerateAsset("http://foo.faketld/", crawler.PAGE)
+		seed.StatusCode = 200
+		seed.ContentType = "text/html"
+		seed.Encoding = "gzip"
+		seed.Size = 40000
+		seed.TransferSize = 8000
+		c.StoreAsset(seed)
+
+		c.HandleURL("/main.js", seed, crawler.SCRIPTS)
+		script := c.Assets["http://foo.faketld/main.js"]
+		script.StatusCode = 200
+		script.ContentType = "application/javascript"
+		script.Size = 20000
+		script.TransferSize = 20000
+
+		c.HandleURL("/logo.png", seed, crawler.IMAGES)
+		logo := c.Assets["http://foo.faketld/logo.png"]
+		logo.StatusCode = 200
+		logo.ContentType = "image/png"
+		logo.Size = 50000
+
+		c.HandleURL("/tiny.css", seed, crawler.STYLESHEETS)
+		tiny := c.Assets["http://foo.faketld/tiny.css"]
+		tiny.StatusCode = 200
+		tiny.ContentType = "text/css"
+		tiny.Size = 100
+		tiny.TransferSize = 100
+
+		c.HandleURL("/unfetched", seed, crawler.PAGE)
+
+		s = c.Snapshot()
+	})
+
+	It("Should total fetched assets", func() {
+		comp := GenerateCompression(s, DefaultLargest)
+		Expect(comp.Assets).To(Equal(4))
+		Expect(comp.Size).To(Equal(int64(110100)))
+		Expect(comp.TransferSize).To(Equal(int64(78100)))
+	})
+
+	It("Should list large uncompressed text", func() {
+		comp := GenerateCompression(s, DefaultLargest)
+		Expect(comp.Uncompressed).To(HaveLen(1))
+		Expect(comp.Uncompressed[0].URL).To(Equal("http://foo.faketld/main.js"))
+	})
+
+	It("Should list the heaviest assets", func() {
+		comp := GenerateCompression(s, 2)
+		Expect(comp.Largest).To(HaveLen(2))
+		Expect(comp.Largest[0].URL).To(Equal("http://foo.faketld/logo.png"))
+		Expect(comp.Largest[1].URL).To(Equal("http://foo.faketld/main.js"))
+	})
+
+	It("Should list no heaviest assets for negative numbers", func() {
+		Expect(GenerateCompression(s, -1).Largest).To(BeEmpty())
+	})
+
+	It("Should recognize text media types", func() {
+		Expect(IsText("text/plain")).To(BeTrue())
+		Expect(IsText("image/svg+xml")).To(BeTrue())
+		Expect(IsText("application/vnd.api+json")).To(BeTrue())
+		Expect(IsText("image/jpeg")).To(BeFalse())
+	})
+
+	It("Should write a text report", func() {
+		var buf bytes.Buffer
+		Expect(GenerateCompression(s, 1).WriteText(&buf)).To(Succeed())
+		Expect(buf.String()).To(ContainSubstring("4 asset(s), 76.3 KiB transferred, 107.5 KiB decoded"))
+		Expect(buf.String()).To(ContainSubstring("19.5 KiB  application/javascript  http://foo.faketld/main.js"))
+		Expect(buf.String()).To(ContainSubstring("48.8 KiB  -      http://foo.faketld/logo.png"))
+	})
+})
@@ -0,0 +1,226 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/kevinoconnor7/digitalocean-crawler/crawler"
+)
+
+// LinkChange is a link, identified by the URLs at either end
+type LinkChange struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+}
+
+// StatusChange is an asset whose status code differs between crawls
+type StatusChange struct {
+	URL    string `json:"url"`
+	Before int    `json:"before"`
+	After  int    `json:"after"`
+}
+
+// Redirect is an asset that redirects somewhere it didn't before
+type Redirect struct {
+	URL    string `json:"url"`
+	Before string `json:"before,omitempty"`
+	After  string `json:"after"`
+}
+
+// Diff lists what changed between two crawls of the same site
+// Assets are matched by URL, so indices may differ between the crawls
+type Diff struct {
+	AddedPages     []string       `json:"addedPages"`
+	RemovedPages   []string       `json:"removedPages"`
+	AddedLinks     []LinkChange   `json:"addedLinks"`
+	RemovedLinks   []LinkChange   `json:"removedLinks"`
+	StatusChanges  []StatusChange `json:"statusChanges"`
+	NewRedirects   []Redirect     `json:"newRedirects"`
+	ContentChanges []string       `json:"contentChanges"`
+}
+
+// GenerateDiff compares the crawl before with the crawl after
+// Status, redirect and content changes are only reported for assets that
+// were fetched in both crawls
+func GenerateDiff(before *crawler.Snapshot, after *crawler.Snapshot) *Diff {
+	d := &Diff{
+		AddedPages:     []string{},
+		RemovedPages:   []string{},
+		AddedLinks:     []LinkChange{},
+		RemovedLinks:   []LinkChange{},
+		StatusChanges:  []StatusChange{},
+		NewRedirects:   []Redirect{},
+		ContentChanges: []string{},
+	}
+
+	beforeAssets := assetsByURL(before)
+	afterAssets := assetsByURL(after)
+
+	for _, asset := range after.Nodes {
+		previous, ok := beforeAssets[asset.URL]
+
+		if !ok {
+			if asset.Type == crawler.PAGE {
+				d.AddedPages = append(d.AddedPages, asset.URL)
+			}
+			continue
+		}
+
+		if previous.StatusCode == 0 || asset.StatusCode == 0 {
+			continue
+		}
+
+		if previous.StatusCode != asset.StatusCode {
+			d.StatusChanges = append(d.StatusChanges, StatusChange{
+				URL:    asset.URL,
+				Before: previous.StatusCode,
+				After:  asset.StatusCode,
+			})
+		}
+
+		if asset.RedirectTo != "" && asset.RedirectTo != previous.RedirectTo {
+			d.NewRedirects = append(d.NewRedirects, Redirect{
+				URL:    asset.URL,
+				Before: previous.RedirectTo,
+				After:  asset.RedirectTo,
+			})
+		}
+
+		if previous.ContentHash != "" && asset.ContentHash != "" && previous.ContentHash != asset.ContentHash {
+			d.ContentChanges = append(d.ContentChanges, asset.URL)
+		}
+	}
+
+	for _, asset := range before.Nodes {
+		if _, ok := afterAssets[asset.URL]; !ok && asset.Type == crawler.PAGE {
+			d.RemovedPages = append(d.RemovedPages, asset.URL)
+		}
+	}
+
+	beforeLinks := linkSet(before)
+	afterLinks := linkSet(after)
+
+	for link := range afterLinks {
+		if !beforeLinks[link] {
+			d.AddedLinks = append(d.AddedLinks, link)
+		}
+	}
+
+	for link := range beforeLinks {
+		if !afterLinks[link] {
+			d.RemovedLinks = append(d.RemovedLinks, link)
+		}
+	}
+
+	sortLinks(d.AddedLinks)
+	sortLinks(d.RemovedLinks)
+
+	return d
+}
+
+// Empty reports whether nothing changed between the crawls
+func (d *Diff) Empty() bool {
+	return len(d.AddedPages) == 0 &&
+		len(d.RemovedPages) == 0 &&
+		len(d.AddedLinks) == 0 &&
+		len(d.RemovedLinks) == 0 &&
+		len(d.StatusChanges) == 0 &&
+		len(d.NewRedirects) == 0 &&
+		len(d.ContentChanges) == 0
+}
+
+// WriteText writes the diff in a human readable form
+func (d *Diff) WriteText(w io.Writer) error {
+	if d.Empty() {
+		_, err := fmt.Fprintln(w, "No changes")
+		return err
+	}
+
+	var lines []string
+
+	section := func(title string, count int) {
+		if count > 0 {
+			if len(lines) > 0 {
+				lines = append(lines, "")
+			}
+			lines = append(lines, fmt.Sprintf("%s (%d)", title, count))
+		}
+	}
+
+	section("Added pages", len(d.AddedPages))
+	for _, url := range d.AddedPages {
+		lines = append(lines, "  + "+url)
+	}
+
+	section("Removed pages", len(d.RemovedPages))
+	for _, url := range d.RemovedPages {
+		lines = append(lines, "  - "+url)
+	}
+
+	section("Added links", len(d.AddedLinks))
+	for _, link := range d.AddedLinks {
+		lines = append(lines, fmt.Sprintf("  + %s -> %s", link.Source, link.Target))
+	}
+
+	section("Removed links", len(d.RemovedLinks))
+	for _, link := range d.RemovedLinks {
+		lines = append(lines, fmt.Sprintf("  - %s -> %s", link.Source, link.Target))
+	}
+
+	section("Status changes", len(d.StatusChanges))
+	for _, change := range d.StatusChanges {
+		lines = append(lines, fmt.Sprintf("  %s: %d -> %d", change.URL, change.Before, change.After))
+	}
+
+	section("New redirects", len(d.NewRedirects))
+	for _, redirect := range d.NewRedirects {
+		lines = append(lines, fmt.Sprintf("  %s -> %s", redirect.URL, redirect.After))
+	}
+
+	section("Content changes", len(d.ContentChanges))
+	for _, url := range d.ContentChanges {
+		lines = append(lines, "  ~ "+url)
+	}
+
+	for _, line := range lines {
+		_, err := fmt.Fprintln(w, line)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func assetsByURL(s *crawler.Snapshot) map[string]*crawler.Asset {
+	assets := make(map[string]*crawler.Asset, len(s.Nodes))
+
+	for i := range s.Nodes {
+		assets[s.Nodes[i].URL] = &s.Nodes[i]
+	}
+
+	return assets
+}
+
+func linkSet(s *crawler.Snapshot) map[LinkChange]bool {
+	links := make(map[LinkChange]bool, len(s.Links))
+
+	for _, link := range s.Links {
+		links[LinkChange{
+			Source: s.Nodes[link.Source].URL,
+			Target: s.Nodes[link.Target].URL,
+		}] = true
+	}
+
+	return links
+}
+
+func sortLinks(links []LinkChange) {
+	sort.Slice(links, func(i, j int) bool {
+		if links[i].Source != links[j].Source {
+			return links[i].Source < links[j].Source
+		}
+		return links[i].Target < links[j].Target
+	})
+}
@@ -0,0 +1,99 @@
+package report_test
+
+import (
+	"bytes"
+
+	"github.com/kevinoconnor7/digitalocean-crawler/crawler"
+	. "github.com/kevinoconnor7/digitalocean-crawler/report"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Diff", func() {
+	var before, after *crawler.Snapshot
+
+	BeforeEach(func() {
+		before = &crawler.Snapshot{
+			Nodes: []crawler.Asset{
+				{URL: "http://foo.faketld/", Type: crawler.PAGE, StatusCode: 200, ContentHash: "a"},
+				{URL: "http://foo.faketld/old", Type: crawler.PAGE, StatusCode: 200},
+				{URL: "http://foo.faketld/moved", Type: crawler.PAGE, StatusCode: 200},
+				{URL: "http://foo.faketld/main.js", Type: crawler.SCRIPTS},
+			},
+			Links: []crawler.Link{
+				{Source: 0, Target: 1, Value: 1},
+				{Source: 0, Target: 2, Value: 1},
+				{Source: 0, Target: 3, Value: 1},
+			},
+		}
+
+		after = &crawler.Snapshot{
+			Nodes: []crawler.Asset{
+				{URL: "http://foo.faketld/", Type: crawler.PAGE, StatusCode: 200, ContentHash: "b"},
+				{URL: "http://foo.faketld/moved", Type: crawler.PAGE, StatusCode: 301, RedirectTo: "http://foo.faketld/new"},
+				{URL: "http://foo.faketld/main.js", Type: crawler.SCRIPTS},
+				{URL: "http://foo.faketld/new", Type: crawler.PAGE, StatusCode: 200},
+			},
+			Links: []crawler.Link{
+				{Source: 0, Target: 1, Value: 1},
+				{Source: 0, Target: 2, Value: 1},
+				{Source: 1, Target: 3, Value: 1},
+			},
+		}
+	})
+
+	It("Should report added and removed pages", func() {
+		d := GenerateDiff(before, after)
+
+		Expect(d.AddedPages).To(Equal([]string{"http://foo.faketld/new"}))
+		Expect(d.RemovedPages).To(Equal([]string{"http://foo.faketld/old"}))
+	})
+
+	It("Should match links by URL", func() {
+		d := GenerateDiff(before, after)
+
+		Expect(d.AddedLinks).To(Equal([]LinkChange{
+			{Source: "http://foo.faketld/moved", Target: "http://foo.faketld/new"},
+		}))
+		Expect(d.RemovedLinks).To(Equal([]LinkChange{
+			{Source: "http://foo.faketld/", Target: "http://foo.faketld/old"},
+		}))
+	})
+
+	It("Should report status changes and new redirects", func() {
+		d := GenerateDiff(before, after)
+
+		Expect(d.StatusChanges).To(Equal([]StatusChange{
+			{URL: "http://foo.faketld/moved", Before: 200, After: 301},
+		}))
+		Expect(d.NewRedirects).To(Equal([]Redirect{
+			{URL: "http://foo.faketld/moved", After: "http://foo.faketld/new"},
+		}))
+	})
+
+	It("Should report content changes", func() {
+		d := GenerateDiff(before, after)
+
+		Expect(d.ContentChanges).To(Equal([]string{"http://foo.faketld/"}))
+	})
+
+	It("Should be empty for identical crawls", func() {
+		d := GenerateDiff(before, before)
+
+		Expect(d.Empty()).To(BeTrue())
+
+		var out bytes.Buffer
+		d.WriteText(&out)
+		Expect(out.String()).To(Equal("No changes\n"))
+	})
+
+	It("Should write each change as text", func() {
+		var out bytes.Buffer
+		GenerateDiff(before, after).WriteText(&out)
+
+		Expect(out.String()).To(ContainSubstring("Added pages (1)\n  + http://foo.faketld/new\n"))
+		Expect(out.String()).To(ContainSubstring("  http://foo.faketld/moved: 200 -> 301\n"))
+		Expect(out.String()).To(ContainSubstring("  ~ http://foo.faketld/\n"))
+	})
+})
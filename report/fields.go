@@ -0,0 +1,76 @@
+package report
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"sort"
+
+	"github.com/kevinoconnor7/digitalocean-crawler/crawler"
+)
+
+// FieldRow is the custom fields extracted from a single page
+type FieldRow struct {
+	URL    string            `json:"url"`
+	Fields map[string]string `json:"fields"`
+}
+
+// FieldRows lists every page of the snapshot that custom fields were
+// extracted from, in crawl order
+func FieldRows(s *crawler.Snapshot) []FieldRow {
+	rows := []FieldRow{}
+
+	for _, node := range s.Nodes {
+		if len(node.Fields) > 0 {
+			rows = append(rows, FieldRow{URL: node.URL, Fields: node.Fields})
+		}
+	}
+
+	return rows
+}
+
+// WriteFieldsJSONL writes each page's custom fields as a line of JSON
+func WriteFieldsJSONL(w io.Writer, s *crawler.Snapshot) error {
+	encoder := json.NewEncoder(w)
+
+	for _, row := range FieldRows(s) {
+		err := encoder.Encode(row)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// WriteFieldsCSV writes each page's custom fields as a row of CSV, with a
+// column for the URL followed by every field name in order
+func WriteFieldsCSV(w io.Writer, s *crawler.Snapshot) error {
+	rows := FieldRows(s)
+
+	names := []string{}
+	seen := make(map[string]bool)
+	for _, row := range rows {
+		for name := range row.Fields {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+
+	out := csv.NewWriter(w)
+	out.Write(append([]string{"url"}, names...))
+
+	for _, row := range rows {
+		record := []string{row.URL}
+		for _, name := range names {
+			record = append(record, row.Fields[name])
+		}
+		out.Write(record)
+	}
+
+	out.Flush()
+	return out.Error()
+}
@@ -0,0 +1,44 @@
+package report_test
+
+import (
+	"bytes"
+
+	"github.com/kevinoconnor7/digitalocean-crawler/crawler"
+	. "github.com/kevinoconnor7/digitalocean-crawler/report"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Fields", func() {
+	s := &crawler.Snapshot{
+		Nodes: []crawler.Asset{
+			{URL: "http://foo.faketld/", Fields: map[string]string{"title": "Home"}},
+			{URL: "http://foo.faketld/main.js"},
+			{URL: "http://foo.faketld/pricing", Fields: map[string]string{"title": "Pricing", "price": "$4, monthly"}},
+		},
+	}
+
+	It("Should only list pages with fields", func() {
+		Expect(FieldRows(s)).To(HaveLen(2))
+	})
+
+	It("Should write JSON Lines", func() {
+		var out bytes.Buffer
+		Expect(WriteFieldsJSONL(&out, s)).To(Succeed())
+
+		Expect(out.String()).To(Equal(
+			`{"url":"http://foo.faketld/","fields":{"title":"Home"}}` + "\n" +
+				`{"url":"http://foo.faketld/pricing","fields":{"price":"$4, monthly","title":"Pricing"}}` + "\n"))
+	})
+
+	It("Should write CSV with a column per field", func() {
+		var out bytes.Buffer
+		Expect(WriteFieldsCSV(&out, s)).To(Succeed())
+
+		Expect(out.String()).To(Equal(
+			"url,price,title\n" +
+				"http://foo.faketld/,,Home\n" +
+				"http://foo.faketld/pricing,\"$4, monthly\",Pricing\n"))
+	})
+})
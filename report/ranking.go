@@ -0,0 +1,76 @@
+package report
+
+import (
+	"sort"
+
+	"github.com/kevinoconnor7/digitalocean-crawler/crawler"
+	"github.com/kevinoconnor7/digitalocean-crawler/graph"
+)
+
+// RankedPage is an internal page with its position in a Ranking
+type RankedPage struct {
+	Rank  int    `json:"rank"`
+	Index int    `json:"index"`
+	URL   string `json:"url"`
+	graph.Metrics
+}
+
+// Ranking orders the internal pages of a crawl by PageRank and calls out
+// structural problems in the link graph
+type Ranking struct {
+	Pages       []RankedPage `json:"pages"`
+	DeadEnds    []string     `json:"deadEnds"`
+	Orphans     []string     `json:"orphans"`
+	Unreachable []string     `json:"unreachable"`
+	Components  int          `json:"components"`
+}
+
+// GenerateRanking ranks the pages of a snapshot by the metrics Analyze
+// attached to them, leaving the snapshot as it is
+// Pages of snapshots that haven't been analyzed aren't ranked
+func GenerateRanking(s *crawler.Snapshot) *Ranking {
+	r := &Ranking{
+		Pages:       []RankedPage{},
+		DeadEnds:    []string{},
+		Orphans:     []string{},
+		Unreachable: []string{},
+	}
+	components := make(map[int]bool)
+
+	for i, node := range s.Nodes {
+		if node.Metrics == nil {
+			continue
+		}
+
+		r.Pages = append(r.Pages, RankedPage{
+			Index:   i,
+			URL:     node.URL,
+			Metrics: *node.Metrics,
+		})
+		components[node.Metrics.Component] = true
+
+		if node.Metrics.DeadEnd {
+			r.DeadEnds = append(r.DeadEnds, node.URL)
+		}
+
+		if node.Metrics.Orphan {
+			r.Orphans = append(r.Orphans, node.URL)
+		}
+
+		if node.Metrics.Depth == -1 {
+			r.Unreachable = append(r.Unreachable, node.URL)
+		}
+	}
+
+	sort.SliceStable(r.Pages, func(i, j int) bool {
+		return r.Pages[i].PageRank > r.Pages[j].PageRank
+	})
+
+	for i := range r.Pages {
+		r.Pages[i].Rank = i + 1
+	}
+
+	r.Components = len(components)
+
+	return r
+}
@@ -0,0 +1,76 @@
+package report_test
+
+import (
+	u "net/url"
+
+	"github.com/kevinoconnor7/digitalocean-crawler/crawler"
+	. "github.com/kevinoconnor7/digitalocean-crawler/report"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Ranking", func() {
+	var r *Ranking
+
+	BeforeEach(func() {
+		url, _ := u.Parse("http://foo.faketld")
+		c := crawler.GenerateCrawler(url)
+		seed := crawler.GenerateAsset("http://foo.faketld/", crawler.PAGE)
+		c.StoreAsset(seed)
+		c.HandleURL("/popular", seed, crawler.PAGE)
+		c.HandleURL("/other", seed, crawler.PAGE)
+		c.HandleURL("/main.js", seed, crawler.SCRIPTS)
+
+		other := c.Assets["http://foo.faketld/other"]
+		c.HandleURL("/popular", other, crawler.PAGE)
+
+		popular := c.Assets["http://foo.faketld/popular"]
+		popular.StatusCode = 200
+
+		orphan := crawler.GenerateAsset("http://foo.faketld/orphan", crawler.PAGE)
+		orphan.InSitemap = true
+		c.StoreAsset(orphan)
+
+		s := c.Snapshot()
+		s.Analyze()
+		r = GenerateRanking(s)
+	})
+
+	It("Should not analyze the snapshot itself", func() {
+		url, _ := u.Parse("http://foo.faketld")
+		c := crawler.GenerateCrawler(url)
+		c.StoreAsset(crawler.GenerateAsset("http://foo.faketld/", crawler.PAGE))
+
+		s := c.Snapshot()
+		Expect(GenerateRanking(s).Pages).To(BeEmpty())
+		Expect(s.Nodes[0].Metrics).To(BeNil())
+	})
+
+	It("Should only rank internal pages", func() {
+		Expect(len(r.Pages)).To(Equal(4))
+	})
+
+	It("Should order pages by PageRank", func() {
+		Expect(r.Pages[0].URL).To(Equal("http://foo.faketld/popular"))
+		Expect(r.Pages[0].Rank).To(Equal(1))
+		Expect(r.Pages[0].InDegree).To(Equal(2))
+
+		for i := 1; i < len(r.Pages); i++ {
+			Expect(r.Pages[i].PageRank).To(BeNumerically("<=", r.Pages[i-1].PageRank))
+		}
+	})
+
+	It("Should report dead ends that were fetched", func() {
+		Expect(r.DeadEnds).To(Equal([]string{"http://foo.faketld/popular"}))
+	})
+
+	It("Should report orphans from the sitemap", func() {
+		Expect(r.Orphans).To(Equal([]string{"http://foo.faketld/orphan"}))
+		Expect(r.Unreachable).To(Equal([]string{"http://foo.faketld/orphan"}))
+	})
+
+	It("Should count components", func() {
+		Expect(r.Components).To(Equal(4))
+	})
+})
@@ -0,0 +1,197 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/kevinoconnor7/digitalocean-crawler/crawler"
+)
+
+// subresourceTypes are the asset types a page depends on to render
+var subresourceTypes = []crawler.ContentType{crawler.STYLESHEETS, crawler.SCRIPTS, crawler.IMAGES}
+
+// TypeWeight totals the subresources of one type
+type TypeWeight struct {
+	Count int   `json:"count"`
+	Bytes int64 `json:"bytes"`
+}
+
+// PageWeight is what an internal page costs to load with everything it
+// links to as a stylesheet, script or image
+type PageWeight struct {
+	URL   string `json:"url"`
+	Index int    `json:"index"`
+
+	// Size is the weight of the page itself
+	Size int64 `json:"size"`
+
+	Resources int                                 `json:"resources"`
+	ByType    map[crawler.ContentType]*TypeWeight `json:"byType"`
+
+	// Unsized counts the subresources whose size isn't known, such as
+	// those that weren't fetched
+	Unsized int `json:"unsized,omitempty"`
+
+	// Total is the page and subresource bytes together
+	Total int64 `json:"total"`
+}
+
+// SharedResource is a subresource with the number of pages that link to it
+type SharedResource struct {
+	AssetWeight
+	Pages int `json:"pages"`
+}
+
+// PageWeights rolls up the subresources of every fetched internal page
+type PageWeights struct {
+	Pages int `json:"pages"`
+
+	// Heaviest lists the pages with the largest totals
+	Heaviest []PageWeight `json:"heaviest"`
+
+	// Shared lists the subresources linked from the most pages
+	Shared []SharedResource `json:"shared"`
+}
+
+// GeneratePageWeights weighs every fetched internal page in the snapshot,
+// listing the top number of the heaviest pages and most shared resources,
+// or none if it's negative
+func GeneratePageWeights(s *crawler.Snapshot, top int) *PageWeights {
+	if top < 0 {
+		top = 0
+	}
+
+	w := &PageWeights{
+		Heaviest: []PageWeight{},
+		Shared:   []SharedResource{},
+	}
+
+	pages := make(map[int]*PageWeight)
+	shared := make(map[int]*SharedResource)
+
+	for i, node := range s.Nodes {
+		if node.Type != crawler.PAGE || node.External || node.StatusCode < 200 || node.StatusCode >= 300 {
+			continue
+		}
+
+		page := &PageWeight{
+			URL:    node.URL,
+			Index:  i,
+			Size:   weigh(i, node).Weight(),
+			ByType: make(map[crawler.ContentType]*TypeWeight),
+		}
+		for _, t := range subresourceTypes {
+			page.ByType[t] = &TypeWeight{}
+		}
+		page.Total = page.Size
+
+		pages[i] = page
+	}
+
+	for _, link := range s.Links {
+		page, ok := pages[link.Source]
+		if !ok || link.Target < 0 || link.Target >= len(s.Nodes) {
+			continue
+		}
+
+		node := s.Nodes[link.Target]
+		total, ok := page.ByType[node.Type]
+		if !ok {
+			continue
+		}
+
+		resource := weigh(link.Target, node)
+		bytes := resource.Weight()
+
+		page.Resources++
+		total.Count++
+		total.Bytes += bytes
+		page.Total += bytes
+
+		if node.StatusCode == 0 {
+			page.Unsized++
+		}
+
+		r, ok := shared[link.Target]
+		if !ok {
+			r = &SharedResource{AssetWeight: resource}
+			shared[link.Target] = r
+		}
+		r.Pages++
+	}
+
+	w.Pages = len(pages)
+
+	for _, page := range pages {
+		w.Heaviest = append(w.Heaviest, *page)
+	}
+
+	sort.Slice(w.Heaviest, func(i, j int) bool {
+		if w.Heaviest[i].Total != w.Heaviest[j].Total {
+			return w.Heaviest[i].Total > w.Heaviest[j].Total
+		}
+		return w.Heaviest[i].Index < w.Heaviest[j].Index
+	})
+
+	if top < len(w.Heaviest) {
+		w.Heaviest = w.Heaviest[:top]
+	}
+
+	// A resource on a single page isn't shared
+	for _, r := range shared {
+		if r.Pages > 1 {
+			w.Shared = append(w.Shared, *r)
+		}
+	}
+
+	sort.Slice(w.Shared, func(i, j int) bool {
+		if w.Shared[i].Pages != w.Shared[j].Pages {
+			return w.Shared[i].Pages > w.Shared[j].Pages
+		}
+		if w.Shared[i].Weight() != w.Shared[j].Weight() {
+			return w.Shared[i].Weight() > w.Shared[j].Weight()
+		}
+		return w.Shared[i].Index < w.Shared[j].Index
+	})
+
+	if top < len(w.Shared) {
+		w.Shared = w.Shared[:top]
+	}
+
+	return w
+}
+
+// WriteText writes the page weights in a human readable form
+func (w *PageWeights) WriteText(out io.Writer) error {
+	_, err := fmt.Fprintf(out, "%d page(s)\n\nHeaviest %d page(s)\n", w.Pages, len(w.Heaviest))
+	if err != nil {
+		return err
+	}
+
+	for _, page := range w.Heaviest {
+		_, err = fmt.Fprintf(out, "  %10s  %3d resource(s)  css %s, js %s, img %s  %s\n",
+			formatBytes(page.Total), page.Resources,
+			formatBytes(page.ByType[crawler.STYLESHEETS].Bytes),
+			formatBytes(page.ByType[crawler.SCRIPTS].Bytes),
+			formatBytes(page.ByType[crawler.IMAGES].Bytes),
+			page.URL)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = fmt.Fprintf(out, "\nMost shared %d resource(s)\n", len(w.Shared))
+	if err != nil {
+		return err
+	}
+
+	for _, r := range w.Shared {
+		_, err = fmt.Fprintf(out, "  %4d page(s)  %10s  %s\n", r.Pages, formatBytes(r.Weight()), r.URL)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,103 @@
+package report_test
+
+import (
+	"bytes"
+	u "net/url"
+
+	"github.com/kevinoconnor7/digitalocean-crawler/crawler"
+	. "github.com/kevinoconnor7/digitalocean-crawler/report"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PageWeights", func() {
+	var s *crawler.Snapshot
+
+	BeforeEach(func() {
+		url, _ := u.Parse("http://foo.faketld")
+		c := crawler.GenerateCrawlerWithConfig(url, crawler.Config{TrackExternal: true})
+
+		fetched := func(url string, size int64) {
+			asset := c.Assets[url]
+			asset.StatusCode = 200
+			asset.Size = size
+		}
+
+		home := crawler.GenerateAsset("http://foo.faketld/", crawler.PAGE)
+		c.StoreAsset(home)
+		fetched("http://foo.faketld/", 1000)
+
+		c.HandleURL("/about", home, crawler.PAGE)
+		c.HandleURL("/site.css", home, crawler.STYLESHEETS)
+		c.HandleURL("/app.js", home, crawler.SCRIPTS)
+		c.HandleURL("/hero.jpg", home, crawler.IMAGES)
+		c.HandleURL("http://cdn.faketld/font.css", home, crawler.STYLESHEETS)
+		fetched("http://foo.faketld/about", 500)
+		fetched("http://foo.faketld/site.css", 2000)
+		fetched("http://foo.faketld/app.js", 10000)
+		fetched("http://foo.faketld/hero.jpg", 50000)
+
+		about := c.Assets["http://foo.faketld/about"]
+		c.HandleURL("/site.css", about, crawler.STYLESHEETS)
+		c.HandleURL("/site.css", about, crawler.STYLESHEETS)
+		c.HandleURL("/app.js", about, crawler.SCRIPTS)
+
+		c.HandleURL("/missing", home, crawler.PAGE)
+		c.Assets["http://foo.faketld/missing"].StatusCode = 404
+
+		s = c.Snapshot()
+	})
+
+	It("Should only weigh fetched internal pages", func() {
+		w := GeneratePageWeights(s, DefaultLargest)
+		Expect(w.Pages).To(Equal(2))
+		Expect(w.Heaviest).To(HaveLen(2))
+	})
+
+	It("Should total subresources by type", func() {
+		home := GeneratePageWeights(s, DefaultLargest).Heaviest[0]
+		Expect(home.URL).To(Equal("http://foo.faketld/"))
+		Expect(home.Size).To(Equal(int64(1000)))
+		Expect(home.Resources).To(Equal(4))
+		Expect(home.Unsized).To(Equal(1))
+		Expect(home.ByType[crawler.STYLESHEETS]).To(Equal(&TypeWeight{Count: 2, Bytes: 2000}))
+		Expect(home.ByType[crawler.SCRIPTS]).To(Equal(&TypeWeight{Count: 1, Bytes: 10000}))
+		Expect(home.ByType[crawler.IMAGES]).To(Equal(&TypeWeight{Count: 1, Bytes: 50000}))
+		Expect(home.Total).To(Equal(int64(63000)))
+	})
+
+	It("Should count repeated resources once", func() {
+		about := GeneratePageWeights(s, DefaultLargest).Heaviest[1]
+		Expect(about.Resources).To(Equal(2))
+		Expect(about.Total).To(Equal(int64(12500)))
+	})
+
+	It("Should list resources shared by several pages", func() {
+		w := GeneratePageWeights(s, DefaultLargest)
+		Expect(w.Shared).To(HaveLen(2))
+		Expect(w.Shared[0].URL).To(Equal("http://foo.faketld/app.js"))
+		Expect(w.Shared[0].Pages).To(Equal(2))
+		Expect(w.Shared[1].URL).To(Equal("http://foo.faketld/site.css"))
+	})
+
+	It("Should limit the lists", func() {
+		w := GeneratePageWeights(s, 1)
+		Expect(w.Heaviest).To(HaveLen(1))
+		Expect(w.Shared).To(HaveLen(1))
+	})
+
+	It("Should list nothing for negative limits", func() {
+		w := GeneratePageWeights(s, -1)
+		Expect(w.Heaviest).To(BeEmpty())
+		Expect(w.Shared).To(BeEmpty())
+		Expect(w.Pages).ToNot(BeZero())
+	})
+
+	It("Should write a text report", func() {
+		var buf bytes.Buffer
+		Expect(GeneratePageWeights(s, DefaultLargest).WriteText(&buf)).To(Succeed())
+		Expect(buf.String()).To(ContainSubstring("61.5 KiB    4 resource(s)  css 2.0 KiB, js 9.8 KiB, img 48.8 KiB  http://foo.faketld/"))
+		Expect(buf.String()).To(ContainSubstring("   2 page(s)     9.8 KiB  http://foo.faketld/app.js"))
+	})
+})
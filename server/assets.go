@@ -0,0 +1,137 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/kevinoconnor7/digitalocean-crawler/crawler"
+)
+
+// maxAssetResults caps how many assets a single search returns
+const maxAssetResults = 100
+
+// handleAssets searches the crawl's assets
+// Supports q, type (comma separated), status, maxDepth and external
+func (s *Server) handleAssets(w http.ResponseWriter, r *http.Request, job *Job) {
+	filter, err := parseFilter(r)
+
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	nodes := job.Snapshot().Find(filter)
+	if len(nodes) > maxAssetResults {
+		nodes = nodes[:maxAssetResults]
+	}
+
+	writeJSON(w, http.StatusOK, nodes)
+}
+
+func (s *Server) handleAsset(w http.ResponseWriter, r *http.Request, job *Job) {
+	index, err := strconv.Atoi(r.PathValue("asset"))
+
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("Invalid asset index %q", r.PathValue("asset")))
+		return
+	}
+
+	node, err := job.Snapshot().Node(index)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, node)
+}
+
+func (s *Server) handleNeighbors(w http.ResponseWriter, r *http.Request, job *Job) {
+	index, err := strconv.Atoi(r.PathValue("asset"))
+
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("Invalid asset index %q", r.PathValue("asset")))
+		return
+	}
+
+	neighborhood, err := job.Snapshot().Neighbors(index)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, neighborhood)
+}
+
+func parseFilter(r *http.Request) (crawler.Filter, error) {
+	query := r.URL.Query()
+	filter := crawler.Filter{
+		Query: query.Get("q"),
+	}
+
+	if types := query.Get("type"); types != "" {
+		for _, t := range strings.Split(types, ",") {
+			contentType, err := strconv.Atoi(t)
+			if err != nil {
+				return filter, fmt.Errorf("Invalid type %q", t)
+			}
+			filter.Types = append(filter.Types, crawler.ContentType(contentType))
+		}
+	}
+
+	if status := query.Get("status"); status != "" {
+		code, err := strconv.Atoi(status)
+		if err != nil {
+			return filter, fmt.Errorf("Invalid status %q", status)
+		}
+		filter.StatusCode = code
+	}
+
+	if maxDepth := query.Get("maxDepth"); maxDepth != "" {
+		depth, err := strconv.Atoi(maxDepth)
+		if err != nil {
+			return filter, fmt.Errorf("Invalid maxDepth %q", maxDepth)
+		}
+		filter.MaxDepth = &depth
+	}
+
+	if external := query.Get("external"); external != "" {
+		value, err := strconv.ParseBool(external)
+		if err != nil {
+			return filter, fmt.Errorf("Invalid external %q", external)
+		}
+		filter.External = &value
+	}
+
+	return filter, nil
+}
+
+// handlePath finds the shortest click paths between the from and to URLs
+// Supports ignoreNofollow, ignoreBoilerplate, ignoreInferred and limit, which
+// is capped at crawler.MaxPathLimit
+func (s *Server) handlePath(w http.ResponseWriter, r *http.Request, job *Job) {
+	query := r.URL.Query()
+	options := crawler.PathOptions{
+		IgnoreNofollow:    query.Get("ignoreNofollow") == "true",
+		IgnoreBoilerplate: query.Get("ignoreBoilerplate") == "true",
+		IgnoreInferred:    query.Get("ignoreInferred") == "true",
+	}
+
+	if limit := query.Get("limit"); limit != "" {
+		value, err := strconv.Atoi(limit)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("Invalid limit %q", limit))
+			return
+		}
+		options.Limit = value
+	}
+
+	result, err := job.Crawler.Snapshot().ShortestPaths(query.Get("from"), query.Get("to"), options)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, result)
+}
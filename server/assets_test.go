@@ -0,0 +1,293 @@
+package server_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	u "net/url"
+	"strings"
+
+	"github.com/kevinoconnor7/digitalocean-crawler/crawler"
+	"github.com/kevinoconnor7/digitalocean-crawler/extract"
+	"github.com/kevinoconnor7/digitalocean-crawler/report"
+	. "github.com/kevinoconnor7/digitalocean-crawler/server"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Assets", func() {
+	var (
+		site *httptest.Server
+		ts   *httptest.Server
+		job  *Job
+	)
+
+	get := func(path string, value interface{}) int {
+		resp, err := http.Get(ts.URL + path)
+		Expect(err).ToNot(HaveOccurred())
+		defer resp.Body.Close()
+
+		if value != nil {
+			Expect(json.NewDecoder(resp.Body).Decode(value)).To(Succeed())
+		}
+
+		return resp.StatusCode
+	}
+
+	BeforeEach(func() {
+		site = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/" {
+				w.Write([]byte(`<a href="/about"></a><script src="/main.js"></script>`))
+				return
+			}
+			if r.URL.Path == "/about" {
+				w.Write([]byte(`<a href="/"></a>`))
+			}
+		}))
+
+		manager := GenerateManager()
+		ts = httptest.NewServer(GenerateServer(manager))
+
+		var err error
+		job, err = manager.Start(CrawlOptions{Seed: site.URL + "/"})
+		Expect(err).ToNot(HaveOccurred())
+		Eventually(job.Crawler.State).Should(Equal(crawler.FINISHED))
+	})
+
+	AfterEach(func() {
+		ts.Close()
+		site.Close()
+	})
+
+	Describe("GET /crawls/{id}/assets", func() {
+		It("Should search assets", func() {
+			var nodes []crawler.Node
+			Expect(get("/crawls/"+job.ID+"/assets?q=about", &nodes)).To(Equal(http.StatusOK))
+			Expect(len(nodes)).To(Equal(1))
+			Expect(nodes[0].URL).To(Equal(site.URL + "/about"))
+		})
+
+		It("Should filter assets", func() {
+			var nodes []crawler.Node
+			get("/crawls/"+job.ID+"/assets?type=3&status=200&maxDepth=1&external=false", &nodes)
+			Expect(len(nodes)).To(Equal(1))
+			Expect(nodes[0].Type).To(Equal(crawler.SCRIPTS))
+		})
+
+		It("Should reject invalid filters", func() {
+			Expect(get("/crawls/"+job.ID+"/assets?maxDepth=deep", nil)).To(Equal(http.StatusBadRequest))
+		})
+	})
+
+	Describe("GET /crawls/{id}/assets/{asset}", func() {
+		It("Should return the asset", func() {
+			var node crawler.Node
+			Expect(get("/crawls/"+job.ID+"/assets/0", &node)).To(Equal(http.StatusOK))
+			Expect(node.URL).To(Equal(site.URL + "/"))
+			Expect(node.StatusCode).To(Equal(http.StatusOK))
+		})
+
+		It("Should 404 for unknown assets", func() {
+			Expect(get("/crawls/"+job.ID+"/assets/99", nil)).To(Equal(http.StatusNotFound))
+		})
+	})
+
+	Describe("GET /crawls/{id}/path", func() {
+		It("Should return the shortest paths", func() {
+			var result crawler.PathResult
+			path := "/crawls/" + job.ID + "/path?from=" + u.QueryEscape(site.URL+"/about") + "&to=" + u.QueryEscape(site.URL+"/main.js")
+			Expect(get(path, &result)).To(Equal(http.StatusOK))
+			Expect(result.Clicks).To(Equal(2))
+			Expect(len(result.Paths)).To(Equal(1))
+		})
+
+		It("Should 404 for unknown URLs", func() {
+			Expect(get("/crawls/"+job.ID+"/path?from=nope&to=nope", nil)).To(Equal(http.StatusNotFound))
+		})
+
+		It("Should match URLs written differently from how they were stored", func() {
+			var result crawler.PathResult
+			path := "/crawls/" + job.ID + "/path?from=" + u.QueryEscape(strings.ToUpper(site.URL[:4])+site.URL[4:]) + "&to=" + u.QueryEscape("/main.js")
+			Expect(get(path, &result)).To(Equal(http.StatusOK))
+			Expect(result.From.URL).To(Equal(site.URL + "/"))
+			Expect(result.Clicks).To(Equal(1))
+		})
+	})
+
+	Describe("GET /api/path", func() {
+		It("Should find paths in the first crawl", func() {
+			var result crawler.PathResult
+			Expect(get("/api/path?from=/about&to=/main.js", &result)).To(Equal(http.StatusOK))
+			Expect(result.Clicks).To(Equal(2))
+		})
+
+		It("Should find paths in the crawl asked for", func() {
+			var result crawler.PathResult
+			Expect(get("/api/path?crawl="+job.ID+"&from=/about&to=/main.js", &result)).To(Equal(http.StatusOK))
+			Expect(result.Clicks).To(Equal(2))
+
+			Expect(get("/api/path?crawl=nope&from=/about&to=/main.js", nil)).To(Equal(http.StatusNotFound))
+		})
+	})
+
+	Describe("GET /crawls/{id}/assets/{asset}/neighbors", func() {
+		It("Should return inbound and outbound links", func() {
+			var n crawler.Neighborhood
+			Expect(get("/crawls/"+job.ID+"/assets/0/neighbors", &n)).To(Equal(http.StatusOK))
+			Expect(len(n.Outbound)).To(Equal(2))
+			Expect(len(n.Inbound)).To(Equal(1))
+		})
+	})
+})
+
+var _ = Describe("Report", func() {
+	var (
+		site *httptest.Server
+		ts   *httptest.Server
+		job  *Job
+
+		manager *Manager
+	)
+
+	BeforeEach(func() {
+		site = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/" {
+				w.Write([]byte(`<a href="/about"></a>`))
+			}
+		}))
+
+		manager = GenerateManager()
+		ts = httptest.NewServer(GenerateServer(manager))
+
+		var err error
+		job, err = manager.Start(CrawlOptions{Seed: site.URL + "/"})
+		Expect(err).ToNot(HaveOccurred())
+		Eventually(job.Crawler.State).Should(Equal(crawler.FINISHED))
+	})
+
+	AfterEach(func() {
+		ts.Close()
+		site.Close()
+	})
+
+	Describe("GET /crawls/{id}/report", func() {
+		It("Should rank the crawled pages", func() {
+			resp, err := http.Get(ts.URL + "/crawls/" + job.ID + "/report")
+			Expect(err).ToNot(HaveOccurred())
+			defer resp.Body.Close()
+
+			var ranking report.Ranking
+			Expect(json.NewDecoder(resp.Body).Decode(&ranking)).To(Succeed())
+			Expect(len(ranking.Pages)).To(Equal(2))
+			Expect(ranking.Pages[0].URL).To(Equal(site.URL + "/about"))
+			Expect(ranking.DeadEnds).To(Equal([]string{site.URL + "/about"}))
+		})
+	})
+
+	Describe("GET /crawls/{id}/fields", func() {
+		BeforeEach(func() {
+			fields, err := extract.LoadFields(strings.NewReader(`{"fields": {"link": "a@href"}}`))
+			Expect(err).ToNot(HaveOccurred())
+
+			job, err = manager.Start(CrawlOptions{Seed: site.URL + "/", Fields: fields})
+			Expect(err).ToNot(HaveOccurred())
+			Eventually(job.Crawler.State).Should(Equal(crawler.FINISHED))
+		})
+
+		It("Should export JSON Lines", func() {
+			resp, err := http.Get(ts.URL + "/crawls/" + job.ID + "/fields")
+			Expect(err).ToNot(HaveOccurred())
+			defer resp.Body.Close()
+
+			body, _ := io.ReadAll(resp.Body)
+			Expect(string(body)).To(Equal(fmt.Sprintf(`{"url":"%s/","fields":{"link":"/about"}}`+"\n", site.URL)))
+		})
+
+		It("Should export CSV", func() {
+			resp, err := http.Get(ts.URL + "/crawls/" + job.ID + "/fields?format=csv")
+			Expect(err).ToNot(HaveOccurred())
+			defer resp.Body.Close()
+
+			body, _ := io.ReadAll(resp.Body)
+			Expect(resp.Header.Get("Content-Type")).To(Equal("text/csv"))
+			Expect(string(body)).To(Equal(fmt.Sprintf("url,link\n%s/,/about\n", site.URL)))
+		})
+	})
+
+	Describe("GET /crawls/{id}/compression", func() {
+		It("Should weigh the crawled assets", func() {
+			resp, err := http.Get(ts.URL + "/crawls/" + job.ID + "/compression?largest=1")
+			Expect(err).ToNot(HaveOccurred())
+			defer resp.Body.Close()
+
+			var c report.Compression
+			Expect(json.NewDecoder(resp.Body).Decode(&c)).To(Succeed())
+			Expect(c.Assets).To(Equal(2))
+			Expect(c.Largest).To(HaveLen(1))
+			Expect(c.Largest[0].URL).To(Equal(site.URL + "/"))
+		})
+
+		It("Should reject invalid limits", func() {
+			resp, err := http.Get(ts.URL + "/crawls/" + job.ID + "/compression?largest=all")
+			Expect(err).ToNot(HaveOccurred())
+			resp.Body.Close()
+			Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+		})
+	})
+
+	Describe("GET /crawls/{id}/weight", func() {
+		It("Should weigh the crawled pages", func() {
+			resp, err := http.Get(ts.URL + "/crawls/" + job.ID + "/weight")
+			Expect(err).ToNot(HaveOccurred())
+			defer resp.Body.Close()
+
+			var w report.PageWeights
+			Expect(json.NewDecoder(resp.Body).Decode(&w)).To(Succeed())
+			Expect(w.Pages).To(Equal(2))
+			Expect(w.Heaviest[0].URL).To(Equal(site.URL + "/"))
+		})
+
+		It("Should reject invalid limits", func() {
+			resp, err := http.Get(ts.URL + "/crawls/" + job.ID + "/weight?top=-1")
+			Expect(err).ToNot(HaveOccurred())
+			resp.Body.Close()
+			Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+		})
+	})
+
+	Describe("GET /crawls/{id}/audit", func() {
+		It("Should summarize the audit findings", func() {
+			resp, err := http.Get(ts.URL + "/crawls/" + job.ID + "/audit?severity=error")
+			Expect(err).ToNot(HaveOccurred())
+			defer resp.Body.Close()
+
+			var a report.Audit
+			Expect(json.NewDecoder(resp.Body).Decode(&a)).To(Succeed())
+			Expect(a.Pages).To(Equal(2))
+			Expect(a.Rules[0].Rule).To(Equal("title-missing"))
+			Expect(a.Rules[0].URLs).To(Equal([]string{site.URL + "/", site.URL + "/about"}))
+		})
+
+		It("Should filter by category", func() {
+			resp, err := http.Get(ts.URL + "/crawls/" + job.ID + "/audit?category=accessibility")
+			Expect(err).ToNot(HaveOccurred())
+			defer resp.Body.Close()
+
+			var a report.Audit
+			Expect(json.NewDecoder(resp.Body).Decode(&a)).To(Succeed())
+			Expect(a.Rules).To(HaveLen(1))
+			Expect(a.Rules[0].Rule).To(Equal("link-text-empty"))
+			Expect(a.Rules[0].Indices).To(Equal([]int{0}))
+		})
+
+		It("Should reject unknown severities", func() {
+			resp, err := http.Get(ts.URL + "/crawls/" + job.ID + "/audit?severity=fatal")
+			Expect(err).ToNot(HaveOccurred())
+			resp.Body.Close()
+			Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+		})
+	})
+})
@@ -0,0 +1,265 @@
+package server
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/kevinoconnor7/digitalocean-crawler/crawler"
+	"github.com/kevinoconnor7/digitalocean-crawler/extract"
+	"github.com/kevinoconnor7/digitalocean-crawler/render"
+)
+
+// defaultMaxPages is used when a crawl is started without a page limit
+const defaultMaxPages = 10
+
+// CrawlOptions configures a crawl started through the Manager
+type CrawlOptions struct {
+	Seed          string `json:"seed"`
+	MaxPages      int    `json:"maxPages"`
+	TrackExternal bool   `json:"trackExternal"`
+	Sitemap       string `json:"sitemap"`
+	SkipMetadata  bool   `json:"skipMetadata"`
+	StreamLinks   bool   `json:"streamLinks"`
+	HeadAssets    bool   `json:"headAssets"`
+	InferLinks    bool   `json:"inferLinks"`
+
+	// MaxBodySize defaults to crawler.DefaultMaxBodySize when zero
+	MaxBodySize int64 `json:"maxBodySize,omitempty"`
+
+	// Fields are extracted from pages, see extract.LoadFields for the format
+	Fields *extract.Fields `json:"fields,omitempty"`
+
+	// Render loads pages matching RenderPatterns, or every page if there
+	// are none, in a browser before finding their links
+	Render         bool            `json:"render"`
+	RenderPatterns render.Patterns `json:"renderPatterns,omitempty"`
+
+	// Renderer is the browser pages are rendered in, defaulting to the
+	// Manager's
+	Renderer render.Renderer `json:"-"`
+
+	// HTTP is only set from the command line, as proxies, CA bundles and
+	// headers shouldn't be chosen by whoever can reach the API
+	HTTP *crawler.HTTPConfig `json:"-"`
+
+	// Auth is only set from the command line, keeping credentials out of
+	// the API
+	Auth *crawler.AuthConfig `json:"-"`
+}
+
+// Config converts the options into the Config for the crawl's Crawler
+func (o CrawlOptions) Config() crawler.Config {
+	config := crawler.DefaultConfig()
+	config.TrackExternal = o.TrackExternal
+	config.SitemapURL = o.Sitemap
+	config.ExtractMetadata = !o.SkipMetadata
+	config.Fields = o.Fields
+	config.StreamLinks = o.StreamLinks
+	config.HeadAssets = o.HeadAssets
+	config.InferLinks = o.InferLinks
+
+	if o.Render {
+		config.Renderer = o.Renderer
+		config.RenderPatterns = o.RenderPatterns
+	}
+
+	if o.MaxBodySize > 0 {
+		config.MaxBodySize = o.MaxBodySize
+	}
+
+	if o.HTTP != nil {
+		config.HTTP = *o.HTTP
+	}
+
+	if o.Auth != nil {
+		config.Auth = *o.Auth
+	}
+
+	return config
+}
+
+// Job is a crawl owned by the Manager
+type Job struct {
+	ID      string
+	Options CrawlOptions
+	Created time.Time
+	Crawler *crawler.Crawler
+
+	mu       sync.Mutex
+	analyzed *crawler.Snapshot
+}
+
+// JobStatus is the externally visible state of a Job
+type JobStatus struct {
+	ID      string        `json:"id"`
+	Seed    string        `json:"seed"`
+	Created time.Time     `json:"created"`
+	State   crawler.State `json:"state"`
+	Error   string        `json:"error,omitempty"`
+	Stats   crawler.Stats `json:"stats"`
+}
+
+// Status reports the current state and stats of the Job
+func (j *Job) Status() JobStatus {
+	status := JobStatus{
+		ID:      j.ID,
+		Seed:    j.Options.Seed,
+		Created: j.Created,
+		Stats:   j.Crawler.Snapshot().Stats,
+	}
+	status.State = status.Stats.State
+
+	if err := j.Crawler.Err(); err != nil {
+		status.Error = err.Error()
+	}
+
+	return status
+}
+
+// Snapshot copies the crawl graph, analyzing it once the crawl has stopped
+// The analysis covers the whole graph, so it's done once and shared by
+// every caller, which mustn't modify it
+// Snapshots of running crawls aren't analyzed
+func (j *Job) Snapshot() *crawler.Snapshot {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.analyzed != nil {
+		return j.analyzed
+	}
+
+	s := j.Crawler.Snapshot()
+	if !s.Stats.State.Stopped() {
+		return s
+	}
+
+	s.Analyze()
+	j.analyzed = s
+
+	return s
+}
+
+// Manager runs and tracks any number of concurrent crawls
+type Manager struct {
+	mu     sync.RWMutex
+	jobs   map[string]*Job
+	order  []string
+	nextID int
+	http   *crawler.HTTPConfig
+
+	renderer render.Renderer
+}
+
+// GenerateManager is a factory for Manager
+func GenerateManager() *Manager {
+	return &Manager{
+		jobs:   make(map[string]*Job),
+		nextID: 1,
+	}
+}
+
+// SetHTTPConfig sets the HTTPConfig of crawls started without their own
+// Its headers aren't sent, as they often hold credentials and whoever can
+// reach the API chooses where those crawls go
+func (m *Manager) SetHTTPConfig(config crawler.HTTPConfig) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	config.Headers = nil
+	m.http = &config
+}
+
+// SetRenderer sets the Renderer of crawls that render pages without their
+// own
+// Crawls can't render pages until one is set
+func (m *Manager) SetRenderer(renderer render.Renderer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.renderer = renderer
+}
+
+// Start validates the options and begins a new crawl in the background
+func (m *Manager) Start(options CrawlOptions) (*Job, error) {
+	seed, err := url.Parse(options.Seed)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if (seed.Scheme != "http" && seed.Scheme != "https") || seed.Host == "" {
+		return nil, fmt.Errorf("Seed must be an absolute http(s) URL")
+	}
+
+	if options.MaxPages < 0 {
+		return nil, fmt.Errorf("maxPages can't be negative")
+	}
+
+	if options.MaxBodySize < 0 {
+		return nil, fmt.Errorf("maxBodySize can't be negative")
+	}
+
+	if options.MaxPages == 0 {
+		options.MaxPages = defaultMaxPages
+	}
+
+	m.mu.RLock()
+	if options.HTTP == nil {
+		options.HTTP = m.http
+	}
+	if options.Renderer == nil {
+		options.Renderer = m.renderer
+	}
+	m.mu.RUnlock()
+
+	if options.Render && options.Renderer == nil {
+		return nil, fmt.Errorf("Rendering isn't enabled on this server")
+	}
+
+	c := crawler.GenerateCrawlerWithConfig(seed, options.Config())
+	if err := c.Err(); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	job := &Job{
+		ID:      strconv.Itoa(m.nextID),
+		Options: options,
+		Created: time.Now(),
+		Crawler: c,
+	}
+	m.nextID++
+	m.jobs[job.ID] = job
+	m.order = append(m.order, job.ID)
+	m.mu.Unlock()
+
+	go job.Crawler.Run(options.MaxPages)
+
+	return job, nil
+}
+
+// Get looks up a Job by ID
+func (m *Manager) Get(id string) (*Job, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	job, ok := m.jobs[id]
+
+	return job, ok
+}
+
+// List returns every Job in the order they were started
+func (m *Manager) List() []*Job {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	jobs := make([]*Job, len(m.order))
+	for i, id := range m.order {
+		jobs[i] = m.jobs[id]
+	}
+
+	return jobs
+}
@@ -0,0 +1,146 @@
+package server_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/kevinoconnor7/digitalocean-crawler/crawler"
+	"github.com/kevinoconnor7/digitalocean-crawler/render"
+	. "github.com/kevinoconnor7/digitalocean-crawler/server"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Manager", func() {
+	var (
+		manager *Manager
+		site    *httptest.Server
+	)
+
+	BeforeEach(func() {
+		site = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, "test")
+		}))
+		manager = GenerateManager()
+	})
+
+	AfterEach(func() {
+		site.Close()
+	})
+
+	Describe("Start", func() {
+		It("Should run the crawl to completion", func() {
+			job, err := manager.Start(CrawlOptions{Seed: site.URL})
+			Expect(err).ToNot(HaveOccurred())
+			Eventually(job.Crawler.State).Should(Equal(crawler.FINISHED))
+		})
+
+		It("Should default the page limit", func() {
+			job, _ := manager.Start(CrawlOptions{Seed: site.URL})
+			Expect(job.Options.MaxPages).To(Equal(10))
+		})
+
+		It("Should reject relative seeds", func() {
+			_, err := manager.Start(CrawlOptions{Seed: "/foo"})
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("Should reject negative page limits", func() {
+			_, err := manager.Start(CrawlOptions{Seed: site.URL, MaxPages: -1})
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("Should reject invalid HTTP configurations", func() {
+			_, err := manager.Start(CrawlOptions{Seed: site.URL, HTTP: &crawler.HTTPConfig{Proxy: "ftp://proxy"}})
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("Should default to the manager's HTTP configuration", func() {
+			var agent string
+			site.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				agent = r.UserAgent()
+			})
+
+			manager.SetHTTPConfig(crawler.HTTPConfig{UserAgent: "Test Agent"})
+			job, err := manager.Start(CrawlOptions{Seed: site.URL})
+			Expect(err).ToNot(HaveOccurred())
+			Eventually(job.Crawler.State).Should(Equal(crawler.FINISHED))
+			Expect(agent).To(Equal("Test Agent"))
+		})
+
+		It("Should not send the manager's headers", func() {
+			var received http.Header
+			site.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				received = r.Header
+			})
+
+			manager.SetHTTPConfig(crawler.HTTPConfig{Headers: http.Header{"Authorization": {"Bearer token"}}})
+			job, err := manager.Start(CrawlOptions{Seed: site.URL})
+			Expect(err).ToNot(HaveOccurred())
+			Eventually(job.Crawler.State).Should(Equal(crawler.FINISHED))
+			Expect(received.Get("Authorization")).To(BeEmpty())
+		})
+
+		It("Should only render pages with a renderer", func() {
+			_, err := manager.Start(CrawlOptions{Seed: site.URL, Render: true})
+			Expect(err).To(MatchError("Rendering isn't enabled on this server"))
+		})
+
+		It("Should render pages with the manager's renderer", func() {
+			site.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, "<p>test</p>")
+			})
+
+			renderer := render.GenerateFakeRenderer()
+			renderer.Add(site.URL+"/", "<p>Rendered</p>")
+
+			manager.SetRenderer(renderer)
+			job, err := manager.Start(CrawlOptions{Seed: site.URL + "/", Render: true})
+			Expect(err).ToNot(HaveOccurred())
+			Eventually(job.Crawler.State).Should(Equal(crawler.FINISHED))
+			Expect(job.Crawler.Assets[site.URL+"/"].Rendered).To(BeTrue())
+		})
+
+		It("Should give every crawl its own id", func() {
+			first, _ := manager.Start(CrawlOptions{Seed: site.URL})
+			second, _ := manager.Start(CrawlOptions{Seed: site.URL})
+			Expect(first.ID).ToNot(Equal(second.ID))
+			Expect(first.Crawler).ToNot(BeIdenticalTo(second.Crawler))
+		})
+	})
+
+	Describe("Get", func() {
+		It("Should find started crawls", func() {
+			job, _ := manager.Start(CrawlOptions{Seed: site.URL})
+			found, ok := manager.Get(job.ID)
+			Expect(ok).To(BeTrue())
+			Expect(found).To(Equal(job))
+		})
+
+		It("Should not find unknown crawls", func() {
+			_, ok := manager.Get("nope")
+			Expect(ok).To(BeFalse())
+		})
+	})
+
+	Describe("List", func() {
+		It("Should return crawls in start order", func() {
+			first, _ := manager.Start(CrawlOptions{Seed: site.URL})
+			second, _ := manager.Start(CrawlOptions{Seed: site.URL})
+			Expect(manager.List()).To(Equal([]*Job{first, second}))
+		})
+	})
+
+	Describe("Job", func() {
+		It("Should analyze stopped crawls once", func() {
+			job, _ := manager.Start(CrawlOptions{Seed: site.URL})
+			Eventually(job.Crawler.State).Should(Equal(crawler.FINISHED))
+
+			s := job.Snapshot()
+			Expect(s.Nodes[0].Metrics).ToNot(BeNil())
+			Expect(job.Snapshot()).To(BeIdenticalTo(s))
+		})
+	})
+})
@@ -0,0 +1,308 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/kevinoconnor7/digitalocean-crawler/audit"
+	"github.com/kevinoconnor7/digitalocean-crawler/report"
+)
+
+// Server exposes crawls, their results and live progress over HTTP
+type Server struct {
+	mux     *http.ServeMux
+	manager *Manager
+}
+
+// GenerateServer is a factory for Server
+func GenerateServer(m *Manager) *Server {
+	s := &Server{
+		mux:     http.NewServeMux(),
+		manager: m,
+	}
+
+	s.mux.HandleFunc("GET /{$}", s.handleIndex)
+	s.mux.Handle("GET /static/", staticHandler())
+	s.mux.HandleFunc("GET /crawls", s.handleListCrawls)
+	s.mux.HandleFunc("POST /crawls", s.handleStartCrawl)
+	s.mux.HandleFunc("GET /crawls/{id}", s.withJob(s.handleGetCrawl))
+	s.mux.HandleFunc("POST /crawls/{id}/{action}", s.withJob(s.handleControlCrawl))
+	s.mux.HandleFunc("GET /crawls/{id}/graph.json", s.withJob(s.handleGraph))
+	s.mux.HandleFunc("GET /graph.json", s.handleFirstGraph)
+	s.mux.HandleFunc("GET /crawls/{id}/events", s.withJob(s.handleEvents))
+	s.mux.HandleFunc("GET /crawls/{id}/assets", s.withJob(s.handleAssets))
+	s.mux.HandleFunc("GET /crawls/{id}/assets/{asset}", s.withJob(s.handleAsset))
+	s.mux.HandleFunc("GET /crawls/{id}/assets/{asset}/neighbors", s.withJob(s.handleNeighbors))
+	s.mux.HandleFunc("GET /crawls/{id}/report", s.withJob(s.handleReport))
+	s.mux.HandleFunc("GET /crawls/{id}/audit", s.withJob(s.handleAudit))
+	s.mux.HandleFunc("GET /crawls/{id}/path", s.withJob(s.handlePath))
+	s.mux.HandleFunc("GET /api/path", s.handleFirstPath)
+	s.mux.HandleFunc("GET /crawls/{id}/fields", s.withJob(s.handleFields))
+	s.mux.HandleFunc("GET /crawls/{id}/compression", s.withJob(s.handleCompression))
+	s.mux.HandleFunc("GET /crawls/{id}/weight", s.withJob(s.handleWeight))
+
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// withJob resolves the {id} path value to a Job before calling the handler
+func (s *Server) withJob(handler func(http.ResponseWriter, *http.Request, *Job)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		job, ok := s.manager.Get(r.PathValue("id"))
+
+		if !ok {
+			writeError(w, http.StatusNotFound, fmt.Errorf("No crawl with id %q", r.PathValue("id")))
+			return
+		}
+
+		handler(w, r, job)
+	}
+}
+
+func (s *Server) handleListCrawls(w http.ResponseWriter, r *http.Request) {
+	jobs := s.manager.List()
+	statuses := make([]JobStatus, len(jobs))
+
+	for i, job := range jobs {
+		statuses[i] = job.Status()
+	}
+
+	writeJSON(w, http.StatusOK, statuses)
+}
+
+func (s *Server) handleStartCrawl(w http.ResponseWriter, r *http.Request) {
+	var options CrawlOptions
+
+	err := json.NewDecoder(r.Body).Decode(&options)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("Invalid crawl options: %v", err))
+		return
+	}
+
+	job, err := s.manager.Start(options)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, job.Status())
+}
+
+func (s *Server) handleGetCrawl(w http.ResponseWriter, r *http.Request, job *Job) {
+	writeJSON(w, http.StatusOK, job.Status())
+}
+
+func (s *Server) handleControlCrawl(w http.ResponseWriter, r *http.Request, job *Job) {
+	var err error
+
+	switch r.PathValue("action") {
+	case "pause":
+		err = job.Crawler.Pause()
+	case "resume":
+		err = job.Crawler.Resume()
+	case "cancel":
+		err = job.Crawler.Cancel()
+	default:
+		writeError(w, http.StatusNotFound, fmt.Errorf("Unknown action %q", r.PathValue("action")))
+		return
+	}
+
+	if err != nil {
+		writeError(w, http.StatusConflict, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, job.Status())
+}
+
+// handleGraph serves the crawl graph, analyzed once the crawl has stopped
+func (s *Server) handleGraph(w http.ResponseWriter, r *http.Request, job *Job) {
+	writeJSON(w, http.StatusOK, job.Snapshot())
+}
+
+// handleFirstGraph serves the graph of the first crawl, the one started
+// from the command line, where it was served before the API could start
+// more
+func (s *Server) handleFirstGraph(w http.ResponseWriter, r *http.Request) {
+	job, ok := s.firstJob(w)
+	if ok {
+		s.handleGraph(w, r, job)
+	}
+}
+
+// handleFirstPath finds shortest paths as handlePath does, in the crawl
+// named by the crawl parameter or else the first crawl
+func (s *Server) handleFirstPath(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("crawl")
+	if id == "" {
+		job, ok := s.firstJob(w)
+		if ok {
+			s.handlePath(w, r, job)
+		}
+		return
+	}
+
+	job, ok := s.manager.Get(id)
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Errorf("No crawl with id %q", id))
+		return
+	}
+
+	s.handlePath(w, r, job)
+}
+
+// firstJob returns the first crawl, writing an error if none has started
+func (s *Server) firstJob(w http.ResponseWriter) (*Job, bool) {
+	jobs := s.manager.List()
+	if len(jobs) == 0 {
+		writeError(w, http.StatusNotFound, fmt.Errorf("No crawl has been started"))
+		return nil, false
+	}
+
+	return jobs[0], true
+}
+
+// handleReport ranks the crawl's pages and lists structural problems
+// Pages are only ranked once the crawl has stopped and been analyzed
+func (s *Server) handleReport(w http.ResponseWriter, r *http.Request, job *Job) {
+	writeJSON(w, http.StatusOK, report.GenerateRanking(job.Snapshot()))
+}
+
+// handleAudit groups the crawl's audit findings by rule, optionally only
+// those at least as severe as ?severity= or of ?category=
+func (s *Server) handleAudit(w http.ResponseWriter, r *http.Request, job *Job) {
+	filter := report.AuditFilter{
+		Category: audit.Category(r.URL.Query().Get("category")),
+	}
+
+	if name := r.URL.Query().Get("severity"); name != "" {
+		var err error
+
+		filter.Severity, err = audit.ParseSeverity(name)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	writeJSON(w, http.StatusOK, report.GenerateAudit(job.Crawler.Snapshot(), filter))
+}
+
+// handleFields exports the custom fields extracted by the crawl as JSON
+// Lines, or as CSV with ?format=csv
+func (s *Server) handleFields(w http.ResponseWriter, r *http.Request, job *Job) {
+	snapshot := job.Crawler.Snapshot()
+
+	switch r.URL.Query().Get("format") {
+	case "", "jsonl":
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		report.WriteFieldsJSONL(w, snapshot)
+	case "csv":
+		w.Header().Set("Content-Type", "text/csv")
+		report.WriteFieldsCSV(w, snapshot)
+	default:
+		writeError(w, http.StatusBadRequest, fmt.Errorf("Unknown format %q, expected jsonl or csv", r.URL.Query().Get("format")))
+	}
+}
+
+// handleCompression reports the crawl's transfer sizes and the assets
+// worth compressing, listing up to ?largest= of the heaviest
+func (s *Server) handleCompression(w http.ResponseWriter, r *http.Request, job *Job) {
+	largest := report.DefaultLargest
+
+	if value := r.URL.Query().Get("largest"); value != "" {
+		var err error
+
+		largest, err = strconv.Atoi(value)
+		if err != nil || largest < 0 {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("Invalid largest %q", value))
+			return
+		}
+	}
+
+	writeJSON(w, http.StatusOK, report.GenerateCompression(job.Crawler.Snapshot(), largest))
+}
+
+// handleWeight reports what the crawl's pages weigh with their
+// subresources, listing up to ?top= pages and shared resources
+func (s *Server) handleWeight(w http.ResponseWriter, r *http.Request, job *Job) {
+	top := report.DefaultLargest
+
+	if value := r.URL.Query().Get("top"); value != "" {
+		var err error
+
+		top, err = strconv.Atoi(value)
+		if err != nil || top < 0 {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("Invalid top %q", value))
+			return
+		}
+	}
+
+	writeJSON(w, http.StatusOK, report.GeneratePageWeights(job.Crawler.Snapshot(), top))
+}
+
+// resyncEvent tells a client that fell too far behind to receive every
+// event to reload the graph, after which the stream ends
+const resyncEvent = "resync"
+
+// handleEvents streams crawl events to the client as Server-Sent Events
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request, job *Job) {
+	flusher, ok := w.(http.Flusher)
+
+	if !ok {
+		http.Error(w, "Streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	events := job.Crawler.Subscribe()
+	defer job.Crawler.Unsubscribe(events)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event, ok := <-events:
+			if !ok {
+				fmt.Fprintf(w, "event: %s\ndata: {}\n\n", resyncEvent)
+				flusher.Flush()
+				return
+			}
+
+			data, err := json.Marshal(event)
+
+			if err != nil {
+				continue
+			}
+
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data)
+			flusher.Flush()
+		}
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	data, err := json.Marshal(value)
+
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Unable to encode response: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(data)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
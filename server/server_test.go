@@ -0,0 +1,246 @@
+package server_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/kevinoconnor7/digitalocean-crawler/crawler"
+	. "github.com/kevinoconnor7/digitalocean-crawler/server"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Server", func() {
+	var (
+		manager *Manager
+		site    *httptest.Server
+		ts      *httptest.Server
+		hit     chan bool
+		release chan bool
+	)
+
+	post := func(path string, body string) (*http.Response, map[string]interface{}) {
+		resp, err := http.Post(ts.URL+path, "application/json", bytes.NewBufferString(body))
+		Expect(err).ToNot(HaveOccurred())
+		defer resp.Body.Close()
+
+		var decoded map[string]interface{}
+		Expect(json.NewDecoder(resp.Body).Decode(&decoded)).To(Succeed())
+
+		return resp, decoded
+	}
+
+	BeforeEach(func() {
+		hit = make(chan bool, 1)
+		release = make(chan bool)
+		site = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case hit <- true:
+			default:
+			}
+			<-release
+			fmt.Fprint(w, `<a href="/subpage"></a>`)
+		}))
+		manager = GenerateManager()
+		ts = httptest.NewServer(GenerateServer(manager))
+	})
+
+	AfterEach(func() {
+		close(release)
+		ts.Close()
+		site.Close()
+	})
+
+	Describe("POST /crawls", func() {
+		It("Should start a crawl", func() {
+			resp, status := post("/crawls", fmt.Sprintf(`{"seed": %q, "maxPages": 2}`, site.URL))
+			Expect(resp.StatusCode).To(Equal(http.StatusCreated))
+			Expect(status["id"]).To(Equal("1"))
+			Expect(status["seed"]).To(Equal(site.URL))
+		})
+
+		It("Should reject invalid seeds", func() {
+			resp, status := post("/crawls", `{"seed": "/relative"}`)
+			Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+			Expect(status["error"]).ToNot(BeEmpty())
+		})
+
+		It("Should reject invalid custom fields", func() {
+			resp, status := post("/crawls", fmt.Sprintf(`{"seed": %q, "fields": {"fields": {"price": "div["}}}`, site.URL))
+			Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+			Expect(status["error"]).To(ContainSubstring("price"))
+		})
+
+		It("Should reject invalid render patterns", func() {
+			resp, status := post("/crawls", fmt.Sprintf(`{"seed": %q, "render": true, "renderPatterns": ["("]}`, site.URL))
+			Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+			Expect(status["error"]).To(ContainSubstring("render pattern"))
+		})
+
+		It("Should reject malformed bodies", func() {
+			resp, _ := post("/crawls", `{`)
+			Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+		})
+	})
+
+	Describe("GET /graph.json", func() {
+		It("Should not be found before any crawl has started", func() {
+			resp, err := http.Get(ts.URL + "/graph.json")
+			Expect(err).ToNot(HaveOccurred())
+			defer resp.Body.Close()
+
+			Expect(resp.StatusCode).To(Equal(http.StatusNotFound))
+		})
+	})
+
+	Context("With a running crawl", func() {
+		var job *Job
+
+		BeforeEach(func() {
+			var err error
+			job, err = manager.Start(CrawlOptions{Seed: site.URL})
+			Expect(err).ToNot(HaveOccurred())
+			<-hit
+		})
+
+		Describe("GET /crawls", func() {
+			It("Should list every crawl", func() {
+				resp, err := http.Get(ts.URL + "/crawls")
+				Expect(err).ToNot(HaveOccurred())
+				defer resp.Body.Close()
+
+				var statuses []JobStatus
+				Expect(json.NewDecoder(resp.Body).Decode(&statuses)).To(Succeed())
+				Expect(len(statuses)).To(Equal(1))
+				Expect(statuses[0].ID).To(Equal(job.ID))
+			})
+		})
+
+		Describe("GET /crawls/{id}", func() {
+			It("Should return the crawl status", func() {
+				resp, err := http.Get(ts.URL + "/crawls/" + job.ID)
+				Expect(err).ToNot(HaveOccurred())
+				defer resp.Body.Close()
+
+				var status JobStatus
+				Expect(json.NewDecoder(resp.Body).Decode(&status)).To(Succeed())
+				Expect(status.State).To(Equal(crawler.RUNNING))
+				Expect(status.Stats.Assets).To(Equal(1))
+			})
+
+			It("Should 404 for unknown crawls", func() {
+				resp, err := http.Get(ts.URL + "/crawls/nope")
+				Expect(err).ToNot(HaveOccurred())
+				resp.Body.Close()
+				Expect(resp.StatusCode).To(Equal(http.StatusNotFound))
+			})
+		})
+
+		Describe("POST /crawls/{id}/{action}", func() {
+			It("Should pause and resume the crawl", func() {
+				resp, status := post("/crawls/"+job.ID+"/pause", "")
+				Expect(resp.StatusCode).To(Equal(http.StatusOK))
+				Expect(status["state"]).To(Equal("paused"))
+
+				resp, _ = post("/crawls/"+job.ID+"/pause", "")
+				Expect(resp.StatusCode).To(Equal(http.StatusConflict))
+
+				resp, status = post("/crawls/"+job.ID+"/resume", "")
+				Expect(resp.StatusCode).To(Equal(http.StatusOK))
+				Expect(status["state"]).To(Equal("running"))
+			})
+
+			It("Should cancel the crawl", func() {
+				resp, status := post("/crawls/"+job.ID+"/cancel", "")
+				Expect(resp.StatusCode).To(Equal(http.StatusOK))
+				Expect(status["state"]).To(Equal("cancelled"))
+
+				resp, _ = post("/crawls/"+job.ID+"/resume", "")
+				Expect(resp.StatusCode).To(Equal(http.StatusConflict))
+			})
+
+			It("Should 404 for unknown actions", func() {
+				resp, _ := post("/crawls/"+job.ID+"/explode", "")
+				Expect(resp.StatusCode).To(Equal(http.StatusNotFound))
+			})
+		})
+
+		Describe("GET /crawls/{id}/graph.json", func() {
+			It("Should return the crawl snapshot", func() {
+				resp, err := http.Get(ts.URL + "/crawls/" + job.ID + "/graph.json")
+				Expect(err).ToNot(HaveOccurred())
+				defer resp.Body.Close()
+
+				Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+				var s crawler.Snapshot
+				Expect(json.NewDecoder(resp.Body).Decode(&s)).To(Succeed())
+				Expect(len(s.Nodes)).To(Equal(1))
+			})
+
+			It("Should analyze the graph once the crawl has stopped", func() {
+				post("/crawls/"+job.ID+"/cancel", "")
+
+				resp, err := http.Get(ts.URL + "/crawls/" + job.ID + "/graph.json")
+				Expect(err).ToNot(HaveOccurred())
+				defer resp.Body.Close()
+
+				var s crawler.Snapshot
+				Expect(json.NewDecoder(resp.Body).Decode(&s)).To(Succeed())
+				Expect(s.Nodes[0].Metrics).ToNot(BeNil())
+			})
+		})
+
+		Describe("GET /graph.json", func() {
+			It("Should return the snapshot of the first crawl", func() {
+				_, err := manager.Start(CrawlOptions{Seed: site.URL + "/subpage"})
+				Expect(err).ToNot(HaveOccurred())
+
+				resp, err := http.Get(ts.URL + "/graph.json")
+				Expect(err).ToNot(HaveOccurred())
+				defer resp.Body.Close()
+
+				Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+				var s crawler.Snapshot
+				Expect(json.NewDecoder(resp.Body).Decode(&s)).To(Succeed())
+				Expect(s.Nodes[0].URL).To(Equal(site.URL))
+			})
+		})
+
+		Describe("GET /crawls/{id}/events", func() {
+			It("Should stream crawl events", func() {
+				resp, err := http.Get(ts.URL + "/crawls/" + job.ID + "/events")
+				Expect(err).ToNot(HaveOccurred())
+				defer resp.Body.Close()
+
+				Expect(resp.Header.Get("Content-Type")).To(Equal("text/event-stream"))
+
+				asset := job.Crawler.Assets[site.URL]
+				job.Crawler.HandleURL("/other", asset, crawler.PAGE)
+
+				reader := bufio.NewReader(resp.Body)
+				var lines []string
+				for len(lines) < 4 {
+					line, err := reader.ReadString('\n')
+					Expect(err).ToNot(HaveOccurred())
+
+					if strings.HasPrefix(line, "event: ") || strings.HasPrefix(line, "data: ") {
+						lines = append(lines, strings.TrimSpace(line))
+					}
+				}
+
+				Expect(lines[0]).To(Equal("event: asset-discovered"))
+				Expect(lines[1]).To(ContainSubstring(`"url":"` + site.URL + `/other"`))
+				Expect(lines[2]).To(Equal("event: link-added"))
+				Expect(lines[3]).To(ContainSubstring(`"target":1`))
+			})
+		})
+	})
+})
@@ -0,0 +1,39 @@
+package server
+
+//go:generate curl -fsSL -o static/d3.v3.min.js https://cdnjs.cloudflare.com/ajax/libs/d3/3.5.17/d3.min.js
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+)
+
+// staticFiles holds the web UI so the binary can serve it from any directory
+// d3 is vendored under static as d3.v3.min.js, BSD-3 licensed as described
+// in static/d3.LICENSE
+//
+//go:embed static
+var staticFiles embed.FS
+
+// staticHandler serves the embedded UI assets under /static/
+func staticHandler() http.Handler {
+	files, err := fs.Sub(staticFiles, "static")
+
+	if err != nil {
+		panic(err)
+	}
+
+	return http.StripPrefix("/static/", http.FileServer(http.FS(files)))
+}
+
+func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
+	index, err := staticFiles.ReadFile("static/index.html")
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(index)
+}
@@ -0,0 +1,490 @@
+(function() {
+  "use strict";
+
+  // Mirrors crawler.ContentType
+  var TYPES = {1: "Page", 2: "Stylesheet", 3: "Script", 4: "Image"};
+
+  var graph = new ForceGraph(document.getElementById("graph"), {
+        charge: -400,
+        friction: 0.9,
+        theta: 0.9,
+        gravity: 0.5,
+        linkDistance: 10,
+        linkStrength: 0.9
+      }),
+      tip = document.getElementById("tip"),
+      crawl = null,
+      hiddenTypes = {},
+      statusCodes = {};
+
+  function $(id) {
+    return document.getElementById(id);
+  }
+
+  function getJSON(url, callback) {
+    var req = new XMLHttpRequest();
+    req.open("GET", url);
+    req.onload = function() {
+      if (req.status !== 200) {
+        callback(new Error(req.statusText));
+        return;
+      }
+      callback(null, JSON.parse(req.responseText));
+    };
+    req.onerror = function() {
+      callback(new Error("Request failed"));
+    };
+    req.send();
+  }
+
+  function element(tag, text) {
+    var el = document.createElement(tag);
+    if (text !== undefined) {
+      el.textContent = text;
+    }
+    return el;
+  }
+
+  graph.on("mouseover", function(node, e) {
+    tip.textContent = node.data.url;
+    tip.style.display = "block";
+    tip.style.left = (e.pageX - tip.offsetWidth / 2) + "px";
+    tip.style.top = (e.pageY - tip.offsetHeight - 12) + "px";
+  });
+
+  graph.on("mouseout", function() {
+    tip.style.display = "none";
+  });
+
+  graph.on("click", function(node) {
+    focus(node.key);
+  });
+
+  // Legend and filters
+
+  function buildLegend() {
+    var legend = $("legend");
+
+    Object.keys(TYPES).forEach(function(type) {
+      var label = element("label"),
+          toggle = element("input"),
+          swatch = element("span");
+
+      toggle.type = "checkbox";
+      toggle.checked = true;
+      toggle.addEventListener("change", function() {
+        hiddenTypes[type] = !toggle.checked;
+        applyFilters();
+      });
+
+      swatch.className = "swatch";
+      swatch.style.background = ForceGraph.color(+type);
+
+      label.appendChild(toggle);
+      label.appendChild(swatch);
+      label.appendChild(document.createTextNode(TYPES[type]));
+      legend.appendChild(label);
+    });
+  }
+
+  function recordStatus(code) {
+    if (!code || statusCodes[code]) {
+      return;
+    }
+    statusCodes[code] = true;
+
+    var option = element("option", code);
+    option.value = code;
+    $("filter-status").appendChild(option);
+  }
+
+  function applyFilters() {
+    var status = $("filter-status").value,
+        depth = $("filter-depth").value,
+        hosts = $("filter-external").value;
+
+    graph.filter(function(asset) {
+      if (hiddenTypes[asset.type]) {
+        return false;
+      }
+      if (status && String(asset.statusCode) !== status) {
+        return false;
+      }
+      if (depth !== "" && asset.depth > +depth) {
+        return false;
+      }
+      if (hosts === "internal" && asset.external) {
+        return false;
+      }
+      if (hosts === "external" && !asset.external) {
+        return false;
+      }
+      return true;
+    });
+  }
+
+  ["filter-status", "filter-depth", "filter-external"].forEach(function(id) {
+    $(id).addEventListener("change", applyFilters);
+  });
+
+  // Search
+
+  var searchTimer = null;
+
+  $("search").addEventListener("input", function() {
+    clearTimeout(searchTimer);
+    searchTimer = setTimeout(search, 200);
+  });
+
+  function search() {
+    var query = $("search").value,
+        results = $("results");
+
+    results.innerHTML = "";
+    if (!query || !crawl) {
+      return;
+    }
+
+    getJSON("/crawls/" + crawl + "/assets?q=" + encodeURIComponent(query), function(error, nodes) {
+      if (error) {
+        return;
+      }
+      results.innerHTML = "";
+      nodes.forEach(function(node) {
+        results.appendChild(nodeItem(node));
+      });
+    });
+  }
+
+  function nodeItem(node) {
+    var item = element("li", node.url);
+    item.title = node.url;
+    item.addEventListener("click", function() {
+      focus(node.index);
+    });
+    return item;
+  }
+
+  // Focus and side panel
+
+  function focus(index) {
+    getJSON("/crawls/" + crawl + "/assets/" + index + "/neighbors", function(error, n) {
+      if (error) {
+        return;
+      }
+
+      var nodeKeys = {},
+          linkKeys = {};
+
+      nodeKeys[index] = true;
+      n.inbound.forEach(function(node) {
+        nodeKeys[node.index] = true;
+        linkKeys[node.index + "-" + index] = true;
+      });
+      n.outbound.forEach(function(node) {
+        nodeKeys[node.index] = true;
+        linkKeys[index + "-" + node.index] = true;
+      });
+
+      graph.highlight(nodeKeys, linkKeys);
+      showPanel(n);
+    });
+  }
+
+  function unfocus() {
+    graph.highlight(null);
+    $("panel").classList.remove("open");
+  }
+
+  function showPanel(n) {
+    var asset = n.node,
+        meta = $("panel-meta");
+
+    $("panel-url").textContent = asset.url;
+
+    meta.innerHTML = "";
+    [
+      ["Type", TYPES[asset.type] || asset.type],
+      ["Status", asset.statusCode || "Not fetched"],
+      ["Depth", asset.depth],
+      ["Host", asset.external ? "External" : "Internal"]
+    ].concat(metadataRows(asset.metadata), metricRows(asset.metrics)).forEach(function(row) {
+      meta.appendChild(element("dt", row[0]));
+      meta.appendChild(element("dd", row[1]));
+    });
+
+    fillFindings(asset.findings || []);
+    fillNeighbors("inbound", n.inbound);
+    fillNeighbors("outbound", n.outbound);
+
+    $("panel").classList.add("open");
+  }
+
+  function metadataRows(m) {
+    if (!m) {
+      return [];
+    }
+
+    var rows = [];
+    if (m.title) {
+      rows.push(["Title", m.title]);
+    }
+    if (m.description) {
+      rows.push(["Description", m.description]);
+    }
+    if (m.headings) {
+      rows.push(["Headings", m.headings.length]);
+    }
+    if (m.jsonLD || m.microdata) {
+      rows.push(["Structured", (m.jsonLD || []).length + " JSON-LD, " + (m.microdata || []).length + " microdata"]);
+    }
+    return rows;
+  }
+
+  function metricRows(m) {
+    if (!m) {
+      return [];
+    }
+
+    var rows = [
+      ["PageRank", m.pageRank.toFixed(5)],
+      ["In-degree", m.inDegree],
+      ["Out-degree", m.outDegree],
+      ["Click depth", m.depth === -1 ? "Unreachable" : m.depth],
+      ["Component", m.componentSize + " pages"]
+    ];
+    if (m.deadEnd) {
+      rows.push(["Warning", "Dead end"]);
+    }
+    if (m.orphan) {
+      rows.push(["Warning", "Orphan"]);
+    }
+    return rows;
+  }
+
+  function fillNeighbors(direction, nodes) {
+    var list = $("panel-" + direction);
+
+    list.innerHTML = "";
+    $("panel-" + direction + "-count").textContent = "(" + nodes.length + ")";
+    nodes.forEach(function(node) {
+      list.appendChild(nodeItem(node));
+    });
+  }
+
+  function severityLabel(severity) {
+    var label = element("span", severity);
+    label.className = "severity severity-" + severity;
+    return label;
+  }
+
+  function fillFindings(findings) {
+    var list = $("panel-findings");
+
+    list.innerHTML = "";
+    $("panel-findings-count").textContent = "(" + findings.length + ")";
+    findings.forEach(function(finding) {
+      var item = element("li"),
+          rule = element("span", finding.category + "/" + finding.rule);
+
+      item.className = "finding-" + finding.severity;
+      rule.className = "category";
+      item.appendChild(severityLabel(finding.severity));
+      item.appendChild(rule);
+      item.appendChild(element("div", finding.message));
+      list.appendChild(item);
+    });
+  }
+
+  $("panel-close").addEventListener("click", unfocus);
+
+  // Site-wide audit summary, each rule expands to the pages it fired on
+
+  $("audit-toggle").addEventListener("click", function() {
+    if ($("audit").classList.toggle("open")) {
+      loadAudit();
+    }
+  });
+
+  $("audit-category").addEventListener("change", loadAudit);
+
+  function loadAudit() {
+    if (!crawl) {
+      return;
+    }
+
+    var url = "/crawls/" + crawl + "/audit?category=" + encodeURIComponent($("audit-category").value);
+
+    getJSON(url, function(error, audit) {
+      if (error) {
+        return;
+      }
+
+      var rules = $("audit-rules");
+
+      $("audit-summary").textContent = audit.findings + " finding(s) on " +
+          audit.affectedPages + " of " + audit.pages + " page(s)";
+
+      rules.innerHTML = "";
+      audit.rules.forEach(function(rule) {
+        rules.appendChild(ruleItem(rule));
+      });
+    });
+  }
+
+  function ruleItem(rule) {
+    var item = element("li"),
+        pages = element("ul");
+
+    item.appendChild(severityLabel(rule.severity));
+    item.appendChild(document.createTextNode(rule.rule + " (" + rule.urls.length + ")"));
+    item.appendChild(pages);
+    pages.style.display = "none";
+
+    rule.urls.forEach(function(url, i) {
+      pages.appendChild(nodeItem({index: rule.indices[i], url: url}));
+    });
+
+    item.addEventListener("click", function(e) {
+      if (e.target === item || e.target.parentNode === item) {
+        pages.style.display = pages.style.display === "none" ? "" : "none";
+      }
+    });
+
+    return item;
+  }
+
+  // Click paths
+
+  $("panel-path-from").addEventListener("click", function() {
+    $("path-from").value = $("panel-url").textContent;
+  });
+
+  $("panel-path-to").addEventListener("click", function() {
+    $("path-to").value = $("panel-url").textContent;
+  });
+
+  $("path").addEventListener("submit", function(e) {
+    e.preventDefault();
+
+    var url = "/crawls/" + crawl + "/path" +
+          "?from=" + encodeURIComponent($("path-from").value) +
+          "&to=" + encodeURIComponent($("path-to").value) +
+          "&ignoreBoilerplate=" + $("path-boilerplate").checked +
+          "&ignoreNofollow=" + $("path-nofollow").checked +
+          "&ignoreInferred=" + $("path-inferred").checked;
+
+    getJSON(url, function(error, result) {
+      if (error) {
+        $("path-result").textContent = "Unknown URL";
+        return;
+      }
+      highlightPaths(result);
+    });
+  });
+
+  function highlightPaths(result) {
+    var nodeKeys = {},
+        linkKeys = {};
+
+    if (!result.paths.length) {
+      $("path-result").textContent = "Unreachable";
+      graph.highlight(null);
+      return;
+    }
+
+    result.paths.forEach(function(path) {
+      path.forEach(function(node, i) {
+        nodeKeys[node.index] = true;
+        if (i > 0) {
+          linkKeys[path[i - 1].index + "-" + node.index] = true;
+        }
+      });
+    });
+
+    $("path-result").textContent = result.clicks + " click(s), " + result.paths.length + " route(s)";
+    graph.highlight(nodeKeys, linkKeys);
+  }
+
+  document.addEventListener("keydown", function(e) {
+    if (e.key === "Escape") {
+      unfocus();
+    }
+  });
+
+  // Loading and live updates
+
+  // Events that arrive before the initial graph has loaded are replayed
+  // once it has, so nothing discovered in between is lost
+  var pending = [],
+      loaded = false;
+
+  function handleEvent(e) {
+    var data = JSON.parse(e.data);
+    if (!loaded) {
+      pending.push(data);
+      return;
+    }
+    applyEvent(data);
+    applyFilters();
+    graph.start();
+  }
+
+  function addAsset(index, asset) {
+    graph.updateNode(index, asset);
+    recordStatus(asset.statusCode);
+  }
+
+  function applyEvent(data) {
+    if (data.type === "asset-discovered" || data.type === "asset-fetched") {
+      addAsset(data.index, data.asset);
+    } else if (data.type === "link-added") {
+      graph.addLink(data.link.source, data.link.target, data.link);
+    }
+  }
+
+  // Show the crawl named by ?crawl=, or the most recently started one
+  function load(id) {
+    crawl = id;
+
+    var events = new EventSource("/crawls/" + id + "/events");
+    events.addEventListener("asset-discovered", handleEvent);
+    events.addEventListener("asset-fetched", handleEvent);
+    events.addEventListener("link-added", handleEvent);
+
+    // The server gave up on sending every event, so the graph is reloaded,
+    // which adds whatever was missed
+    events.addEventListener("resync", function() {
+      events.close();
+      pending = [];
+      loaded = false;
+      load(id);
+    });
+
+    getJSON("/crawls/" + id + "/graph.json", function(error, snapshot) {
+      if (error) {
+        return;
+      }
+      snapshot.nodes.forEach(function(asset, i) { addAsset(i, asset); });
+      snapshot.links.forEach(function(l) { graph.addLink(l.source, l.target, l); });
+      pending.forEach(applyEvent);
+      pending = [];
+      loaded = true;
+      applyFilters();
+      graph.start();
+    });
+  }
+
+  buildLegend();
+
+  var requested = /[?&]crawl=([^&]+)/.exec(window.location.search);
+  if (requested) {
+    load(decodeURIComponent(requested[1]));
+  } else {
+    getJSON("/crawls", function(error, crawls) {
+      if (!error && crawls.length) {
+        load(crawls[crawls.length - 1].id);
+      }
+    });
+  }
+})();
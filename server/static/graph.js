@@ -0,0 +1,203 @@
+// A force-directed graph of the crawl, laid out by d3's force layout.
+//
+// Nodes and links are keyed so that assets and links streamed in as the
+// crawl runs can be added, updated, filtered and highlighted in place.
+(function(global, d3) {
+  "use strict";
+
+  var color = d3.scale.category10();
+
+  function ForceGraph(svg, options) {
+    options = options || {};
+
+    this.svg = d3.select(svg);
+    this.radius = options.radius || 5;
+    this.listeners = {};
+    this.nodesByKey = {};
+    this.linksByKey = {};
+
+    this.force = d3.layout.force()
+        .charge(options.charge || -400)
+        .friction(options.friction || 0.9)
+        .theta(options.theta || 0.9)
+        .gravity(options.gravity || 0.5)
+        .linkDistance(options.linkDistance || 10)
+        .linkStrength(options.linkStrength || 0.9);
+
+    this.nodes = this.force.nodes();
+    this.links = this.force.links();
+
+    this.linkLayer = this.svg.append("g");
+    this.nodeLayer = this.svg.append("g");
+    this.link = this.linkLayer.selectAll(".link");
+    this.node = this.nodeLayer.selectAll(".node");
+
+    this.force.on("tick", this.tick.bind(this));
+
+    this.resize();
+    global.addEventListener("resize", this.resize.bind(this));
+  }
+
+  ForceGraph.color = function(type) {
+    return color(type);
+  };
+
+  ForceGraph.prototype.resize = function() {
+    var box = this.svg.node().getBoundingClientRect();
+    this.width = box.width || global.innerWidth;
+    this.height = box.height || global.innerHeight;
+    this.force.size([this.width, this.height]);
+  };
+
+  ForceGraph.prototype.on = function(type, listener) {
+    this.listeners[type] = listener;
+    return this;
+  };
+
+  ForceGraph.prototype.emit = function(type, node) {
+    if (this.listeners[type]) {
+      this.listeners[type](node, d3.event);
+    }
+  };
+
+  // addNode adds data under key, ignoring keys that already exist
+  ForceGraph.prototype.addNode = function(key, data) {
+    if (this.nodesByKey[key]) {
+      return this.nodesByKey[key];
+    }
+
+    var node = {
+      key: key,
+      data: data,
+      x: this.width / 2 + Math.random() - 0.5,
+      y: this.height / 2 + Math.random() - 0.5
+    };
+
+    this.nodes.push(node);
+    this.nodesByKey[key] = node;
+    this.dirty = true;
+
+    return node;
+  };
+
+  // addLink joins two existing nodes, ignoring duplicate or dangling links
+  ForceGraph.prototype.addLink = function(sourceKey, targetKey, data) {
+    var key = sourceKey + "-" + targetKey,
+        source = this.nodesByKey[sourceKey],
+        target = this.nodesByKey[targetKey];
+
+    if (this.linksByKey[key] || !source || !target) {
+      return null;
+    }
+
+    var link = {key: key, source: source, target: target, data: data || {}};
+
+    this.links.push(link);
+    this.linksByKey[key] = link;
+    this.dirty = true;
+
+    return link;
+  };
+
+  ForceGraph.prototype.getNode = function(key) {
+    return this.nodesByKey[key];
+  };
+
+  // updateNode replaces the data of an existing node, adding it if needed
+  ForceGraph.prototype.updateNode = function(key, data) {
+    var node = this.nodesByKey[key];
+    if (!node) {
+      return this.addNode(key, data);
+    }
+
+    node.data = data;
+    this.redraw();
+    this.node.filter(function(d) { return d === node; })
+        .style("fill", color(data.type || 0));
+
+    return node;
+  };
+
+  // filter hides every node the predicate rejects, along with its links
+  ForceGraph.prototype.filter = function(predicate) {
+    this.redraw();
+    this.nodes.forEach(function(n) {
+      n.hidden = !predicate(n.data, n.key);
+    });
+
+    this.node.style("display", function(d) { return d.hidden ? "none" : null; });
+    this.link.style("display", function(d) {
+      return d.source.hidden || d.target.hidden ? "none" : null;
+    });
+  };
+
+  // highlight dims everything except the given node and link keys
+  // Passing null for nodeKeys clears the highlight
+  ForceGraph.prototype.highlight = function(nodeKeys, linkKeys) {
+    function on(keys) {
+      return function(d) { return !!(keys && keys[d.key]); };
+    }
+
+    function dimmed(keys) {
+      return function(d) { return !!nodeKeys && !(keys && keys[d.key]); };
+    }
+
+    this.redraw();
+    this.node
+        .classed("highlighted", on(nodeKeys))
+        .classed("dimmed", dimmed(nodeKeys));
+    this.link
+        .classed("highlighted", on(linkKeys))
+        .classed("dimmed", dimmed(linkKeys));
+  };
+
+  ForceGraph.prototype.start = function() {
+    this.redraw();
+    this.force.start();
+  };
+
+  // redraw joins the nodes and links to their SVG elements, creating those
+  // added since it last ran
+  ForceGraph.prototype.redraw = function() {
+    var self = this;
+
+    if (!this.dirty) {
+      return;
+    }
+    this.dirty = false;
+
+    this.link = this.link.data(this.links, function(d) { return d.key; });
+    this.link.enter().append("line")
+        .attr("class", "link")
+        .style("stroke-width", function(d) { return Math.sqrt(d.data.value || 1); });
+
+    this.node = this.node.data(this.nodes, function(d) { return d.key; });
+    this.node.enter().append("circle")
+        .attr("class", "node")
+        .attr("r", this.radius)
+        .style("fill", function(d) { return color(d.data.type || 0); })
+        .on("mouseover", function(d) { self.emit("mouseover", d); })
+        .on("mouseout", function(d) { self.emit("mouseout", d); })
+        .on("click", function(d) {
+          // Dragging a node shouldn't also select it
+          if (!d3.event.defaultPrevented) {
+            self.emit("click", d);
+          }
+        })
+        .call(this.force.drag);
+  };
+
+  ForceGraph.prototype.tick = function() {
+    this.link
+        .attr("x1", function(d) { return d.source.x; })
+        .attr("y1", function(d) { return d.source.y; })
+        .attr("x2", function(d) { return d.target.x; })
+        .attr("y2", function(d) { return d.target.y; });
+
+    this.node
+        .attr("cx", function(d) { return d.x; })
+        .attr("cy", function(d) { return d.y; });
+  };
+
+  global.ForceGraph = ForceGraph;
+})(window, window.d3);
@@ -0,0 +1,64 @@
+package server_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+
+	. "github.com/kevinoconnor7/digitalocean-crawler/server"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Static", func() {
+	var (
+		ts    *httptest.Server
+		index string
+	)
+
+	BeforeEach(func() {
+		ts = httptest.NewServer(GenerateServer(GenerateManager()))
+
+		resp, err := http.Get(ts.URL + "/")
+		Expect(err).ToNot(HaveOccurred())
+		defer resp.Body.Close()
+
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		Expect(resp.Header.Get("Content-Type")).To(HavePrefix("text/html"))
+
+		body, err := io.ReadAll(resp.Body)
+		Expect(err).ToNot(HaveOccurred())
+		index = string(body)
+	})
+
+	AfterEach(func() {
+		ts.Close()
+	})
+
+	It("Should not reference external assets", func() {
+		Expect(index).ToNot(MatchRegexp(`(src|href)="(https?:)?//`))
+	})
+
+	It("Should serve every referenced asset", func() {
+		refs := regexp.MustCompile(`(?:src|href)="([^"]+)"`).FindAllStringSubmatch(index, -1)
+		Expect(refs).ToNot(BeEmpty())
+
+		for _, ref := range refs {
+			Expect(ref[1]).To(HavePrefix("/static/"))
+
+			resp, err := http.Get(ts.URL + ref[1])
+			Expect(err).ToNot(HaveOccurred())
+			resp.Body.Close()
+			Expect(resp.StatusCode).To(Equal(http.StatusOK), ref[1])
+		}
+	})
+
+	It("Should 404 for missing assets", func() {
+		resp, err := http.Get(ts.URL + "/static/missing.js")
+		Expect(err).ToNot(HaveOccurred())
+		resp.Body.Close()
+		Expect(resp.StatusCode).To(Equal(http.StatusNotFound))
+	})
+})
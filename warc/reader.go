@@ -0,0 +1,255 @@
+package warc
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// MaxBlockSize is the largest record block a Reader accepts, so that a
+// corrupt Content-Length can't claim more memory than that
+const MaxBlockSize = 1 << 30
+
+// Offset locates a record in a WARC file: Member is where the gzip member
+// the record starts in begins, or where the record itself begins in files
+// that aren't gzipped, and Skip how many decompressed bytes of the member
+// come before the record
+type Offset struct {
+	Member int64
+	Skip   int64
+}
+
+// Reader reads records from a WARC file, gzipped or not
+type Reader struct {
+	r *bufio.Reader
+
+	// members is the source of r if the file is gzipped
+	members *members
+
+	// read counts the bytes of r consumed so far
+	read int64
+	last Offset
+}
+
+// GenerateReader is a factory for a Reader over r, which is decompressed
+// if it starts with a gzip header
+func GenerateReader(r io.Reader) (*Reader, error) {
+	buffered := bufio.NewReader(r)
+
+	magic, _ := buffered.Peek(2)
+	if bytes.Equal(magic, []byte{0x1f, 0x8b}) {
+		members, err := generateMembers(buffered)
+		if err != nil {
+			return nil, err
+		}
+
+		return &Reader{r: bufio.NewReader(members), members: members}, nil
+	}
+
+	return &Reader{r: buffered}, nil
+}
+
+// ReadRecordAt reads the record at offset in the WARC file r
+func ReadRecordAt(r io.ReaderAt, offset Offset) (*Record, error) {
+	reader, err := GenerateReader(io.NewSectionReader(r, offset.Member, math.MaxInt64-offset.Member))
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = reader.r.Discard(int(offset.Skip))
+	if err != nil {
+		return nil, fmt.Errorf("No WARC record at %d+%d: %v", offset.Member, offset.Skip, err)
+	}
+
+	return reader.Next()
+}
+
+// Offset returns where the record last returned by Next starts
+func (r *Reader) Offset() Offset {
+	return r.last
+}
+
+// Next returns the next record, or io.EOF once there are none left
+func (r *Reader) Next() (*Record, error) {
+	start := r.read
+	version, err := r.readLine()
+	for err == nil && version == "" {
+		start = r.read
+		version, err = r.readLine()
+	}
+
+	if err == io.EOF && version == "" {
+		return nil, io.EOF
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !strings.HasPrefix(version, "WARC/") {
+		return nil, fmt.Errorf("Expected a WARC record, found %q", version)
+	}
+
+	record := &Record{}
+	length := -1
+
+	for {
+		line, err := r.readLine()
+		if err != nil {
+			return nil, fmt.Errorf("Truncated WARC header: %v", err)
+		}
+
+		if line == "" {
+			break
+		}
+
+		// Continuation lines extend the previous field
+		if (line[0] == ' ' || line[0] == '\t') && len(record.Header) > 0 {
+			record.Header[len(record.Header)-1].Value += " " + strings.TrimSpace(line)
+			continue
+		}
+
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			return nil, fmt.Errorf("Malformed WARC header %q", line)
+		}
+
+		name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+		if strings.EqualFold(name, "Content-Length") {
+			length, err = strconv.Atoi(value)
+			if err != nil || length < 0 {
+				return nil, fmt.Errorf("Invalid Content-Length %q", value)
+			}
+			if length > MaxBlockSize {
+				return nil, fmt.Errorf("WARC block of %d bytes is larger than %d", length, MaxBlockSize)
+			}
+			continue
+		}
+
+		record.Header.Add(name, value)
+	}
+
+	if length < 0 {
+		return nil, fmt.Errorf("WARC record has no Content-Length")
+	}
+
+	// Reading as the block arrives rather than allocating its whole length
+	// up front leaves short files unable to claim more than they hold
+	record.Block, err = io.ReadAll(io.LimitReader(r.r, int64(length)))
+	r.read += int64(len(record.Block))
+	if err == nil && len(record.Block) < length {
+		err = io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Truncated WARC block: %v", err)
+	}
+
+	r.last = Offset{Member: start}
+	if r.members != nil {
+		r.last = r.members.locate(start)
+	}
+
+	return record, nil
+}
+
+func (r *Reader) readLine() (string, error) {
+	line, err := r.r.ReadString('\n')
+	r.read += int64(len(line))
+	return strings.TrimRight(line, "\r\n"), err
+}
+
+// members decompresses every gzip member of a file in turn, noting where
+// each begins so that records can be located by the member they're in
+type members struct {
+	source *countingReader
+	gz     *gzip.Reader
+
+	// starts lists the members that records may yet start in
+	starts []memberStart
+
+	// read counts the decompressed bytes read so far
+	read int64
+}
+
+// memberStart is where a member begins in the file and in its decompressed
+// content
+type memberStart struct {
+	offset       int64
+	decompressed int64
+}
+
+func generateMembers(r *bufio.Reader) (*members, error) {
+	// gzip reads no further than each member when given a ByteReader, so
+	// the bytes counted are exactly those of the members read
+	source := &countingReader{r: r}
+	gz, err := gzip.NewReader(source)
+	if err != nil {
+		return nil, err
+	}
+
+	gz.Multistream(false)
+
+	return &members{source: source, gz: gz, starts: []memberStart{{}}}, nil
+}
+
+func (m *members) Read(p []byte) (int, error) {
+	for {
+		n, err := m.gz.Read(p)
+		m.read += int64(n)
+		if err == io.EOF && n > 0 {
+			// The next member is started on the next call
+			return n, nil
+		}
+		if err != io.EOF {
+			return n, err
+		}
+
+		offset := m.source.n
+		err = m.gz.Reset(m.source)
+		if err != nil {
+			return 0, err
+		}
+
+		m.gz.Multistream(false)
+		m.starts = append(m.starts, memberStart{offset: offset, decompressed: m.read})
+	}
+}
+
+// locate returns the offset of a record starting decompressed bytes in,
+// forgetting the members before its own as no later record starts in them
+func (m *members) locate(decompressed int64) Offset {
+	i := len(m.starts) - 1
+	for i > 0 && m.starts[i].decompressed > decompressed {
+		i--
+	}
+
+	m.starts = m.starts[i:]
+	start := m.starts[0]
+
+	return Offset{Member: start.offset, Skip: decompressed - start.decompressed}
+}
+
+// countingReader counts the bytes read through it
+type countingReader struct {
+	r *bufio.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+func (c *countingReader) ReadByte() (byte, error) {
+	b, err := c.r.ReadByte()
+	if err == nil {
+		c.n++
+	}
+	return b, err
+}
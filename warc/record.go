@@ -0,0 +1,158 @@
+package warc
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base32"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Version is the WARC format version written by Writer
+const Version = "WARC/1.1"
+
+// Record types used by the crawler
+const (
+	WARCINFO = "warcinfo"
+	REQUEST  = "request"
+	RESPONSE = "response"
+)
+
+// TruncatedLength is the WARC-Truncated reason for bodies cut off at a
+// maximum size
+const TruncatedLength = "length"
+
+// Field is a single named WARC header field
+type Field struct {
+	Name  string
+	Value string
+}
+
+// Header is the ordered list of fields at the start of a record
+type Header []Field
+
+// Get returns the value of the first field named name, ignoring case
+func (h Header) Get(name string) string {
+	for _, field := range h {
+		if strings.EqualFold(field.Name, name) {
+			return field.Value
+		}
+	}
+
+	return ""
+}
+
+// Add appends a field to the header
+func (h *Header) Add(name string, value string) {
+	*h = append(*h, Field{Name: name, Value: value})
+}
+
+// Record is a single WARC record
+type Record struct {
+	Header Header
+	Block  []byte
+}
+
+// Type returns the WARC-Type of the record
+func (r *Record) Type() string {
+	return r.Header.Get("WARC-Type")
+}
+
+// ID returns the WARC-Record-ID of the record
+func (r *Record) ID() string {
+	return r.Header.Get("WARC-Record-ID")
+}
+
+// TargetURI returns the URL the record was captured from
+func (r *Record) TargetURI() string {
+	return r.Header.Get("WARC-Target-URI")
+}
+
+// Truncated returns why the record's content was cut short, if it was
+func (r *Record) Truncated() string {
+	return r.Header.Get("WARC-Truncated")
+}
+
+// Response parses the HTTP response held by a response record
+func (r *Record) Response() (*http.Response, error) {
+	if r.Type() != RESPONSE {
+		return nil, fmt.Errorf("Record %s is a %s, not a response", r.ID(), r.Type())
+	}
+
+	return http.ReadResponse(bufio.NewReader(bytes.NewReader(r.Block)), nil)
+}
+
+// NewRecordID generates a unique WARC-Record-ID
+func NewRecordID() string {
+	var id [16]byte
+	rand.Read(id[:])
+
+	// Version 4, variant 1 UUID
+	id[6] = id[6]&0x0f | 0x40
+	id[8] = id[8]&0x3f | 0x80
+
+	return fmt.Sprintf("<urn:uuid:%x-%x-%x-%x-%x>", id[0:4], id[4:6], id[6:8], id[8:10], id[10:])
+}
+
+// Digest returns the labelled SHA-1 digest WARC uses for blocks and payloads
+func Digest(data []byte) string {
+	sum := sha1.Sum(data)
+	return "sha1:" + base32.StdEncoding.EncodeToString(sum[:])
+}
+
+// ResponseBlock serializes an HTTP response as a WARC response block
+// body is the response body already read from resp
+func ResponseBlock(resp *http.Response, body []byte) []byte {
+	var buf bytes.Buffer
+
+	proto := resp.Proto
+	if proto == "" {
+		proto = "HTTP/1.1"
+	}
+
+	status := resp.Status
+	if status == "" {
+		status = fmt.Sprintf("%d %s", resp.StatusCode, http.StatusText(resp.StatusCode))
+	}
+
+	fmt.Fprintf(&buf, "%s %s\r\n", proto, status)
+	resp.Header.Write(&buf)
+	buf.WriteString("\r\n")
+	buf.Write(body)
+
+	return buf.Bytes()
+}
+
+// Redacted replaces the values of credential headers in request blocks
+const Redacted = "REDACTED"
+
+// credentialHeaders are the request headers that are never archived as sent
+var credentialHeaders = []string{"Authorization", "Cookie", "Proxy-Authorization"}
+
+// RequestBlock serializes an HTTP request as a WARC request block
+// Credentials in the request's headers are redacted
+func RequestBlock(req *http.Request) []byte {
+	var buf bytes.Buffer
+
+	proto := req.Proto
+	if proto == "" {
+		proto = "HTTP/1.1"
+	}
+
+	fmt.Fprintf(&buf, "%s %s %s\r\n", req.Method, req.URL.RequestURI(), proto)
+	fmt.Fprintf(&buf, "Host: %s\r\n", req.URL.Host)
+
+	header := req.Header.Clone()
+	for _, name := range credentialHeaders {
+		if header.Get(name) != "" {
+			header.Set(name, Redacted)
+		}
+	}
+	header.Write(&buf)
+	buf.WriteString("\r\n")
+
+	return buf.Bytes()
+}
@@ -0,0 +1,13 @@
+package warc_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestWarc(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Warc Suite")
+}
@@ -0,0 +1,270 @@
+package warc_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	. "github.com/kevinoconnor7/digitalocean-crawler/warc"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func exchange(url string) (*http.Request, *http.Response) {
+	req, _ := http.NewRequest("GET", url, nil)
+	req.Header.Set("User-Agent", "test")
+
+	resp := &http.Response{
+		Proto:      "HTTP/1.1",
+		Status:     "200 OK",
+		StatusCode: 200,
+		Header:     http.Header{"Content-Type": {"text/html"}},
+	}
+
+	return req, resp
+}
+
+func records(path string) []*Record {
+	file, err := os.Open(path)
+	Expect(err).ToNot(HaveOccurred())
+	defer file.Close()
+
+	reader, err := GenerateReader(file)
+	Expect(err).ToNot(HaveOccurred())
+
+	var all []*Record
+	for {
+		record, err := reader.Next()
+		if err == io.EOF {
+			return all
+		}
+		Expect(err).ToNot(HaveOccurred())
+		all = append(all, record)
+	}
+}
+
+var _ = Describe("Warc", func() {
+	var dir string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = os.MkdirTemp("", "warc")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	It("Should archive an exchange as request and response records", func() {
+		w := GenerateWriter(dir, "test", 0)
+		req, resp := exchange("http://example.com/page?q=1")
+
+		id, err := w.WriteExchange(req, resp, []byte("<p>hello</p>"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(w.Close()).To(Succeed())
+
+		Expect(w.Files()).To(HaveLen(1))
+		Expect(w.Files()[0]).To(HaveSuffix("test-00000.warc.gz"))
+
+		all := records(w.Files()[0])
+		Expect(all).To(HaveLen(3))
+		Expect(all[0].Type()).To(Equal(WARCINFO))
+
+		response := all[1]
+		Expect(response.Type()).To(Equal(RESPONSE))
+		Expect(response.ID()).To(Equal(id))
+		Expect(response.TargetURI()).To(Equal("http://example.com/page?q=1"))
+		Expect(response.Header.Get("WARC-Warcinfo-ID")).To(Equal(all[0].ID()))
+		Expect(response.Header.Get("WARC-Block-Digest")).To(Equal(Digest(response.Block)))
+		Expect(response.Header.Get("WARC-Payload-Digest")).To(Equal(Digest([]byte("<p>hello</p>"))))
+
+		request := all[2]
+		Expect(request.Type()).To(Equal(REQUEST))
+		Expect(request.Header.Get("WARC-Concurrent-To")).To(Equal(id))
+		Expect(string(request.Block)).To(HavePrefix("GET /page?q=1 HTTP/1.1\r\nHost: example.com\r\n"))
+		Expect(string(request.Block)).To(ContainSubstring("User-Agent: test"))
+	})
+
+	It("Should parse archived responses", func() {
+		w := GenerateWriter(dir, "test", 0)
+		req, resp := exchange("http://example.com/")
+
+		_, err := w.WriteExchange(req, resp, []byte("<p>hello</p>"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(w.Close()).To(Succeed())
+
+		parsed, err := records(w.Files()[0])[1].Response()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(parsed.StatusCode).To(Equal(200))
+		Expect(parsed.Header.Get("Content-Type")).To(Equal("text/html"))
+
+		body, _ := io.ReadAll(parsed.Body)
+		Expect(string(body)).To(Equal("<p>hello</p>"))
+	})
+
+	It("Should redact credentials from requests", func() {
+		w := GenerateWriter(dir, "test", 0)
+		req, resp := exchange("http://example.com/")
+		req.SetBasicAuth("user", "secret")
+		req.Header.Set("Cookie", "session=secret")
+		req.Header.Set("Proxy-Authorization", "Basic secret")
+
+		_, err := w.WriteExchange(req, resp, []byte("<p>hello</p>"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(w.Close()).To(Succeed())
+
+		request := records(w.Files()[0])[2]
+		block := string(request.Block)
+		Expect(block).To(ContainSubstring("Authorization: " + Redacted + "\r\n"))
+		Expect(block).To(ContainSubstring("Cookie: " + Redacted + "\r\n"))
+		Expect(block).To(ContainSubstring("Proxy-Authorization: " + Redacted + "\r\n"))
+		Expect(block).ToNot(ContainSubstring("secret"))
+		Expect(block).ToNot(ContainSubstring("dXNlcjpzZWNyZXQ="))
+		Expect(request.Header.Get("WARC-Block-Digest")).To(Equal(Digest(request.Block)))
+
+		Expect(req.Header.Get("Cookie")).To(Equal("session=secret"))
+	})
+
+	It("Should mark truncated responses", func() {
+		w := GenerateWriter(dir, "test", 0)
+		req, resp := exchange("http://example.com/")
+
+		_, err := w.WriteTruncatedExchange(req, resp, []byte("<p>hel"), TruncatedLength)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(w.Close()).To(Succeed())
+
+		all := records(w.Files()[0])
+		Expect(all[1].Truncated()).To(Equal(TruncatedLength))
+		Expect(all[2].Truncated()).To(BeEmpty())
+	})
+
+	It("Should refuse to parse other records as responses", func() {
+		w := GenerateWriter(dir, "test", 0)
+		req, resp := exchange("http://example.com/")
+
+		_, err := w.WriteExchange(req, resp, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(w.Close()).To(Succeed())
+
+		_, err = records(w.Files()[0])[2].Response()
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("Should rotate files by size, keeping exchanges together", func() {
+		w := GenerateWriter(dir, "test", 1)
+
+		for _, path := range []string{"/a", "/b", "/c"} {
+			req, resp := exchange("http://example.com" + path)
+			_, err := w.WriteExchange(req, resp, []byte(path))
+			Expect(err).ToNot(HaveOccurred())
+		}
+		Expect(w.Close()).To(Succeed())
+
+		Expect(w.Files()).To(HaveLen(3))
+		for _, file := range w.Files() {
+			all := records(file)
+			Expect(all).To(HaveLen(3))
+			Expect(all[0].Type()).To(Equal(WARCINFO))
+		}
+	})
+
+	It("Should read uncompressed archives", func() {
+		var buf bytes.Buffer
+		record := &Record{Block: []byte("hello")}
+		record.Header.Add("WARC-Type", "resource")
+		record.Header.Add("WARC-Record-ID", NewRecordID())
+
+		Expect(WriteRecord(&buf, record)).To(Succeed())
+		Expect(WriteRecord(&buf, record)).To(Succeed())
+
+		reader, err := GenerateReader(&buf)
+		Expect(err).ToNot(HaveOccurred())
+
+		for i := 0; i < 2; i++ {
+			read, err := reader.Next()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(read.Type()).To(Equal("resource"))
+			Expect(string(read.Block)).To(Equal("hello"))
+		}
+
+		_, err = reader.Next()
+		Expect(err).To(Equal(io.EOF))
+	})
+
+	It("Should read records at their offsets", func() {
+		w := GenerateWriter(dir, "test", 0)
+		for _, path := range []string{"/a", "/b"} {
+			req, resp := exchange("http://example.com" + path)
+			_, err := w.WriteExchange(req, resp, []byte(path))
+			Expect(err).ToNot(HaveOccurred())
+		}
+		Expect(w.Close()).To(Succeed())
+
+		var buf bytes.Buffer
+		record := &Record{Block: []byte("hello")}
+		record.Header.Add("WARC-Type", "resource")
+		Expect(WriteRecord(&buf, record)).To(Succeed())
+		record.Block = []byte("world")
+		Expect(WriteRecord(&buf, record)).To(Succeed())
+
+		var compressed bytes.Buffer
+		gz := gzip.NewWriter(&compressed)
+		gz.Write(buf.Bytes())
+		gz.Close()
+
+		file, err := os.ReadFile(w.Files()[0])
+		Expect(err).ToNot(HaveOccurred())
+
+		for _, archive := range [][]byte{file, buf.Bytes(), compressed.Bytes()} {
+			reader, err := GenerateReader(bytes.NewReader(archive))
+			Expect(err).ToNot(HaveOccurred())
+
+			for {
+				read, err := reader.Next()
+				if err == io.EOF {
+					break
+				}
+				Expect(err).ToNot(HaveOccurred())
+
+				again, err := ReadRecordAt(bytes.NewReader(archive), reader.Offset())
+				Expect(err).ToNot(HaveOccurred())
+				Expect(again).To(Equal(read))
+			}
+		}
+	})
+
+	It("Should error on records longer than their file", func() {
+		reader, err := GenerateReader(strings.NewReader("WARC/1.1\r\nWARC-Type: resource\r\nContent-Length: 1000000000\r\n\r\nhello"))
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = reader.Next()
+		Expect(err).To(MatchError(ContainSubstring("Truncated WARC block")))
+	})
+
+	It("Should refuse blocks larger than the maximum", func() {
+		reader, err := GenerateReader(strings.NewReader(fmt.Sprintf("WARC/1.1\r\nContent-Length: %d\r\n\r\n", MaxBlockSize+1)))
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = reader.Next()
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("Should error on records without a length", func() {
+		reader, err := GenerateReader(strings.NewReader("WARC/1.1\r\nWARC-Type: resource\r\n\r\n"))
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = reader.Next()
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("Should generate unique record IDs", func() {
+		Expect(NewRecordID()).To(MatchRegexp(`^<urn:uuid:[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}>$`))
+		Expect(NewRecordID()).ToNot(Equal(NewRecordID()))
+	})
+})
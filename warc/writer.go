@@ -0,0 +1,226 @@
+package warc
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// DefaultMaxSize is the size archives are rotated at by default
+const DefaultMaxSize = 1 << 30
+
+// Writer archives records into gzipped WARC files in a directory, starting
+// a new file whenever the current one reaches the maximum size
+// Every record is compressed as its own gzip member
+type Writer struct {
+	mu         sync.Mutex
+	dir        string
+	prefix     string
+	maxSize    int64
+	file       *os.File
+	size       int64
+	serial     int
+	warcinfoID string
+	files      []string
+}
+
+// GenerateWriter is a factory for a Writer creating files named
+// prefix-00000.warc.gz and so on in dir
+// No file is created until the first record is written
+func GenerateWriter(dir string, prefix string, maxSize int64) *Writer {
+	if maxSize <= 0 {
+		maxSize = DefaultMaxSize
+	}
+
+	return &Writer{
+		dir:     dir,
+		prefix:  prefix,
+		maxSize: maxSize,
+	}
+}
+
+// Files lists every archive written so far, in order
+func (w *Writer) Files() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return append([]string{}, w.files...)
+}
+
+// WriteExchange archives a request and the response to it as a pair of
+// records, returning the ID of the response record
+// body is the response body already read from resp
+func (w *Writer) WriteExchange(req *http.Request, resp *http.Response, body []byte) (string, error) {
+	return w.WriteTruncatedExchange(req, resp, body, "")
+}
+
+// WriteTruncatedExchange is WriteExchange for bodies that may have been cut
+// short, truncated being the WARC-Truncated reason if so
+func (w *Writer) WriteTruncatedExchange(req *http.Request, resp *http.Response, body []byte, truncated string) (string, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	// Rotate before the pair so that both records end up in the same file
+	err := w.prepare()
+	if err != nil {
+		return "", err
+	}
+
+	date := time.Now().UTC().Format(time.RFC3339)
+	target := req.URL.String()
+
+	response := &Record{Block: ResponseBlock(resp, body)}
+	responseID := NewRecordID()
+	response.Header.Add("WARC-Type", RESPONSE)
+	response.Header.Add("WARC-Record-ID", responseID)
+	response.Header.Add("WARC-Date", date)
+	response.Header.Add("WARC-Target-URI", target)
+	response.Header.Add("WARC-Warcinfo-ID", w.warcinfoID)
+	response.Header.Add("WARC-Block-Digest", Digest(response.Block))
+	response.Header.Add("WARC-Payload-Digest", Digest(body))
+	if truncated != "" {
+		response.Header.Add("WARC-Truncated", truncated)
+	}
+	response.Header.Add("Content-Type", "application/http;msgtype=response")
+
+	request := &Record{Block: RequestBlock(req)}
+	request.Header.Add("WARC-Type", REQUEST)
+	request.Header.Add("WARC-Record-ID", NewRecordID())
+	request.Header.Add("WARC-Date", date)
+	request.Header.Add("WARC-Target-URI", target)
+	request.Header.Add("WARC-Warcinfo-ID", w.warcinfoID)
+	request.Header.Add("WARC-Concurrent-To", responseID)
+	request.Header.Add("WARC-Block-Digest", Digest(request.Block))
+	request.Header.Add("Content-Type", "application/http;msgtype=request")
+
+	err = w.write(response)
+	if err != nil {
+		return "", err
+	}
+
+	err = w.write(request)
+	if err != nil {
+		return "", err
+	}
+
+	return responseID, nil
+}
+
+// Close finishes the current archive
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.closeFile()
+}
+
+// prepare makes sure there is a file with room for more records, starting
+// each new file with a warcinfo record
+func (w *Writer) prepare() error {
+	if w.file != nil && w.size < w.maxSize {
+		return nil
+	}
+
+	err := w.closeFile()
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(w.dir, 0755)
+	if err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%s-%05d.warc.gz", w.prefix, w.serial)
+	file, err := os.Create(filepath.Join(w.dir, name))
+	if err != nil {
+		return fmt.Errorf("Unable to create archive: %v", err)
+	}
+
+	w.file = file
+	w.size = 0
+	w.serial++
+	w.files = append(w.files, file.Name())
+
+	info := &Record{Block: []byte("software: digitalocean-crawler\r\nformat: WARC File Format 1.1\r\n")}
+	w.warcinfoID = NewRecordID()
+	info.Header.Add("WARC-Type", WARCINFO)
+	info.Header.Add("WARC-Record-ID", w.warcinfoID)
+	info.Header.Add("WARC-Date", time.Now().UTC().Format(time.RFC3339))
+	info.Header.Add("WARC-Filename", name)
+	info.Header.Add("Content-Type", "application/warc-fields")
+
+	return w.write(info)
+}
+
+func (w *Writer) closeFile() error {
+	if w.file == nil {
+		return nil
+	}
+
+	err := w.file.Close()
+	w.file = nil
+
+	return err
+}
+
+// write appends the record to the current file as its own gzip member
+func (w *Writer) write(r *Record) error {
+	counter := &countingWriter{w: w.file}
+	compressed := gzip.NewWriter(counter)
+
+	err := WriteRecord(compressed, r)
+	if err != nil {
+		return err
+	}
+
+	err = compressed.Close()
+	w.size += counter.n
+
+	return err
+}
+
+// WriteRecord writes an uncompressed record, adding its Content-Length
+func WriteRecord(out io.Writer, r *Record) error {
+	_, err := io.WriteString(out, Version+"\r\n")
+	if err != nil {
+		return err
+	}
+
+	for _, field := range r.Header {
+		_, err = fmt.Fprintf(out, "%s: %s\r\n", field.Name, field.Value)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = io.WriteString(out, "Content-Length: "+strconv.Itoa(len(r.Block))+"\r\n\r\n")
+	if err != nil {
+		return err
+	}
+
+	_, err = out.Write(r.Block)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(out, "\r\n\r\n")
+	return err
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/kevinoconnor7/digitalocean-crawler/report"
+)
+
+// weight lists the heaviest pages and the resources shared by most pages of a
+// saved crawl
+func weight(args []string) error {
+	flags := flag.NewFlagSet("weight", flag.ExitOnError)
+	asJSON := flags.Bool("json", false, "Print the report as JSON")
+	top := flags.Int("top", report.DefaultLargest, "Number of pages and resources to list")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: weight [flags] crawl.json")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return fmt.Errorf("Expected a crawl file")
+	}
+
+	if *top < 0 {
+		return fmt.Errorf("top can't be negative")
+	}
+
+	s, err := loadSnapshot(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	w := report.GeneratePageWeights(s, *top)
+
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(w)
+	}
+
+	return w.WriteText(os.Stdout)
+}